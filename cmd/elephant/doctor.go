@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/abenz1267/elephant/v2/internal/comm"
+	"github.com/abenz1267/elephant/v2/internal/comm/client"
+	"github.com/abenz1267/elephant/v2/internal/providers"
+	"github.com/abenz1267/elephant/v2/pkg/common"
+)
+
+func handleDoctor() error {
+	logger := slog.New(slog.DiscardHandler)
+	slog.SetDefault(logger)
+
+	common.EnableConfigCheck()
+	common.LoadGlobalConfig()
+	common.InitRunPrefix()
+
+	providers.Load(false)
+
+	fmt.Println("Socket")
+
+	if info, err := client.ServerInfo(); err != nil {
+		fmt.Printf("  %s: not reachable (%s)\n", comm.Socket, err)
+	} else {
+		fmt.Printf("  %s: reachable, elephant %s, protocol %d\n", comm.Socket, info.Version, info.Protocol)
+	}
+
+	cfg := common.GetElephantConfig()
+
+	fmt.Println()
+	fmt.Println("Environment")
+
+	switch {
+	case cfg.LaunchPrefix != "":
+		fmt.Printf("  launch prefix: %s (launch_prefix)\n", cfg.LaunchPrefix)
+	case common.LaunchPrefix() != "":
+		fmt.Printf("  launch prefix: %s (detected)\n", common.LaunchPrefix())
+	default:
+		fmt.Println("  launch prefix: none")
+	}
+
+	terminal := common.GetTerminal()
+
+	switch {
+	case cfg.TerminalCmd != "":
+		fmt.Printf("  terminal: %s (terminal_cmd)\n", cfg.TerminalCmd)
+	case terminal != "":
+		fmt.Printf("  terminal: %s (detected)\n", terminal)
+	default:
+		fmt.Println("  terminal: none found, set terminal_cmd")
+	}
+
+	fmt.Println()
+	fmt.Println("Providers")
+
+	names := slices.Collect(maps.Keys(providers.Providers))
+	names = append(names, slices.Collect(maps.Keys(providers.Disabled))...)
+	slices.Sort(names)
+
+	for _, v := range slices.Compact(names) {
+		if _, ok := providers.Providers[v]; ok {
+			fmt.Printf("  ok  %s\n", v)
+		} else {
+			fmt.Printf("  off %s: %s\n", v, providers.Disabled[v])
+		}
+
+		for _, r := range providers.Requirements[v] {
+			status := r.Find()
+
+			if status == "" {
+				status = "missing"
+			}
+
+			if r.Optional {
+				status += ", optional"
+			}
+
+			fmt.Printf("        %s (%s): %s\n", r.Name, strings.Join(r.Executables, " or "), status)
+		}
+	}
+
+	return nil
+}
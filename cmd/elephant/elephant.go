@@ -10,15 +10,19 @@ import (
 	"os/exec"
 	"os/signal"
 	"path/filepath"
+	"strings"
 	"syscall"
 	"time"
 
+	_ "github.com/abenz1267/elephant/v2/internal/builtin"
 	"github.com/abenz1267/elephant/v2/internal/comm"
 	"github.com/abenz1267/elephant/v2/internal/comm/client"
+	"github.com/abenz1267/elephant/v2/internal/comm/handlers"
 	"github.com/abenz1267/elephant/v2/internal/install"
 	"github.com/abenz1267/elephant/v2/internal/providers"
 	"github.com/abenz1267/elephant/v2/internal/util"
 	"github.com/abenz1267/elephant/v2/pkg/common"
+	"github.com/abenz1267/elephant/v2/pkg/common/history"
 	"github.com/adrg/xdg"
 	"github.com/urfave/cli/v3"
 )
@@ -27,6 +31,9 @@ import (
 var version string
 
 func main() {
+	handlers.Version = strings.TrimSpace(version)
+	client.Version = handlers.Version
+
 	cmd := &cli.Command{
 		Name:                   "Elephant",
 		Usage:                  "Data provider and executor",
@@ -113,6 +120,20 @@ WantedBy=graphical-session.target
 					return nil
 				},
 			},
+			{
+				Name:  "doctor",
+				Usage: "shows which providers are available, their requirements and the detected environment",
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					return handleDoctor()
+				},
+			},
+			{
+				Name:  "plugins",
+				Usage: "lists all provider plugins with their ABI version and why they weren't loaded",
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					return handlePlugins()
+				},
+			},
 			{
 				Name:    "listproviders",
 				Aliases: []string{"l"},
@@ -189,6 +210,26 @@ WantedBy=graphical-session.target
 							return nil
 						},
 					},
+					{
+						Name:    "schema",
+						Aliases: []string{"s"},
+						Usage:   "generates JSON schemas for the config of the given provider or all providers, if none is specified",
+						Arguments: []cli.Argument{
+							&cli.StringArg{
+								Name: "provider",
+							},
+						},
+						Flags: []cli.Flag{
+							&cli.StringFlag{
+								Name:    "dir",
+								Aliases: []string{"o"},
+								Usage:   "directory to write the schemas to, defaults to <configdir>/schemas",
+							},
+						},
+						Action: func(ctx context.Context, cmd *cli.Command) error {
+							return handleGenerateSchema(cmd.StringArg("provider"), cmd.String("dir"))
+						},
+					},
 				},
 			},
 			{
@@ -206,6 +247,18 @@ WantedBy=graphical-session.target
 						DefaultText: "output as json",
 						Usage:       "if you want json. use this.",
 					},
+					&cli.BoolFlag{
+						Name:        "stream",
+						Category:    "",
+						DefaultText: "stream results per provider",
+						Usage:       "receive results of every provider as soon as it is done.",
+					},
+					&cli.IntFlag{
+						Name:        "offset",
+						Category:    "",
+						DefaultText: "0",
+						Usage:       "skip the first n results. ignored when streaming.",
+					},
 				},
 				Arguments: []cli.Argument{
 					&cli.StringArg{
@@ -213,7 +266,7 @@ WantedBy=graphical-session.target
 					},
 				},
 				Action: func(ctx context.Context, cmd *cli.Command) error {
-					client.Query(cmd.StringArg("content"), cmd.Bool("async"), cmd.Bool("json"))
+					client.Query(cmd.StringArg("content"), cmd.Bool("async"), cmd.Bool("json"), cmd.Bool("stream"), int(cmd.Int("offset")))
 
 					return nil
 				},
@@ -239,6 +292,56 @@ WantedBy=graphical-session.target
 					return nil
 				},
 			},
+			{
+				Name:  "config",
+				Usage: "work with the configuration",
+				Commands: []*cli.Command{
+					{
+						Name:  "check",
+						Usage: "checks the config of the given provider or of all providers, if none is specified. Exits non-zero on issues.",
+						Arguments: []cli.Argument{
+							&cli.StringArg{
+								Name: "provider",
+							},
+						},
+						Action: func(ctx context.Context, cmd *cli.Command) error {
+							return handleCheckConfig(cmd.StringArg("provider"))
+						},
+					},
+				},
+			},
+			{
+				Name:  "reload",
+				Usage: "reloads the configuration of the running elephant",
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					return client.Reload()
+				},
+			},
+			{
+				Name:  "profile",
+				Usage: "switches the running elephant to the given profile, or back to the base configuration if none is specified",
+				Arguments: []cli.Argument{
+					&cli.StringArg{
+						Name: "profile",
+					},
+				},
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					return client.SwitchProfile(cmd.StringArg("profile"))
+				},
+			},
+			{
+				Name:  "stats",
+				Usage: "shows query, activation and error counts per provider, latency, connections and memory use of the running elephant",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "json",
+						Usage: "output as json",
+					},
+				},
+				Action: func(ctx context.Context, cmd *cli.Command) error {
+					return handleStats(cmd.Bool("json"))
+				},
+			},
 			{
 				Name:  "community",
 				Usage: "elephant-community based actions",
@@ -306,6 +409,16 @@ WantedBy=graphical-session.target
 					return nil
 				},
 			},
+			&cli.StringFlag{
+				Name:    "profile",
+				Value:   "",
+				Usage:   "config profile to use",
+				Sources: cli.EnvVars("ELEPHANT_PROFILE"),
+				Action: func(ctx context.Context, cmd *cli.Command, val string) error {
+					common.SetProfile(val)
+					return nil
+				},
+			},
 			&cli.BoolFlag{
 				Name:    "debug",
 				Aliases: []string{"d"},
@@ -322,11 +435,31 @@ WantedBy=graphical-session.target
 
 			start := time.Now()
 
+			if err := common.CheckProfile(common.Profile()); err != nil {
+				return err
+			}
+
+			if cmd.Bool("debug") {
+				common.EnableDebugLogging()
+			}
+
 			common.LoadGlobalConfig()
 
+			if err := common.SetupLogging(); err != nil {
+				slog.Error("elephant", "logging", err)
+			}
+
+			reloadChan := make(chan os.Signal, 1)
+			signal.Notify(reloadChan, syscall.SIGHUP)
+
+			go func() {
+				for range reloadChan {
+					handlers.Reload()
+				}
+			}()
+
 			signalChan := make(chan os.Signal, 1)
 			signal.Notify(signalChan,
-				syscall.SIGHUP,
 				syscall.SIGINT,
 				syscall.SIGTERM,
 				syscall.SIGKILL,
@@ -334,23 +467,24 @@ WantedBy=graphical-session.target
 
 			go func() {
 				<-signalChan
-				os.Remove(comm.Socket)
+				shutdown()
 				os.Exit(0)
 			}()
 
-			if cmd.Bool("debug") {
-				logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
-					Level: slog.LevelDebug,
-				}))
-				slog.SetDefault(logger)
-			}
-
 			common.InitRunPrefix()
 
 			runBeforeCommands()
 
 			providers.Load(true)
 
+			go providers.SetupIdle()
+
+			if common.GetElephantConfig().WatchConfigs {
+				go common.WatchConfigs(handlers.ReloadProvider)
+			}
+
+			go handlers.ExportMetrics()
+
 			slog.Info("elephant", "startup", time.Since(start))
 
 			comm.StartListen()
@@ -364,6 +498,31 @@ WantedBy=graphical-session.target
 	}
 }
 
+// shutdown notifies clients and gives providers the chance to persist
+// pending writes, bounded by shutdown_timeout.
+func shutdown() {
+	start := time.Now()
+
+	ctx, cancel := context.WithTimeout(context.Background(), common.GetElephantConfig().ShutdownTimeoutDuration())
+	defer cancel()
+
+	if err := comm.Shutdown(ctx); err != nil {
+		slog.Error("elephant", "shutdown", err)
+	}
+
+	if err := providers.Shutdown(ctx); err != nil {
+		slog.Error("elephant", "shutdown", err)
+	}
+
+	history.Flush()
+
+	if err := common.FlushGit(ctx); err != nil {
+		slog.Error("elephant", "shutdown", err)
+	}
+
+	slog.Info("elephant", "shutdown", time.Since(start))
+}
+
 func runBeforeCommands() {
 	cfg := common.GetElephantConfig()
 
@@ -395,3 +554,56 @@ func handleGenerateConfig(provider string, write bool) {
 
 	util.GenerateDoc(provider, write)
 }
+
+func handleGenerateSchema(provider, dir string) error {
+	logger := slog.New(slog.DiscardHandler)
+	slog.SetDefault(logger)
+
+	common.EnableConfigCheck()
+	common.LoadGlobalConfig()
+
+	providers.Load(false)
+
+	if dir == "" {
+		cfg, err := common.ConfigDir()
+		if err != nil {
+			return cli.Exit(err.Error(), 1)
+		}
+
+		dir = filepath.Join(cfg, "schemas")
+	}
+
+	if err := util.GenerateSchema(provider, dir); err != nil {
+		return cli.Exit(err.Error(), 1)
+	}
+
+	return nil
+}
+
+func handleCheckConfig(provider string) error {
+	logger := slog.New(slog.DiscardHandler)
+	slog.SetDefault(logger)
+
+	common.EnableConfigCheck()
+	common.LoadGlobalConfig()
+
+	providers.Load(false)
+
+	if _, ok := providers.Providers[provider]; provider != "" && provider != "elephant" && !ok {
+		return cli.Exit(fmt.Sprintf("unknown provider: %s", provider), 1)
+	}
+
+	issues := providers.CheckConfigs(provider)
+
+	for _, v := range issues {
+		fmt.Println(v)
+	}
+
+	if len(issues) > 0 {
+		return cli.Exit(fmt.Sprintf("found %d config issue(s)", len(issues)), 1)
+	}
+
+	fmt.Println("config ok")
+
+	return nil
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/abenz1267/elephant/v2/internal/providers"
+	"github.com/abenz1267/elephant/v2/pkg/common"
+)
+
+func handlePlugins() error {
+	slog.SetDefault(slog.New(slog.DiscardHandler))
+
+	common.EnableConfigCheck()
+	common.LoadGlobalConfig()
+
+	providers.Load(false)
+
+	plugins := slices.Clone(providers.Plugins)
+	slices.SortFunc(plugins, func(a, b providers.Plugin) int {
+		return cmp.Compare(a.Path, b.Path)
+	})
+
+	fmt.Printf("plugin ABI: %d\n\n", common.ABIVersion)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "plugin\tprovider\tabi\toptional\tstatus")
+
+	for _, v := range plugins {
+		abi := strconv.Itoa(v.ABI)
+
+		switch {
+		case v.External:
+			abi = "external"
+		case v.ABI == -1:
+			abi = "-"
+		case v.ABI == 0:
+			abi = "legacy"
+		}
+
+		optional := strings.Join(v.Optional, ", ")
+
+		if optional == "" {
+			optional = "-"
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", v.Path, v.Provider, abi, optional, v.Status)
+	}
+
+	return w.Flush()
+}
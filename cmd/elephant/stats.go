@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/abenz1267/elephant/v2/internal/comm/client"
+	"github.com/abenz1267/elephant/v2/pkg/pb/pb"
+)
+
+func handleStats(j bool) error {
+	res, err := client.Stats()
+	if err != nil {
+		return err
+	}
+
+	if j {
+		b, err := json.Marshal(res)
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(string(b))
+
+		return nil
+	}
+
+	fmt.Printf("uptime: %s, connections: %d, subscriptions: %d\n", time.Duration(res.Uptime)*time.Second, res.Connections, res.Subscriptions)
+	fmt.Printf("memory: heap %s of %s, total %s, goroutines: %d\n", mib(res.HeapAlloc), mib(res.HeapSys), mib(res.Sys), res.Goroutines)
+	fmt.Println()
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "provider\tqueries\tresults\tavg\tp50\tp95\ttimeouts\tactivations\terrors\tcrashes")
+
+	for _, v := range res.Providers {
+		avg := "-"
+
+		if v.Queries > 0 {
+			avg = time.Duration(v.LatencySum / float64(v.Queries) * float64(time.Millisecond)).Round(time.Microsecond).String()
+		}
+
+		crashes := strconv.FormatUint(v.Crashes, 10)
+
+		if v.DisabledUntil != 0 {
+			crashes = fmt.Sprintf("%s (disabled until %s)", crashes, time.Unix(v.DisabledUntil, 0).Format(time.TimeOnly))
+		}
+
+		fmt.Fprintf(w, "%s\t%d\t%d\t%s\t%s\t%s\t%d\t%d\t%d\t%s\n", v.Name, v.Queries, v.Results, avg, quantile(v, 0.5), quantile(v, 0.95), v.Timeouts, v.Activations, v.Errors, crashes)
+	}
+
+	return w.Flush()
+}
+
+// quantile estimates the latency quantile q as the upper bound of the bucket
+// it falls in.
+func quantile(p *pb.StatsResponse_Provider, q float64) string {
+	if p.Queries == 0 {
+		return "-"
+	}
+
+	rank := q * float64(p.Queries)
+
+	for _, v := range p.Latency {
+		if float64(v.Count) >= rank {
+			return fmt.Sprintf("≤%gms", v.Le)
+		}
+	}
+
+	return fmt.Sprintf(">%gms", p.Latency[len(p.Latency)-1].Le)
+}
+
+func mib(b uint64) string {
+	return fmt.Sprintf("%.1fMiB", float64(b)/1024/1024)
+}
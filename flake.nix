@@ -127,7 +127,7 @@
                 set -- "$dir"/*.go
                 if [ -e "$1" ]; then
                   echo "Building provider: $provider"
-                  if ! go build -buildmode=plugin -o "$provider.so" ./internal/providers/"$provider"; then
+                  if ! go build -buildmode=plugin -o "$provider.so" ./internal/providers/"$provider"/plugin; then
                     echo "⚠ Failed to build provider: $provider"
                     exit 1
                   fi
@@ -0,0 +1,5 @@
+//go:build builtin || builtin_bookmarks
+
+package builtin
+
+import _ "github.com/abenz1267/elephant/v2/internal/providers/bookmarks"
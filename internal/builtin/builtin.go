@@ -0,0 +1,5 @@
+// Package builtin compiles providers into elephant. Building with the
+// "builtin" tag includes all providers, "builtin_<provider>" single ones, f.e.
+//
+//	go build -tags builtin_desktopapplications,builtin_websearch ./cmd/elephant
+package builtin
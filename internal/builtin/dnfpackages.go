@@ -0,0 +1,5 @@
+//go:build builtin || builtin_dnfpackages
+
+package builtin
+
+import _ "github.com/abenz1267/elephant/v2/internal/providers/dnfpackages"
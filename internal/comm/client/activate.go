@@ -2,10 +2,14 @@
 package client
 
 import (
+	"bufio"
 	"bytes"
 	"encoding/binary"
 	"encoding/json"
+	"fmt"
+	"io"
 	"net"
+	"os"
 	"strings"
 
 	"github.com/abenz1267/elephant/v2/pkg/pb/pb"
@@ -46,4 +50,28 @@ func Activate(data string) {
 	if err != nil {
 		panic(err)
 	}
+
+	reader := bufio.NewReader(conn)
+
+	for {
+		header := make([]byte, 5)
+		if _, err := io.ReadFull(reader, header); err != nil {
+			return
+		}
+
+		msg := make([]byte, binary.BigEndian.Uint32(header[1:5]))
+		if _, err := io.ReadFull(reader, msg); err != nil {
+			return
+		}
+
+		switch header[0] {
+		case errorFrame:
+			printError(msg)
+		case finished:
+			return
+		case shutdown:
+			fmt.Fprintln(os.Stderr, "elephant is shutting down")
+			return
+		}
+	}
 }
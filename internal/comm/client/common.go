@@ -1,6 +1,122 @@
 package client
 
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+
+	"github.com/abenz1267/elephant/v2/pkg/pb/pb"
+)
+
 const (
-	done  = 255
-	empty = 254
+	done         = 255
+	empty        = 254
+	statusDone   = 253
+	finished     = 2
+	providerDone = 5
+	order        = 6
+	errorFrame   = 7
+	shutdown     = 8
+	stats        = 9
 )
+
+var (
+	errRequest  = errors.New("request failed")
+	errShutdown = errors.New("elephant is shutting down")
+)
+
+// statusRequest sends a JSON request which is answered with a StatusDone
+// frame, printing errors reported before.
+func statusRequest(reqType uint8, req any) error {
+	return request(reqType, req, nil)
+}
+
+// request sends a JSON request and passes every frame besides errors to
+// frame until the StatusDone frame arrives. Errors are printed.
+func request(reqType uint8, req any, frame func(kind byte, payload []byte) error) error {
+	b, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	conn, err := net.Dial("unix", socket)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if err := handshake(conn, int(reqType)); err != nil {
+		return err
+	}
+
+	var buffer bytes.Buffer
+	buffer.Write([]byte{reqType})
+	buffer.Write([]byte{1})
+
+	lengthBuf := make([]byte, 4)
+	binary.BigEndian.PutUint32(lengthBuf, uint32(len(b)))
+	buffer.Write(lengthBuf)
+	buffer.Write(b)
+
+	if _, err := conn.Write(buffer.Bytes()); err != nil {
+		return err
+	}
+
+	reader := bufio.NewReader(conn)
+	failed := false
+
+	for {
+		header := make([]byte, 5)
+		if _, err := io.ReadFull(reader, header); err != nil {
+			return err
+		}
+
+		msg := make([]byte, binary.BigEndian.Uint32(header[1:5]))
+		if _, err := io.ReadFull(reader, msg); err != nil {
+			return err
+		}
+
+		switch header[0] {
+		case errorFrame:
+			printError(msg)
+			failed = true
+		case statusDone:
+			if failed {
+				return errRequest
+			}
+
+			return nil
+		case shutdown:
+			return errShutdown
+		default:
+			if frame != nil {
+				if err := frame(header[0], msg); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+// printError prints the payload of an error frame to stderr.
+func printError(payload []byte) {
+	res := &pb.ErrorResponse{}
+
+	if err := json.Unmarshal(payload, res); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return
+	}
+
+	if res.Provider != "" {
+		fmt.Fprintf(os.Stderr, "error: %s: %s (%s)\n", res.Provider, res.Message, res.Code)
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "error: %s (%s)\n", res.Message, res.Code)
+}
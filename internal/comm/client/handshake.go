@@ -0,0 +1,120 @@
+package client
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"time"
+
+	"github.com/abenz1267/elephant/v2/pkg/pb/pb"
+)
+
+const (
+	protocolVersion = 1
+	handshakeType   = 5
+	handshakeResp   = 4
+)
+
+// Version is the client version sent to the server during the handshake.
+var Version string
+
+var errIncompatible = errors.New("incompatible elephant server")
+
+var errNoAnswer = errors.New("elephant server did not answer handshake")
+
+// handshake negotiates with the server before sending a request that was
+// added after the first protocol version. Query, activate, menu and state
+// are sent without it, daemons predating the handshake don't know the
+// request type. Servers not answering the handshake are assumed to speak
+// the current protocol, mismatching servers are only rejected if they don't
+// support the request the client is about to send.
+func handshake(conn net.Conn, request int) error {
+	resp, err := exchangeHandshake(conn)
+	if errors.Is(err, errNoAnswer) {
+		fmt.Fprintln(os.Stderr, "warning: elephant server did not answer handshake, continuing without version check")
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if resp.Protocol == protocolVersion {
+		return nil
+	}
+
+	for _, v := range resp.Requests {
+		if int(v.Type) == request {
+			fmt.Fprintf(os.Stderr, "warning: elephant server %s uses protocol %d, client %s uses %d. restart elephant after upgrading.\n", resp.Version, resp.Protocol, Version, protocolVersion)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: server %s (protocol %d) does not support request %d", errIncompatible, resp.Version, resp.Protocol, request)
+}
+
+// ServerInfo connects to the running elephant and returns its handshake
+// response.
+func ServerInfo() (*pb.HandshakeResponse, error) {
+	conn, err := net.Dial("unix", socket)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	return exchangeHandshake(conn)
+}
+
+func exchangeHandshake(conn net.Conn) (*pb.HandshakeResponse, error) {
+	req := pb.HandshakeRequest{
+		Version:  Version,
+		Protocol: protocolVersion,
+	}
+
+	b, err := json.Marshal(&req)
+	if err != nil {
+		return nil, err
+	}
+
+	var buffer bytes.Buffer
+	buffer.Write([]byte{handshakeType})
+	buffer.Write([]byte{1})
+
+	lengthBuf := make([]byte, 4)
+	binary.BigEndian.PutUint32(lengthBuf, uint32(len(b)))
+	buffer.Write(lengthBuf)
+	buffer.Write(b)
+
+	if _, err := conn.Write(buffer.Bytes()); err != nil {
+		return nil, err
+	}
+
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	defer conn.SetReadDeadline(time.Time{})
+
+	header := make([]byte, 5)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return nil, errNoAnswer
+	}
+
+	if header[0] != handshakeResp {
+		return nil, fmt.Errorf("%w: unexpected handshake response %d", errIncompatible, header[0])
+	}
+
+	payload := make([]byte, binary.BigEndian.Uint32(header[1:5]))
+	if _, err := io.ReadFull(conn, payload); err != nil {
+		return nil, err
+	}
+
+	resp := &pb.HandshakeResponse{}
+	if err := json.Unmarshal(payload, resp); err != nil {
+		return nil, err
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,11 @@
+package client
+
+import (
+	"github.com/abenz1267/elephant/v2/pkg/pb/pb"
+)
+
+// SwitchProfile asks the running elephant to switch to the given profile. An
+// empty profile switches back to the base configuration.
+func SwitchProfile(profile string) error {
+	return statusRequest(8, &pb.ProfileRequest{Profile: profile})
+}
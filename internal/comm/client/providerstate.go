@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"io"
 	"net"
+	"os"
 
 	"github.com/abenz1267/elephant/v2/pkg/pb/pb"
 )
@@ -54,11 +55,16 @@ func ProviderState(data string, j bool) {
 			panic(err)
 		}
 
-		if header[0] == 253 {
+		if header[0] == statusDone {
 			break
 		}
 
-		if header[0] != 3 {
+		if header[0] == shutdown {
+			fmt.Fprintln(os.Stderr, "elephant is shutting down")
+			break
+		}
+
+		if header[0] != 3 && header[0] != errorFrame {
 			panic("invalid protocol prefix")
 		}
 
@@ -72,6 +78,11 @@ func ProviderState(data string, j bool) {
 
 		payload := msg[5:]
 
+		if header[0] == errorFrame {
+			printError(payload)
+			continue
+		}
+
 		resp := &pb.ProviderStateResponse{}
 		if err := json.Unmarshal(payload, resp); err != nil {
 			panic(err)
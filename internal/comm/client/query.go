@@ -16,6 +16,7 @@ import (
 	"strings"
 
 	"github.com/abenz1267/elephant/v2/pkg/pb/pb"
+	"google.golang.org/protobuf/proto"
 )
 
 var socket string
@@ -31,7 +32,7 @@ func init() {
 	}
 }
 
-func Query(data string, async, j bool) {
+func Query(data string, async, j, stream bool, offset int) {
 	v := strings.Split(data, ";")
 	if len(v) < 3 || len(v) > 4 {
 		fmt.Fprintln(os.Stderr, "query expects '<providers>;<query>;<limit>[;exactsearch]'")
@@ -58,6 +59,8 @@ func Query(data string, async, j bool) {
 		Query:       v[1],
 		Maxresults:  int32(maxresults),
 		Exactsearch: exact,
+		Stream:      stream,
+		Offset:      int32(offset),
 	}
 
 	b, err := json.Marshal(&req)
@@ -100,7 +103,12 @@ func Query(data string, async, j bool) {
 			break
 		}
 
-		if header[0] != 0 && header[0] != 1 && header[0] != done && header[0] != empty {
+		if header[0] == shutdown {
+			fmt.Fprintln(os.Stderr, "elephant is shutting down")
+			break
+		}
+
+		if header[0] != 0 && header[0] != 1 && header[0] != providerDone && header[0] != order && header[0] != done && header[0] != empty && header[0] != errorFrame {
 			panic("invalid protocol prefix")
 		}
 
@@ -112,9 +120,28 @@ func Query(data string, async, j bool) {
 			panic(err)
 		}
 
+		if header[0] == empty || header[0] == done {
+			continue
+		}
+
 		payload := msg[5:]
 
-		resp := &pb.QueryResponse{}
+		if header[0] == errorFrame {
+			printError(payload)
+			continue
+		}
+
+		var resp proto.Message
+
+		switch header[0] {
+		case providerDone:
+			resp = &pb.QueryProviderDone{}
+		case order:
+			resp = &pb.QueryOrder{}
+		default:
+			resp = &pb.QueryResponse{}
+		}
+
 		if err := json.Unmarshal(payload, resp); err != nil {
 			panic(err)
 		}
@@ -0,0 +1,10 @@
+package client
+
+import (
+	"github.com/abenz1267/elephant/v2/pkg/pb/pb"
+)
+
+// Reload asks the running elephant to reload its configuration.
+func Reload() error {
+	return statusRequest(7, &pb.ReloadRequest{})
+}
@@ -0,0 +1,22 @@
+package client
+
+import (
+	"encoding/json"
+
+	"github.com/abenz1267/elephant/v2/pkg/pb/pb"
+)
+
+// Stats requests the runtime metrics of the running elephant.
+func Stats() (*pb.StatsResponse, error) {
+	res := &pb.StatsResponse{}
+
+	err := request(9, &pb.StatsRequest{}, func(kind byte, payload []byte) error {
+		if kind != stats {
+			return nil
+		}
+
+		return json.Unmarshal(payload, res)
+	})
+
+	return res, err
+}
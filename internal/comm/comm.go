@@ -3,19 +3,25 @@ package comm
 
 import (
 	"encoding/binary"
+	"errors"
+	"fmt"
 	"io"
 	"log/slog"
 	"net"
 	"os"
 	"path/filepath"
+	"runtime/debug"
+	"sync/atomic"
 
 	"github.com/abenz1267/elephant/v2/internal/comm/handlers"
+	"github.com/abenz1267/elephant/v2/pkg/pb/pb"
 )
 
 // connection id
 var (
-	cid    uint32
+	cid    atomic.Uint32
 	Socket string
+	auth   = &handlers.AuthRequest{}
 )
 
 var registry []MessageHandler
@@ -25,15 +31,33 @@ type MessageHandler interface {
 }
 
 const (
-	QueryRequestHandlerPos     = 0
-	ActivateRequestHandlerPos  = 1
-	SubscribeRequestHandlerPos = 2
-	MenuRequestHandlerPos      = 3
-	StateRequestHandlerPos     = 4
+	QueryRequestHandlerPos     = handlers.QueryRequestType
+	ActivateRequestHandlerPos  = handlers.ActivateRequestType
+	SubscribeRequestHandlerPos = handlers.SubscribeRequestType
+	MenuRequestHandlerPos      = handlers.MenuRequestType
+	StateRequestHandlerPos     = handlers.StateRequestType
+	HandshakeRequestHandlerPos = handlers.HandshakeRequestType
+	AuthRequestHandlerPos      = handlers.AuthRequestType
+	ReloadRequestHandlerPos    = handlers.ReloadRequestType
+	ProfileRequestHandlerPos   = handlers.ProfileRequestType
+	StatsRequestHandlerPos     = handlers.StatsRequestType
 	Protobuf                   = 0
 	JSON                       = 1
 )
 
+var requestNames = map[int]string{
+	QueryRequestHandlerPos:     "query",
+	ActivateRequestHandlerPos:  "activate",
+	SubscribeRequestHandlerPos: "subscribe",
+	MenuRequestHandlerPos:      "menu",
+	StateRequestHandlerPos:     "state",
+	HandshakeRequestHandlerPos: "handshake",
+	AuthRequestHandlerPos:      "auth",
+	ReloadRequestHandlerPos:    "reload",
+	ProfileRequestHandlerPos:   "profile",
+	StatsRequestHandlerPos:     "stats",
+}
+
 func init() {
 	rd := os.Getenv("XDG_RUNTIME_DIR")
 
@@ -44,15 +68,37 @@ func init() {
 		Socket = filepath.Join(rd, "elephant", "elephant.sock")
 	}
 
-	os.MkdirAll(filepath.Dir(Socket), 0o755)
+	os.MkdirAll(filepath.Dir(Socket), 0o700)
+	os.Chmod(filepath.Dir(Socket), 0o700)
 
-	registry = make([]MessageHandler, 255)
+	registry = make([]MessageHandler, 256)
 
 	registry[QueryRequestHandlerPos] = &handlers.QueryRequest{}
 	registry[ActivateRequestHandlerPos] = &handlers.ActivateRequest{}
 	registry[SubscribeRequestHandlerPos] = &handlers.SubscribeRequest{}
 	registry[MenuRequestHandlerPos] = &handlers.MenuRequest{}
 	registry[StateRequestHandlerPos] = &handlers.StateRequest{}
+	registry[AuthRequestHandlerPos] = auth
+	registry[ReloadRequestHandlerPos] = &handlers.ReloadRequest{}
+	registry[ProfileRequestHandlerPos] = &handlers.ProfileRequest{}
+	registry[StatsRequestHandlerPos] = &handlers.StatsRequest{}
+
+	handlers.Connections = connections
+
+	handshake := &handlers.HandshakeRequest{}
+
+	for k := range registry {
+		if registry[k] == nil && k != HandshakeRequestHandlerPos {
+			continue
+		}
+
+		handshake.Requests = append(handshake.Requests, &pb.HandshakeResponse_Request{
+			Type: int32(k),
+			Name: requestNames[k],
+		})
+	}
+
+	registry[HandshakeRequestHandlerPos] = handshake
 }
 
 func StartListen() {
@@ -66,46 +112,79 @@ func StartListen() {
 	}
 	defer l.Close()
 
+	addListener(l)
+
+	if err := os.Chmod(Socket, 0o600); err != nil {
+		slog.Error("comm", "socket", err)
+	}
+
 	slog.Info("comm", "listen", "starting")
 
+	startRemote()
+
 	for {
 		conn, err := l.AcceptUnix()
 		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				// closed by Shutdown, which exits once it's done.
+				select {}
+			}
+
 			slog.Error("comm", "accept", err)
+			continue
 		}
 
-		slog.Info("comm", "connection", "new")
+		activate, err := checkPeer(conn)
+		if err != nil {
+			slog.Warn("comm", "peer", err)
+			conn.Close()
+			continue
+		}
 
-		cid++
+		slog.Info("comm", "connection", "new")
 
-		go handle(conn, cid)
+		go handle(newConn(conn), cid.Add(1), access{authenticated: true, activate: activate})
 	}
 }
 
-func handle(conn net.Conn, cid uint32) {
+// access describes what a connection is allowed to do.
+type access struct {
+	authenticated bool
+	activate      bool
+}
+
+// handle reads requests until the connection is closed. Unauthenticated
+// connections have to send an AuthRequest first, otherwise they are closed.
+const (
+	// maxFrameSize is the largest payload a client may send.
+	maxFrameSize = 1 << 20
+	// maxAuthFrameSize is the largest payload accepted before authenticating.
+	maxAuthFrameSize = 1 << 10
+)
+
+func handle(conn *conn, cid uint32, a access) {
+	addConn(cid, conn)
+
+	defer handlers.ClearConnection(cid)
+	defer removeConn(cid)
 	defer conn.Close()
 
 	for {
 		tb := make([]byte, 1)
 		if _, err := io.ReadFull(conn, tb); err != nil {
-			if err == io.EOF {
-				break
+			if err != io.EOF && !errors.Is(err, net.ErrClosed) {
+				slog.Error("conn", "readtype", err)
 			}
 
-			slog.Error("conn", "readtype", err)
-			continue
+			break
 		}
 
 		mType := int(tb[0])
 
 		fb := make([]byte, 1)
 		if _, err := io.ReadFull(conn, fb); err != nil {
-			if err == io.EOF {
-				break
-			}
-
-			slog.Error("conn", "readtype", err)
-			continue
+			slog.Error("conn", "readformat", err)
+			break
 		}
 
 		format := uint8(fb[0])
@@ -113,17 +192,97 @@ func handle(conn net.Conn, cid uint32) {
 		lb := make([]byte, 4)
 		if _, err := io.ReadFull(conn, lb); err != nil {
 			slog.Error("conn", "readlength", err)
-			continue
+			break
 		}
 
 		l := binary.BigEndian.Uint32(lb)
 
+		limit := uint32(maxFrameSize)
+		if !a.authenticated {
+			limit = maxAuthFrameSize
+		}
+
+		if l > limit {
+			slog.Warn("conn", "frame", "too large, closing connection", "size", l, "limit", limit)
+
+			handlers.WriteError(format, &pb.ErrorResponse{
+				Code:    pb.ErrorResponse_INVALID_REQUEST,
+				Message: fmt.Sprintf("frame of %d bytes exceeds the limit of %d bytes", l, limit),
+				Type:    int32(mType),
+			}, conn)
+
+			break
+		}
+
 		p := make([]byte, l)
 		if _, err := io.ReadFull(conn, p); err != nil {
 			slog.Error("conn", "readpayload", err)
+			break
+		}
+
+		if !a.authenticated {
+			if mType != AuthRequestHandlerPos {
+				slog.Warn("conn", "auth", "unauthenticated request, closing connection", "type", mType)
+
+				handlers.WriteError(format, &pb.ErrorResponse{
+					Code:    pb.ErrorResponse_UNAUTHENTICATED,
+					Message: "authenticate first",
+					Type:    int32(mType),
+				}, conn)
+
+				break
+			}
+
+			if !auth.Authenticate(format, conn, p) {
+				break
+			}
+
+			a.authenticated = true
+			continue
+		}
+
+		if mType == ActivateRequestHandlerPos && !a.activate {
+			slog.Warn("conn", "activate", "connection is not allowed to activate")
+
+			handlers.Reject(format, &pb.ErrorResponse{
+				Code:    pb.ErrorResponse_FORBIDDEN,
+				Message: "connection is not allowed to activate",
+				Type:    int32(mType),
+			}, conn)
+
+			continue
+		}
+
+		if registry[mType] == nil {
+			slog.Error("conn", "unknown request type", mType)
+
+			handlers.WriteError(format, &pb.ErrorResponse{
+				Code:    pb.ErrorResponse_UNKNOWN_REQUEST,
+				Message: fmt.Sprintf("unknown request type: %d", mType),
+				Type:    int32(mType),
+			}, conn)
+
 			continue
 		}
 
-		go registry[mType].Handle(format, cid, conn, p)
+		go handleRequest(format, mType, cid, conn, p)
 	}
 }
+
+// handleRequest runs the handler of a request. A panic is answered with an
+// error instead of taking down elephant.
+func handleRequest(format uint8, mType int, cid uint32, conn net.Conn, p []byte) {
+	defer func() {
+		if r := recover(); r != nil {
+			slog.Error("conn", "panic", r, "type", requestNames[mType], "stack", string(debug.Stack()))
+
+			handlers.Reject(format, &pb.ErrorResponse{
+				Code:    pb.ErrorResponse_INTERNAL,
+				Message: fmt.Sprintf("panic: %v", r),
+				Type:    int32(mType),
+			}, conn)
+		}
+	}()
+
+	registry[mType].Handle(format, cid, conn, p)
+}
@@ -0,0 +1,128 @@
+package comm
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"net"
+	"sync"
+	"time"
+)
+
+var (
+	// writeQueueSize is the amount of frames buffered per connection.
+	writeQueueSize = 512
+	// enqueueTimeout is how long a handler waits for a full queue before the
+	// client is considered stuck and gets dropped.
+	enqueueTimeout = 5 * time.Second
+	// writeTimeout is the socket write deadline for a single frame.
+	writeTimeout = 5 * time.Second
+)
+
+var errSlowClient = errors.New("client is not reading, dropping connection")
+
+// conn owns the socket of a client. Handlers and providers write complete
+// frames concurrently, conn queues them and a single goroutine writes them to
+// the socket, so frames never interleave.
+type conn struct {
+	net.Conn
+
+	queue  chan []byte
+	done   chan struct{}
+	closed chan struct{}
+	once   sync.Once
+}
+
+func newConn(c net.Conn) *conn {
+	res := &conn{
+		Conn:   c,
+		queue:  make(chan []byte, writeQueueSize),
+		done:   make(chan struct{}),
+		closed: make(chan struct{}),
+	}
+
+	go res.writer()
+
+	return res
+}
+
+// Write queues a single frame. It never writes partial frames, so every call
+// has to contain exactly one frame.
+func (c *conn) Write(b []byte) (int, error) {
+	select {
+	case <-c.done:
+		return 0, net.ErrClosed
+	default:
+	}
+
+	frame := bytes.Clone(b)
+
+	timer := time.NewTimer(enqueueTimeout)
+	defer timer.Stop()
+
+	select {
+	case c.queue <- frame:
+		return len(b), nil
+	case <-c.done:
+		return 0, net.ErrClosed
+	case <-timer.C:
+		slog.Error("comm", "write", errSlowClient)
+		go c.Close()
+		return 0, errSlowClient
+	}
+}
+
+// Close stops accepting frames, flushes the ones already queued and closes
+// the socket.
+func (c *conn) Close() error {
+	c.once.Do(func() {
+		close(c.done)
+	})
+
+	<-c.closed
+
+	return nil
+}
+
+func (c *conn) writer() {
+	defer func() {
+		c.Conn.Close()
+		close(c.closed)
+	}()
+
+	for {
+		select {
+		case b := <-c.queue:
+			if !c.write(b) {
+				return
+			}
+		case <-c.done:
+			for {
+				select {
+				case b := <-c.queue:
+					if !c.write(b) {
+						return
+					}
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (c *conn) write(b []byte) bool {
+	c.Conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+
+	if _, err := c.Conn.Write(b); err != nil {
+		slog.Debug("comm", "write", err)
+
+		c.once.Do(func() {
+			close(c.done)
+		})
+
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,444 @@
+package comm
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/abenz1267/elephant/v2/internal/comm/handlers"
+	"github.com/abenz1267/elephant/v2/internal/providers"
+	"github.com/abenz1267/elephant/v2/pkg/pb/pb"
+)
+
+type frame struct {
+	kind    byte
+	payload []byte
+}
+
+func readFrame(r io.Reader) (frame, error) {
+	header := make([]byte, 5)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return frame{}, err
+	}
+
+	payload := make([]byte, binary.BigEndian.Uint32(header[1:]))
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return frame{}, err
+	}
+
+	return frame{kind: header[0], payload: payload}, nil
+}
+
+func encodeFrame(kind byte, payload []byte) []byte {
+	var buffer bytes.Buffer
+	buffer.Write([]byte{kind})
+
+	lengthBuf := make([]byte, 4)
+	binary.BigEndian.PutUint32(lengthBuf, uint32(len(payload)))
+	buffer.Write(lengthBuf)
+	buffer.Write(payload)
+
+	return buffer.Bytes()
+}
+
+func TestConnParallelQueriesAndAsyncUpdates(t *testing.T) {
+	const (
+		queries        = 40
+		itemsPerQuery  = 25
+		asyncPerQuery  = 10
+		providerName   = "hammer"
+		providerPretty = "Hammer"
+	)
+
+	name := providerName
+	pretty := providerPretty
+
+	providers.Providers = map[string]providers.Provider{
+		providerName: {
+			Name:       &name,
+			NamePretty: &pretty,
+			Query: func(conn net.Conn, query string, single bool, exact bool, format uint8) []*pb.QueryResponse_Item {
+				res := []*pb.QueryResponse_Item{}
+
+				for i := range itemsPerQuery {
+					res = append(res, &pb.QueryResponse_Item{
+						Identifier: fmt.Sprintf("%s-%d", query, i),
+						Text:       fmt.Sprintf("%s item %d with some padding to make frames larger", query, i),
+						Provider:   providerName,
+						Score:      int32(i),
+					})
+				}
+
+				for i := range asyncPerQuery {
+					go handlers.UpdateItem(format, query, conn, &pb.QueryResponse_Item{
+						Identifier: fmt.Sprintf("%s-async-%d", query, i),
+						Text:       "async",
+						Provider:   providerName,
+					})
+				}
+
+				return res
+			},
+		},
+	}
+	defer func() { providers.Providers = nil }()
+
+	server, client := net.Pipe()
+	c := newConn(server)
+	defer c.Close()
+
+	var wg sync.WaitGroup
+
+	for i := range queries {
+		wg.Go(func() {
+			req := pb.QueryRequest{
+				Providers:  []string{providerName},
+				Query:      fmt.Sprintf("q%d", i),
+				Maxresults: itemsPerQuery,
+				Rid:        uint32(i + 1),
+			}
+
+			b, err := json.Marshal(&req)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			// separate connection ids, so queries don't cancel each other.
+			(&handlers.QueryRequest{}).Handle(JSON, uint32(1000+i), c, b)
+		})
+	}
+
+	items := make(map[uint32]int)
+	done := make(map[uint32]int)
+	async := 0
+
+	client.SetReadDeadline(time.Now().Add(30 * time.Second))
+
+	for len(done) < queries || async < queries*asyncPerQuery {
+		f, err := readFrame(client)
+		if err != nil {
+			t.Fatalf("read frame: %v (items %d, done %d, async %d)", err, len(items), len(done), async)
+		}
+
+		switch f.kind {
+		case handlers.QueryItem:
+			resp := &pb.QueryResponse{}
+			if err := json.Unmarshal(f.payload, resp); err != nil {
+				t.Fatalf("corrupt item frame: %v", err)
+			}
+
+			items[resp.Rid]++
+		case handlers.QueryAsyncItem:
+			resp := &pb.QueryResponse{}
+			if err := json.Unmarshal(f.payload, resp); err != nil {
+				t.Fatalf("corrupt async frame: %v", err)
+			}
+
+			if resp.Query != fmt.Sprintf("q%d", resp.Rid-1) || resp.Qid == 0 {
+				t.Fatalf("async frame for %q has rid %d and qid %d", resp.Query, resp.Rid, resp.Qid)
+			}
+
+			async++
+		case handlers.QueryDone:
+			resp := &pb.StatusResponse{}
+			if err := json.Unmarshal(f.payload, resp); err != nil {
+				t.Fatalf("corrupt status frame: %v", err)
+			}
+
+			if items[resp.Rid] != itemsPerQuery {
+				t.Fatalf("rid %d done after %d items, want %d", resp.Rid, items[resp.Rid], itemsPerQuery)
+			}
+
+			done[resp.Rid]++
+		default:
+			t.Fatalf("unexpected frame type %d", f.kind)
+		}
+	}
+
+	wg.Wait()
+
+	for rid, n := range done {
+		if n != 1 {
+			t.Errorf("rid %d finished %d times", rid, n)
+		}
+	}
+}
+
+func TestConnCloseFlushesQueuedFrames(t *testing.T) {
+	server, client := net.Pipe()
+	c := newConn(server)
+
+	const frames = 100
+
+	read := make(chan int)
+
+	go func() {
+		n := 0
+
+		for {
+			f, err := readFrame(client)
+			if err != nil {
+				read <- n
+				return
+			}
+
+			if int(f.kind) != n%256 {
+				t.Errorf("frame %d has type %d", n, f.kind)
+			}
+
+			n++
+		}
+	}()
+
+	for i := range frames {
+		if _, err := c.Write(encodeFrame(byte(i), []byte("payload"))); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	c.Close()
+
+	if n := <-read; n != frames {
+		t.Errorf("read %d frames, want %d", n, frames)
+	}
+
+	if _, err := c.Write(encodeFrame(0, nil)); !errors.Is(err, net.ErrClosed) {
+		t.Errorf("write after close: %v", err)
+	}
+}
+
+func TestConnDropsStuckClient(t *testing.T) {
+	oldQueue, oldEnqueue, oldWrite := writeQueueSize, enqueueTimeout, writeTimeout
+	writeQueueSize, enqueueTimeout, writeTimeout = 4, 50*time.Millisecond, 50*time.Millisecond
+
+	defer func() {
+		writeQueueSize, enqueueTimeout, writeTimeout = oldQueue, oldEnqueue, oldWrite
+	}()
+
+	server, client := net.Pipe()
+	defer client.Close()
+
+	c := newConn(server)
+
+	deadline := time.Now().Add(5 * time.Second)
+
+	for {
+		if _, err := c.Write(encodeFrame(0, []byte("nobody reads this"))); err != nil {
+			break
+		}
+
+		if time.Now().After(deadline) {
+			t.Fatal("connection was never dropped")
+		}
+	}
+
+	select {
+	case <-c.closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("socket was not closed")
+	}
+}
+
+func TestQueryPagingUsesCachedResults(t *testing.T) {
+	const providerName = "pager"
+
+	name := providerName
+	calls := 0
+
+	providers.Providers = map[string]providers.Provider{
+		providerName: {
+			Name:       &name,
+			NamePretty: &name,
+			Query: func(conn net.Conn, query string, single bool, exact bool, format uint8) []*pb.QueryResponse_Item {
+				calls++
+
+				res := []*pb.QueryResponse_Item{}
+
+				for i := range 25 {
+					res = append(res, &pb.QueryResponse_Item{
+						Identifier: fmt.Sprintf("%d", i),
+						Text:       fmt.Sprintf("item %d", i),
+						Provider:   providerName,
+						Score:      int32(100 - i),
+					})
+				}
+
+				return res
+			},
+		},
+	}
+	defer func() { providers.Providers = nil }()
+
+	server, client := net.Pipe()
+	c := newConn(server)
+	defer c.Close()
+	defer handlers.ClearConnection(1)
+
+	client.SetReadDeadline(time.Now().Add(10 * time.Second))
+
+	page := func(offset int32) []string {
+		b, err := json.Marshal(&pb.QueryRequest{
+			Providers:  []string{providerName},
+			Maxresults: 10,
+			Offset:     offset,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		go (&handlers.QueryRequest{}).Handle(JSON, 1, c, b)
+
+		res := []string{}
+
+		for {
+			f, err := readFrame(client)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			switch f.kind {
+			case handlers.QueryItem:
+				resp := &pb.QueryResponse{}
+				if err := json.Unmarshal(f.payload, resp); err != nil {
+					t.Fatal(err)
+				}
+
+				if resp.Total != 25 {
+					t.Errorf("total %d, want 25", resp.Total)
+				}
+
+				res = append(res, resp.Item.Identifier)
+			case handlers.QueryNoResults:
+			case handlers.QueryDone:
+				return res
+			default:
+				t.Fatalf("unexpected frame type %d", f.kind)
+			}
+		}
+	}
+
+	if got := page(0); len(got) != 10 || got[0] != "0" {
+		t.Fatalf("first page: %v", got)
+	}
+
+	if got := page(10); len(got) != 10 || got[0] != "10" {
+		t.Fatalf("second page: %v", got)
+	}
+
+	if got := page(20); len(got) != 5 || got[4] != "24" {
+		t.Fatalf("last page: %v", got)
+	}
+
+	if got := page(30); len(got) != 0 {
+		t.Fatalf("page past the end: %v", got)
+	}
+
+	if calls != 1 {
+		t.Errorf("provider queried %d times, want 1", calls)
+	}
+}
+
+func TestConnRejectsOversizedFrameBeforeAuth(t *testing.T) {
+	server, client := net.Pipe()
+	defer client.Close()
+
+	go handle(newConn(server), 1, access{})
+
+	header := []byte{byte(AuthRequestHandlerPos), JSON, 0, 0, 0, 0}
+	binary.BigEndian.PutUint32(header[2:], maxAuthFrameSize+1)
+
+	client.SetDeadline(time.Now().Add(5 * time.Second))
+
+	if _, err := client.Write(header); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := readFrame(client)
+	if err != nil {
+		t.Fatalf("read frame: %v", err)
+	}
+
+	resp := &pb.ErrorResponse{}
+	if err := json.Unmarshal(f.payload, resp); err != nil {
+		t.Fatal(err)
+	}
+
+	if f.kind != handlers.Error || resp.Code != pb.ErrorResponse_INVALID_REQUEST {
+		t.Fatalf("got frame %d with code %s, expected an INVALID_REQUEST error", f.kind, resp.Code)
+	}
+
+	if _, err := readFrame(client); !errors.Is(err, io.EOF) {
+		t.Errorf("connection not closed: %v", err)
+	}
+}
+
+type panicHandler struct{}
+
+func (panicHandler) Handle(format uint8, cid uint32, conn net.Conn, data []byte) {
+	panic("boom")
+}
+
+func TestConnRecoversHandlerPanic(t *testing.T) {
+	old := registry[StatsRequestHandlerPos]
+	registry[StatsRequestHandlerPos] = panicHandler{}
+	defer func() { registry[StatsRequestHandlerPos] = old }()
+
+	server, client := net.Pipe()
+	defer client.Close()
+
+	go handle(newConn(server), 1, access{authenticated: true})
+
+	client.SetDeadline(time.Now().Add(5 * time.Second))
+
+	if _, err := client.Write([]byte{byte(StatsRequestHandlerPos), JSON, 0, 0, 0, 0}); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := readFrame(client)
+	if err != nil {
+		t.Fatalf("read frame: %v", err)
+	}
+
+	resp := &pb.ErrorResponse{}
+	if err := json.Unmarshal(f.payload, resp); err != nil {
+		t.Fatal(err)
+	}
+
+	if f.kind != handlers.Error || resp.Code != pb.ErrorResponse_INTERNAL {
+		t.Fatalf("got frame %d with code %s, expected an INTERNAL error", f.kind, resp.Code)
+	}
+
+	if f, err := readFrame(client); err != nil || f.kind != handlers.StatusDone {
+		t.Fatalf("got frame %d (%v), expected StatusDone", f.kind, err)
+	}
+}
+
+func TestCheckListenAddr(t *testing.T) {
+	tests := []struct {
+		addr        string
+		allowRemote bool
+		ok          bool
+	}{
+		{"127.0.0.1:7373", false, true},
+		{"[::1]:7373", false, true},
+		{"localhost:7373", false, true},
+		{":7373", false, false},
+		{"0.0.0.0:7373", false, false},
+		{"192.168.1.2:7373", false, false},
+		{"192.168.1.2:7373", true, true},
+	}
+
+	for _, v := range tests {
+		if err := checkListenAddr(v.addr, v.allowRemote); (err == nil) != v.ok {
+			t.Errorf("checkListenAddr(%q, %t) = %v", v.addr, v.allowRemote, err)
+		}
+	}
+}
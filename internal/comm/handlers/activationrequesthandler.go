@@ -1,16 +1,13 @@
 package handlers
 
 import (
-	"bytes"
-	"encoding/binary"
-	"encoding/json"
+	"fmt"
 	"log/slog"
 	"net"
 	"strings"
 
 	"github.com/abenz1267/elephant/v2/internal/providers"
 	"github.com/abenz1267/elephant/v2/pkg/pb/pb"
-	"google.golang.org/protobuf/proto"
 )
 
 type ActivateRequest struct{}
@@ -18,19 +15,8 @@ type ActivateRequest struct{}
 func (a *ActivateRequest) Handle(format uint8, cid uint32, conn net.Conn, data []byte) {
 	req := &pb.ActivateRequest{}
 
-	switch format {
-	case 0:
-		if err := proto.Unmarshal(data, req); err != nil {
-			slog.Error("activationrequesthandler", "protobuf", err)
-
-			return
-		}
-	case 1:
-		if err := json.Unmarshal(data, req); err != nil {
-			slog.Error("activationrequesthandler", "protobuf", err)
-
-			return
-		}
+	if !unmarshal(format, ActivateRequestType, data, req, conn) {
+		return
 	}
 
 	provider := req.Provider
@@ -39,22 +25,40 @@ func (a *ActivateRequest) Handle(format uint8, cid uint32, conn net.Conn, data [
 		provider = strings.Split(provider, ":")[0]
 	}
 
-	if p, ok := providers.Providers[provider]; ok {
-		p.Activate(req.Single, req.Identifier, req.Action, req.Query, req.Arguments, format, conn)
+	p, ok := providers.Get(provider)
+	if !ok {
+		Reject(format, &pb.ErrorResponse{
+			Code:     pb.ErrorResponse_UNKNOWN_PROVIDER,
+			Message:  fmt.Sprintf("unknown provider: %s", provider),
+			Type:     ActivateRequestType,
+			Rid:      req.Rid,
+			Provider: req.Provider,
+		}, conn)
+
+		return
+	}
 
-		var buffer bytes.Buffer
-		buffer.Write([]byte{ActivationFinished})
+	err := p.RunActivate(req.Single, req.Identifier, req.Action, req.Query, req.Arguments, format, withRequest(conn, req.Rid, 0))
 
-		lengthBuf := make([]byte, 4)
-		binary.BigEndian.PutUint32(lengthBuf, uint32(0))
-		buffer.Write(lengthBuf)
+	recordActivation(provider, err != nil)
+
+	if err != nil {
+		slog.Error(provider, "activate", err, "action", req.Action, "identifier", req.Identifier)
+
+		WriteError(format, &pb.ErrorResponse{
+			Code:     providerErrorCode(err, pb.ErrorResponse_ACTIVATION_FAILED),
+			Message:  err.Error(),
+			Type:     ActivateRequestType,
+			Rid:      req.Rid,
+			Provider: req.Provider,
+		}, conn)
+	}
 
-		_, err := conn.Write(buffer.Bytes())
+	_, err = writeStatus(ActivationFinished, format, req.Rid, conn)
 
-		slog.Debug("activation", "provider", *p.Name, "identifier", req.Identifier)
+	slog.Debug("activation", "provider", *p.Name, "identifier", req.Identifier)
 
-		if err != nil {
-			slog.Debug("activation done", "write", err)
-		}
+	if err != nil {
+		slog.Debug("activation done", "write", err)
 	}
 }
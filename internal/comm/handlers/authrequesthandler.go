@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"log/slog"
+	"net"
+
+	"github.com/abenz1267/elephant/v2/pkg/pb/pb"
+)
+
+// AuthRequest authenticates connections of the tcp and websocket listeners.
+// Unix socket connections are always authenticated.
+type AuthRequest struct {
+	Token string
+}
+
+// Authenticate checks the token of an AuthRequest and confirms a successful
+// authentication with a StatusDone frame.
+func (a *AuthRequest) Authenticate(format uint8, conn net.Conn, data []byte) bool {
+	req := &pb.AuthRequest{}
+
+	if !unmarshal(format, AuthRequestType, data, req, conn) {
+		return false
+	}
+
+	if !a.Valid(req.Token) {
+		slog.Warn("authrequesthandler", "auth", "invalid token", "remote", conn.RemoteAddr())
+
+		WriteError(format, &pb.ErrorResponse{
+			Code:    pb.ErrorResponse_UNAUTHENTICATED,
+			Message: "invalid token",
+			Type:    AuthRequestType,
+			Rid:     req.Rid,
+		}, conn)
+
+		return false
+	}
+
+	writeStatus(StatusDone, format, req.Rid, conn)
+
+	return true
+}
+
+// Valid reports whether the token matches. An empty token never matches.
+func (a *AuthRequest) Valid(token string) bool {
+	if a.Token == "" {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(token), []byte(a.Token)) == 1
+}
+
+// Handle only confirms, as connections reaching the handler are already
+// authenticated.
+func (a *AuthRequest) Handle(format uint8, cid uint32, conn net.Conn, data []byte) {
+	req := &pb.AuthRequest{}
+
+	if !unmarshal(format, AuthRequestType, data, req, conn) {
+		return
+	}
+
+	writeStatus(StatusDone, format, req.Rid, conn)
+}
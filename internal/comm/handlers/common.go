@@ -2,15 +2,50 @@ package handlers
 
 import (
 	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
 	"net"
+
+	"github.com/abenz1267/elephant/v2/internal/providers"
+	"github.com/abenz1267/elephant/v2/pkg/pb/pb"
+	"google.golang.org/protobuf/proto"
 )
 
-func writeStatus(status int, conn net.Conn) (bool, error) {
+// writeStatus writes a status frame. The request id is only sent as payload
+// if the client provided one, so clients not using request ids keep
+// receiving empty status frames.
+func writeStatus(status int, format uint8, rid uint32, conn net.Conn) (bool, error) {
+	var b []byte
+
+	if rid != 0 {
+		res := &pb.StatusResponse{
+			Rid: rid,
+		}
+
+		var err error
+
+		switch format {
+		case 0:
+			b, err = proto.Marshal(res)
+		case 1:
+			b, err = json.Marshal(res)
+		}
+
+		if err != nil {
+			return false, err
+		}
+	}
+
 	var buffer bytes.Buffer
 	buffer.Write([]byte{byte(status)})
 
 	lengthBuf := make([]byte, 4)
+	binary.BigEndian.PutUint32(lengthBuf, uint32(len(b)))
 	buffer.Write(lengthBuf)
+	buffer.Write(b)
 
 	_, err := conn.Write(buffer.Bytes())
 	if err != nil {
@@ -19,3 +54,92 @@ func writeStatus(status int, conn net.Conn) (bool, error) {
 
 	return true, nil
 }
+
+// request types as registered in comm.
+const (
+	QueryRequestType = iota
+	ActivateRequestType
+	SubscribeRequestType
+	MenuRequestType
+	StateRequestType
+	HandshakeRequestType
+	AuthRequestType
+	ReloadRequestType
+	ProfileRequestType
+	StatsRequestType
+)
+
+// NotifyShutdown tells the client elephant is shutting down.
+func NotifyShutdown(conn net.Conn) {
+	if _, err := writeStatus(ServerShutdown, 0, 0, conn); err != nil {
+		slog.Debug("shutdown", "write", err)
+	}
+}
+
+// WriteError sends an Error frame for a failed request.
+func WriteError(format uint8, res *pb.ErrorResponse, conn net.Conn) {
+	slog.Debug("error", "type", res.Type, "code", res.Code.String(), "message", res.Message, "provider", res.Provider)
+
+	if err := writeMessage(Error, format, res, conn); err != nil {
+		slog.Debug("error", "write", err)
+	}
+}
+
+// providerErrorCode returns the error code for a failed provider call,
+// fallback unless the provider crashed or is disabled.
+func providerErrorCode(err error, fallback pb.ErrorResponse_Code) pb.ErrorResponse_Code {
+	var crash *providers.PanicError
+
+	switch {
+	case errors.As(err, &crash):
+		return pb.ErrorResponse_PROVIDER_CRASHED
+	case errors.Is(err, providers.ErrSuspended):
+		return pb.ErrorResponse_PROVIDER_DISABLED
+	}
+
+	return fallback
+}
+
+// Reject answers a request that couldn't be handled at all. The Error frame
+// is followed by the frame finishing the request, so clients waiting for it
+// don't hang.
+func Reject(format uint8, res *pb.ErrorResponse, conn net.Conn) {
+	WriteError(format, res, conn)
+
+	switch res.Type {
+	case QueryRequestType:
+		writeStatus(QueryDone, format, res.Rid, conn)
+	case ActivateRequestType:
+		writeStatus(ActivationFinished, format, res.Rid, conn)
+	case StateRequestType, ReloadRequestType, ProfileRequestType, StatsRequestType:
+		writeStatus(StatusDone, format, res.Rid, conn)
+	}
+}
+
+// unmarshal decodes a request, rejecting it on failure.
+func unmarshal(format uint8, reqType int, data []byte, req proto.Message, conn net.Conn) bool {
+	var err error
+
+	switch format {
+	case 0:
+		err = proto.Unmarshal(data, req)
+	case 1:
+		err = json.Unmarshal(data, req)
+	default:
+		err = fmt.Errorf("unknown format %d", format)
+	}
+
+	if err != nil {
+		slog.Error("handlers", "unmarshal", err, "type", reqType)
+
+		Reject(format, &pb.ErrorResponse{
+			Code:    pb.ErrorResponse_INVALID_REQUEST,
+			Message: err.Error(),
+			Type:    int32(reqType),
+		}, conn)
+
+		return false
+	}
+
+	return true
+}
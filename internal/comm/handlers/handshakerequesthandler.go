@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"log/slog"
+	"net"
+	"slices"
+	"strings"
+
+	"github.com/abenz1267/elephant/v2/internal/providers"
+	"github.com/abenz1267/elephant/v2/pkg/pb/pb"
+	"google.golang.org/protobuf/proto"
+)
+
+// ProtocolVersion is increased whenever message types or their fields change
+// in a way that breaks existing clients.
+const ProtocolVersion = 1
+
+// Version is the elephant version reported to clients.
+var Version string
+
+type HandshakeRequest struct {
+	Requests []*pb.HandshakeResponse_Request
+}
+
+func (a *HandshakeRequest) Handle(format uint8, cid uint32, conn net.Conn, data []byte) {
+	req := &pb.HandshakeRequest{}
+
+	if !unmarshal(format, HandshakeRequestType, data, req, conn) {
+		return
+	}
+
+	if req.Protocol != ProtocolVersion {
+		slog.Warn("handshake", "protocol mismatch", req.Protocol, "server", ProtocolVersion, "client", req.Version)
+	}
+
+	res := &pb.HandshakeResponse{
+		Version:   Version,
+		Protocol:  ProtocolVersion,
+		Requests:  a.Requests,
+		Formats:   []string{"protobuf", "json"},
+		Providers: []*pb.HandshakeResponse_Provider{},
+		Rid:       req.Rid,
+	}
+
+	for _, v := range providers.Providers {
+		res.Providers = append(res.Providers, &pb.HandshakeResponse_Provider{
+			Name:         *v.Name,
+			NamePretty:   v.PrettyName(),
+			Capabilities: v.Capabilities(),
+		})
+	}
+
+	slices.SortFunc(res.Providers, func(a, b *pb.HandshakeResponse_Provider) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	var b []byte
+	var err error
+
+	switch format {
+	case 0:
+		b, err = proto.Marshal(res)
+	case 1:
+		b, err = json.Marshal(res)
+	}
+
+	if err != nil {
+		slog.Error("handshakerequesthandler", "marshal", err)
+
+		Reject(format, &pb.ErrorResponse{
+			Code:    pb.ErrorResponse_INTERNAL,
+			Message: err.Error(),
+			Type:    HandshakeRequestType,
+			Rid:     req.Rid,
+		}, conn)
+
+		return
+	}
+
+	var buffer bytes.Buffer
+	buffer.Write([]byte{Handshake})
+
+	lengthBuf := make([]byte, 4)
+	binary.BigEndian.PutUint32(lengthBuf, uint32(len(b)))
+	buffer.Write(lengthBuf)
+	buffer.Write(b)
+
+	_, err = conn.Write(buffer.Bytes())
+	if err != nil {
+		slog.Error("handshakerequesthandler", "write", err)
+	}
+}
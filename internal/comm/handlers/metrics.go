@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/abenz1267/elephant/v2/internal/providers"
+	"github.com/abenz1267/elephant/v2/pkg/pb/pb"
+)
+
+// latencyBuckets are the upper bounds of the query latency histogram in
+// milliseconds.
+var latencyBuckets = []float64{1, 2.5, 5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000}
+
+type providerMetrics struct {
+	queries     uint64
+	results     uint64
+	activations uint64
+	// errors counts failed queries and activations, crashes included.
+	errors     uint64
+	timeouts   uint64
+	latencySum time.Duration
+	// latency holds the amount of queries per bucket, the last one being
+	// everything above the largest bound.
+	latency []uint64
+}
+
+var (
+	metrics    = make(map[string]*providerMetrics)
+	metricsMut sync.Mutex
+	started    = time.Now()
+	// Connections returns the amount of open connections. Set by comm.
+	Connections = func() int { return 0 }
+)
+
+// providerMetricsFor returns the metrics of the provider, metricsMut has to be
+// held.
+func providerMetricsFor(provider string) *providerMetrics {
+	m, ok := metrics[provider]
+	if !ok {
+		m = &providerMetrics{
+			latency: make([]uint64, len(latencyBuckets)+1),
+		}
+
+		metrics[provider] = m
+	}
+
+	return m
+}
+
+func recordQuery(provider string, took time.Duration, results int, timedout, failed bool) {
+	metricsMut.Lock()
+	defer metricsMut.Unlock()
+
+	m := providerMetricsFor(provider)
+	m.queries++
+	m.results += uint64(results)
+	m.latencySum += took
+
+	if timedout {
+		m.timeouts++
+	}
+
+	if failed {
+		m.errors++
+	}
+
+	ms := float64(took) / float64(time.Millisecond)
+
+	i, _ := slices.BinarySearch(latencyBuckets, ms)
+	m.latency[i]++
+}
+
+func recordActivation(provider string, failed bool) {
+	metricsMut.Lock()
+	defer metricsMut.Unlock()
+
+	m := providerMetricsFor(provider)
+	m.activations++
+
+	if failed {
+		m.errors++
+	}
+}
+
+// Stats returns a snapshot of the per-provider metrics. Latency buckets are
+// cumulative, the count of all queries being the implicit +Inf bucket.
+func Stats() []*pb.StatsResponse_Provider {
+	crashes := providers.Crashes()
+
+	metricsMut.Lock()
+	defer metricsMut.Unlock()
+
+	for k := range crashes {
+		providerMetricsFor(k)
+	}
+
+	res := []*pb.StatsResponse_Provider{}
+
+	for _, k := range slices.Sorted(maps.Keys(metrics)) {
+		m := metrics[k]
+
+		p := &pb.StatsResponse_Provider{
+			Name:        k,
+			Queries:     m.queries,
+			Results:     m.results,
+			Activations: m.activations,
+			Errors:      m.errors,
+			Timeouts:    m.timeouts,
+			LatencySum:  float64(m.latencySum) / float64(time.Millisecond),
+			Crashes:     uint64(crashes[k].Count),
+		}
+
+		if until := crashes[k].Until; time.Now().Before(until) {
+			p.DisabledUntil = until.Unix()
+		}
+
+		var count uint64
+
+		for i, le := range latencyBuckets {
+			count += m.latency[i]
+			p.Latency = append(p.Latency, &pb.StatsResponse_Bucket{Le: le, Count: count})
+		}
+
+		res = append(res, p)
+	}
+
+	return res
+}
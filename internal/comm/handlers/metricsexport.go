@@ -0,0 +1,226 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/abenz1267/elephant/v2/internal/providers"
+	"github.com/abenz1267/elephant/v2/pkg/common"
+)
+
+// ExportMetrics periodically writes the metrics to the configured
+// metrics_file. The config is re-read before every write, so reloading can
+// enable, disable or move the export. Blocks forever.
+func ExportMetrics() {
+	for {
+		cfg := common.GetElephantConfig()
+
+		if cfg.MetricsFile != "" {
+			if err := writeMetricsFile(cfg.MetricsFile); err != nil {
+				slog.Error("metrics", "write", err, "file", cfg.MetricsFile)
+			}
+		}
+
+		time.Sleep(cfg.MetricsIntervalDuration())
+	}
+}
+
+// writeMetricsFile replaces file atomically, so collectors never read a
+// partial file.
+func writeMetricsFile(file string) error {
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(file), ".elephant-metrics-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(PrometheusMetrics()); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Chmod(0o644); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), file)
+}
+
+// PrometheusMetrics returns the metrics in the Prometheus text format 0.0.4,
+// as read by node_exporter's textfile collector.
+func PrometheusMetrics() []byte {
+	var b bytes.Buffer
+
+	s := snapshot()
+
+	family(&b, "elephant_queries_total", "counter", "queries per provider")
+	for _, v := range s.Providers {
+		sample(&b, "elephant_queries_total", v.Name, "", float64(v.Queries))
+	}
+
+	family(&b, "elephant_query_duration_seconds", "histogram", "query latency per provider")
+	for _, v := range s.Providers {
+		for _, bucket := range v.Latency {
+			sample(&b, "elephant_query_duration_seconds_bucket", v.Name, strconv.FormatFloat(bucket.Le/1000, 'g', -1, 64), float64(bucket.Count))
+		}
+
+		sample(&b, "elephant_query_duration_seconds_bucket", v.Name, "+Inf", float64(v.Queries))
+		sample(&b, "elephant_query_duration_seconds_sum", v.Name, "", v.LatencySum/1000)
+		sample(&b, "elephant_query_duration_seconds_count", v.Name, "", float64(v.Queries))
+	}
+
+	family(&b, "elephant_query_results_total", "counter", "results returned per provider")
+	for _, v := range s.Providers {
+		sample(&b, "elephant_query_results_total", v.Name, "", float64(v.Results))
+	}
+
+	family(&b, "elephant_query_timeouts_total", "counter", "queries that missed the query timeout per provider")
+	for _, v := range s.Providers {
+		sample(&b, "elephant_query_timeouts_total", v.Name, "", float64(v.Timeouts))
+	}
+
+	family(&b, "elephant_activations_total", "counter", "activations per provider")
+	for _, v := range s.Providers {
+		sample(&b, "elephant_activations_total", v.Name, "", float64(v.Activations))
+	}
+
+	family(&b, "elephant_errors_total", "counter", "failed queries and activations per provider, crashes included")
+	for _, v := range s.Providers {
+		sample(&b, "elephant_errors_total", v.Name, "", float64(v.Errors))
+	}
+
+	family(&b, "elephant_provider_crashes_total", "counter", "recovered panics per provider")
+	for _, v := range s.Providers {
+		sample(&b, "elephant_provider_crashes_total", v.Name, "", float64(v.Crashes))
+	}
+
+	family(&b, "elephant_provider_disabled", "gauge", "1 while a provider is disabled after repeated crashes")
+	for _, v := range s.Providers {
+		disabled := 0.0
+
+		if v.DisabledUntil != 0 {
+			disabled = 1
+		}
+
+		sample(&b, "elephant_provider_disabled", v.Name, "", disabled)
+	}
+
+	failures := common.GitSyncFailures()
+
+	family(&b, "elephant_git_sync_failures_total", "counter", "failed git setups and pushes per provider")
+	for _, k := range slices.Sorted(maps.Keys(failures)) {
+		sample(&b, "elephant_git_sync_failures_total", k, "", float64(failures[k]))
+	}
+
+	writeProviderMetrics(&b)
+
+	family(&b, "elephant_connections", "gauge", "open connections")
+	sample(&b, "elephant_connections", "", "", float64(s.Connections))
+
+	family(&b, "elephant_subscriptions", "gauge", "active subscriptions")
+	sample(&b, "elephant_subscriptions", "", "", float64(s.Subscriptions))
+
+	family(&b, "elephant_heap_alloc_bytes", "gauge", "allocated heap objects")
+	sample(&b, "elephant_heap_alloc_bytes", "", "", float64(s.HeapAlloc))
+
+	family(&b, "elephant_heap_sys_bytes", "gauge", "heap memory obtained from the OS")
+	sample(&b, "elephant_heap_sys_bytes", "", "", float64(s.HeapSys))
+
+	family(&b, "elephant_sys_bytes", "gauge", "total memory obtained from the OS")
+	sample(&b, "elephant_sys_bytes", "", "", float64(s.Sys))
+
+	family(&b, "elephant_goroutines", "gauge", "running goroutines")
+	sample(&b, "elephant_goroutines", "", "", float64(s.Goroutines))
+
+	family(&b, "elephant_uptime_seconds", "gauge", "time since start")
+	sample(&b, "elephant_uptime_seconds", "", "", float64(s.Uptime))
+
+	return b.Bytes()
+}
+
+// writeProviderMetrics adds the values reported by providers implementing
+// Metrics, grouped by name.
+func writeProviderMetrics(b *bytes.Buffer) {
+	type value struct {
+		provider string
+		value    float64
+	}
+
+	help := make(map[string]string)
+	values := make(map[string][]value)
+
+	for _, k := range slices.Sorted(maps.Keys(providers.Providers)) {
+		p, ok := providers.Get(k)
+		if !ok || p.Metrics == nil {
+			continue
+		}
+
+		var reported []common.Metric
+
+		if err := p.Recover(func() { reported = p.Metrics() }); err != nil {
+			continue
+		}
+
+		for _, m := range reported {
+			name := fmt.Sprintf("elephant_%s", m.Name)
+
+			help[name] = m.Help
+			values[name] = append(values[name], value{provider: k, value: m.Value})
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(values)) {
+		family(b, name, "gauge", help[name])
+
+		for _, v := range values[name] {
+			sample(b, name, v.provider, "", v.value)
+		}
+	}
+}
+
+func family(b *bytes.Buffer, name, kind, help string) {
+	fmt.Fprintf(b, "# HELP %s %s\n", name, escapeHelp.Replace(help))
+	fmt.Fprintf(b, "# TYPE %s %s\n", name, kind)
+}
+
+var (
+	escapeHelp  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	escapeLabel = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+// sample writes a single sample, labeled with the provider and the histogram
+// bucket bound le, if set.
+func sample(b *bytes.Buffer, name, provider, le string, value float64) {
+	labels := []string{}
+
+	if provider != "" {
+		labels = append(labels, fmt.Sprintf(`provider="%s"`, escapeLabel.Replace(provider)))
+	}
+
+	if le != "" {
+		labels = append(labels, fmt.Sprintf(`le="%s"`, le))
+	}
+
+	if len(labels) > 0 {
+		name = fmt.Sprintf("%s{%s}", name, strings.Join(labels, ","))
+	}
+
+	fmt.Fprintf(b, "%s %s\n", name, strconv.FormatFloat(value, 'g', -1, 64))
+}
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"errors"
+	"net"
+
+	"github.com/abenz1267/elephant/v2/pkg/common"
+	"github.com/abenz1267/elephant/v2/pkg/pb/pb"
+)
+
+type ProfileRequest struct{}
+
+func (a *ProfileRequest) Handle(format uint8, cid uint32, conn net.Conn, data []byte) {
+	req := &pb.ProfileRequest{}
+
+	if !unmarshal(format, ProfileRequestType, data, req, conn) {
+		return
+	}
+
+	if err := SwitchProfile(req.Profile); err != nil {
+		code := pb.ErrorResponse_INVALID_CONFIG
+
+		if errors.Is(err, common.ErrUnknownProfile) {
+			code = pb.ErrorResponse_INVALID_REQUEST
+		}
+
+		Reject(format, &pb.ErrorResponse{
+			Code:    code,
+			Message: err.Error(),
+			Type:    ProfileRequestType,
+			Rid:     req.Rid,
+		}, conn)
+
+		return
+	}
+
+	writeStatus(StatusDone, format, req.Rid, conn)
+}
+
+// SwitchProfile activates the profile and reloads all configs. If no config
+// defines the profile or a config is invalid with it, the previous profile
+// stays active.
+func SwitchProfile(name string) error {
+	if err := common.CheckProfile(name); err != nil {
+		return err
+	}
+
+	reloadMut.Lock()
+	defer reloadMut.Unlock()
+
+	prev := common.Profile()
+	common.SetProfile(name)
+
+	if err := reload(); err != nil {
+		common.SetProfile(prev)
+		return err
+	}
+
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/binary"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net"
@@ -15,6 +16,7 @@ import (
 	"time"
 
 	"github.com/abenz1267/elephant/v2/internal/providers"
+	"github.com/abenz1267/elephant/v2/pkg/common"
 	"github.com/abenz1267/elephant/v2/pkg/common/history"
 	"github.com/abenz1267/elephant/v2/pkg/pb/pb"
 	"google.golang.org/protobuf/proto"
@@ -28,52 +30,113 @@ const (
 	QueryAsyncItem     = 1
 	ActivationFinished = 2
 	ProviderState      = 3
+	Handshake          = 4
+	// streaming only
+	QueryProviderDone = 5
+	QueryOrder        = 6
+	// followed by the request's regular final frame, if it has one
+	Error          = 7
+	ServerShutdown = 8
+	ServerStats    = 9
 )
 
 var (
-	queries                          = make(map[uint32]context.CancelFunc)
-	queryMutex                       sync.Mutex
-	MaxGlobalItemsToDisplayWebsearch = 0
-	WebsearchAlwaysShow              = false
-	WebsearchPrefixes                = make(map[string]string)
-	qid                              atomic.Uint32
+	queries      = make(map[uint32]context.CancelFunc)
+	queryMutex   sync.Mutex
+	qid          atomic.Uint32
+	results      = make(map[uint32]cachedResults)
+	resultsMutex sync.Mutex
+	// resultsTTL is how long sorted results are kept for paging via offset.
+	resultsTTL = 10 * time.Second
 )
 
+// websearchSettings controls how websearch results are shown when querying
+// multiple providers. It's set by the websearch provider.
+type websearchSettings struct {
+	maxGlobalItems int
+	alwaysShow     bool
+	prefixes       map[string]string
+}
+
+var (
+	websearchCfg = websearchSettings{prefixes: map[string]string{}}
+	websearchMut sync.RWMutex
+)
+
+// SetWebsearch replaces the websearch settings: the amount of default
+// engines, whether they are always shown and the engine names by prefix.
+func SetWebsearch(maxGlobalItems int, alwaysShow bool, prefixes map[string]string) {
+	websearchMut.Lock()
+	websearchCfg = websearchSettings{
+		maxGlobalItems: maxGlobalItems,
+		alwaysShow:     alwaysShow,
+		prefixes:       prefixes,
+	}
+	websearchMut.Unlock()
+}
+
+func getWebsearch() websearchSettings {
+	websearchMut.RLock()
+	defer websearchMut.RUnlock()
+
+	return websearchCfg
+}
+
+// cachedResults holds the last sorted result set of a connection, so
+// requesting further pages doesn't query the providers again.
+type cachedResults struct {
+	key     string
+	entries []*pb.QueryResponse_Item
+	expires time.Time
+}
+
+func resultsKey(req *pb.QueryRequest) string {
+	return fmt.Sprintf("%s\x00%s\x00%t", strings.Join(req.Providers, ","), req.Query, req.Exactsearch)
+}
+
+// ClearConnection drops all query state kept for the given connection.
+func ClearConnection(cid uint32) {
+	queryMutex.Lock()
+	if cancel, ok := queries[cid]; ok && cancel != nil {
+		cancel()
+	}
+	delete(queries, cid)
+	queryMutex.Unlock()
+
+	resultsMutex.Lock()
+	delete(results, cid)
+	resultsMutex.Unlock()
+}
+
 type QueryRequest struct{}
 
+// requestConn is the connection handed to providers. It carries the ids of
+// the request it was handed out for, so async items can be matched to it.
+type requestConn struct {
+	net.Conn
+	rid uint32
+	qid uint32
+}
+
+func withRequest(conn net.Conn, rid, qid uint32) net.Conn {
+	return &requestConn{Conn: conn, rid: rid, qid: qid}
+}
+
+// UpdateItem sends an item after the request it belongs to, f.e. one that
+// was computed in the background or changed by an activation.
 func UpdateItem(format uint8, query string, conn net.Conn, item *pb.QueryResponse_Item) {
 	req := pb.QueryResponse{
 		Query: query,
 		Item:  item,
 	}
 
-	var b []byte
-	var err error
-
-	switch format {
-	case 0:
-		b, err = proto.Marshal(&req)
-	case 1:
-		b, err = json.Marshal(&req)
-	}
-
-	if err != nil {
-		slog.Debug("async update", "marshal", err)
-		return
+	if c, ok := conn.(*requestConn); ok {
+		req.Rid = c.rid
+		req.Qid = int32(c.qid)
 	}
 
-	var buffer bytes.Buffer
-	buffer.Write([]byte{QueryAsyncItem})
-
-	lengthBuf := make([]byte, 4)
-	binary.BigEndian.PutUint32(lengthBuf, uint32(len(b)))
-	buffer.Write(lengthBuf)
-	buffer.Write(b)
-
-	_, err = conn.Write(buffer.Bytes())
-	if err != nil {
+	if err := writeMessage(QueryAsyncItem, format, &req, conn); err != nil {
 		slog.Debug("async update", "write", err)
-		return
 	}
 }
 
@@ -85,25 +148,15 @@ func (h *QueryRequest) Handle(format uint8, cid uint32, conn net.Conn, data []by
 
 	req := &pb.QueryRequest{}
 
-	switch format {
-	case 0:
-		if err := proto.Unmarshal(data, req); err != nil {
-			slog.Error("queryhandler", "protobuf", err)
-
-			return
-		}
-	case 1:
-		if err := json.Unmarshal(data, req); err != nil {
-			slog.Error("queryhandler", "protobuf", err)
-
-			return
-		}
+	if !unmarshal(format, QueryRequestType, data, req, conn) {
+		return
 	}
 
 	wsprefix := ""
+	ws := getWebsearch()
 
 	if slices.Contains(req.Providers, "websearch") {
-		for k, v := range WebsearchPrefixes {
+		for k, v := range ws.prefixes {
 			if strings.HasPrefix(req.Query, k) {
 				wsprefix = v
 			}
@@ -134,32 +187,48 @@ func (h *QueryRequest) Handle(format uint8, cid uint32, conn net.Conn, data []by
 		}
 	}
 
+	if req.Stream {
+		streamQuery(ctx, format, qqid, req, wsprefix, conn, isCncld)
+		slog.Info("providers", "p", strings.Join(req.Providers, ","), "stream", true, "time", time.Since(start))
+		return
+	}
+
+	key := resultsKey(req)
+
+	if req.Offset > 0 {
+		resultsMutex.Lock()
+		cached, ok := results[cid]
+		resultsMutex.Unlock()
+
+		if ok && cached.key == key && time.Now().Before(cached.expires) {
+			writePage(format, qqid, req, cached.entries, nil, conn, isCncld)
+			slog.Info("providers", "p", strings.Join(req.Providers, ","), "offset", req.Offset, "cached", true, "time", time.Since(start))
+			return
+		}
+	}
+
 	var mut sync.Mutex
 
 	var wg sync.WaitGroup
 	wg.Add(len(req.Providers))
 
 	entries := []*pb.QueryResponse_Item{}
+	timedout := []string{}
 
 	for _, v := range req.Providers {
-		query := req.Query
+		go func(v string) {
+			defer wg.Done()
 
-		if strings.HasPrefix(v, "menus:") {
-			split := strings.Split(v, ":")
-			v = split[0]
-			query = fmt.Sprintf("%s:%s", split[1], query)
-		}
+			res, late := queryProvider(ctx, format, qqid, v, req, conn)
 
-		go func(text string, wg *sync.WaitGroup) {
-			defer wg.Done()
-			if p, ok := providers.Providers[v]; ok {
-				res := p.Query(conn, text, len(req.Providers) == 1, req.Exactsearch, format)
+			mut.Lock()
+			entries = append(entries, res...)
 
-				mut.Lock()
-				entries = append(entries, res...)
-				mut.Unlock()
+			if late {
+				timedout = append(timedout, v)
 			}
-		}(query, &wg)
+			mut.Unlock()
+		}(v)
 	}
 
 	wg.Wait()
@@ -170,71 +239,323 @@ func (h *QueryRequest) Handle(format uint8, cid uint32, conn net.Conn, data []by
 
 	slices.SortFunc(entries, sortEntries)
 
-	if len(entries) == 0 {
-		writeStatus(QueryNoResults, conn)
-		writeStatus(QueryDone, conn)
-		slog.Info("providers", "p", strings.Join(req.Providers, ","), "results", len(entries), "time", time.Since(start))
-		return
+	hideWebsearch := (len(req.Providers) > 1 && min(len(entries), int(req.Maxresults)) > ws.maxGlobalItems) && !ws.alwaysShow
+
+	if hideWebsearch {
+		entries = slices.DeleteFunc(entries, func(v *pb.QueryResponse_Item) bool {
+			return v.Provider == "websearch" && v.Text != wsprefix
+		})
+	}
+
+	addHistoryActions(entries)
+
+	resultsMutex.Lock()
+	results[cid] = cachedResults{
+		key:     key,
+		entries: entries,
+		expires: time.Now().Add(resultsTTL),
 	}
+	resultsMutex.Unlock()
+
+	writePage(format, qqid, req, entries, timedout, conn, isCncld)
+
+	slog.Info("providers", "p", strings.Join(req.Providers, ","), "results", len(entries), "time", time.Since(start))
+}
 
-	if len(entries) > int(req.Maxresults) {
-		entries = entries[:req.Maxresults]
+// writePage sends maxresults entries starting at the requested offset. Every
+// item carries the total amount of results.
+func writePage(format uint8, qqid uint32, req *pb.QueryRequest, entries []*pb.QueryResponse_Item, timedout []string, conn net.Conn, isCncld func() bool) {
+	total := int32(len(entries))
+	offset := min(max(int(req.Offset), 0), len(entries))
+	page := entries[offset:min(offset+max(int(req.Maxresults), 0), len(entries))]
+
+	if len(page) == 0 {
+		writeStatus(QueryNoResults, format, req.Rid, conn)
+		writeTimedout(format, qqid, req, timedout, nil, conn)
+		writeStatus(QueryDone, format, req.Rid, conn)
+		return
 	}
 
-	hideWebsearch := (len(req.Providers) > 1 && len(entries) > MaxGlobalItemsToDisplayWebsearch) && !WebsearchAlwaysShow
+	sent := make(map[string]int)
 
-	for _, v := range entries {
+	for _, v := range page {
 		if isCncld() {
 			return
 		}
 
-		if v.Provider == "websearch" && hideWebsearch && v.Text != wsprefix {
-			continue
+		if err := writeItem(format, qqid, total, req, v, conn); err != nil {
+			slog.Error("queryrequesthandler", "write", err, "item", v.Text)
+			return
 		}
 
+		sent[v.Provider]++
+	}
+
+	writeTimedout(format, qqid, req, timedout, sent, conn)
+	writeStatus(QueryDone, format, req.Rid, conn)
+}
+
+// queryProvider queries a single requested provider. Menus are requested as
+// "menus:<menu>" and get the menu prepended to the query. The returned bool
+// reports whether the provider missed its configured query timeout, in which
+// case the results might be partial.
+func queryProvider(ctx context.Context, format uint8, qqid uint32, provider string, req *pb.QueryRequest, conn net.Conn) ([]*pb.QueryResponse_Item, bool) {
+	query := req.Query
+
+	if strings.HasPrefix(provider, "menus:") {
+		split := strings.Split(provider, ":")
+		provider = split[0]
+		query = fmt.Sprintf("%s:%s", split[1], query)
+	}
+
+	p, ok := providers.Get(provider)
+	if !ok {
+		WriteError(format, &pb.ErrorResponse{
+			Code:     pb.ErrorResponse_UNKNOWN_PROVIDER,
+			Message:  fmt.Sprintf("unknown provider: %s", provider),
+			Type:     QueryRequestType,
+			Rid:      req.Rid,
+			Provider: provider,
+		}, conn)
+
+		return nil, false
+	}
+
+	if timeout := common.GetElephantConfig().ProviderQueryTimeout(provider); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+
+	res, err := p.RunQuery(ctx, withRequest(conn, req.Rid, qqid), query, len(req.Providers) == 1, req.Exactsearch, format)
+
+	// superseded queries would skew the latency.
+	if errors.Is(err, context.Canceled) {
+		return res, false
+	}
+
+	timedout := errors.Is(err, context.DeadlineExceeded)
+
+	if err != nil && !timedout {
+		recordQuery(provider, time.Since(start), 0, false, true)
+
+		WriteError(format, &pb.ErrorResponse{
+			Code:     providerErrorCode(err, pb.ErrorResponse_INTERNAL),
+			Message:  err.Error(),
+			Type:     QueryRequestType,
+			Rid:      req.Rid,
+			Provider: provider,
+		}, conn)
+
+		return nil, false
+	}
+
+	recordQuery(provider, time.Since(start), len(res), timedout, false)
+
+	if timedout {
+		slog.Warn("queryrequesthandler", "timeout", provider, "query", query, "results", len(res))
+	}
+
+	return res, timedout
+}
+
+// writeTimedout marks providers that missed their query timeout with a
+// QueryProviderDone frame. sent holds the amount of results sent per provider.
+func writeTimedout(format uint8, qqid uint32, req *pb.QueryRequest, timedout []string, sent map[string]int, conn net.Conn) {
+	for _, v := range timedout {
+		err := writeMessage(QueryProviderDone, format, &pb.QueryProviderDone{
+			Provider: v,
+			Results:  int32(sent[strings.Split(v, ":")[0]]),
+			Qid:      int32(qqid),
+			Rid:      req.Rid,
+			Timedout: true,
+		}, conn)
+		if err != nil {
+			slog.Error("queryrequesthandler", "write", err, "provider", v)
+			return
+		}
+	}
+}
+
+func addHistoryActions(entries []*pb.QueryResponse_Item) {
+	for _, v := range entries {
 		if slices.Contains(v.State, history.StateHistory) {
 			v.Actions = append(v.Actions, history.ActionDelete)
 		}
+	}
+}
+
+// writeItem sends a single result. total is the amount of results available
+// for paging, 0 if unknown.
+func writeItem(format uint8, qqid uint32, total int32, req *pb.QueryRequest, item *pb.QueryResponse_Item, conn net.Conn) error {
+	return writeMessage(QueryItem, format, &pb.QueryResponse{
+		Qid:   int32(qqid),
+		Rid:   req.Rid,
+		Query: req.Query,
+		Item:  item,
+		Total: total,
+	}, conn)
+}
+
+func writeMessage(kind int, format uint8, msg proto.Message, conn net.Conn) error {
+	var b []byte
+	var err error
+
+	switch format {
+	case 0:
+		b, err = proto.Marshal(msg)
+	case 1:
+		b, err = json.Marshal(msg)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	var buffer bytes.Buffer
+	buffer.Write([]byte{byte(kind)})
+
+	lengthBuf := make([]byte, 4)
+	binary.BigEndian.PutUint32(lengthBuf, uint32(len(b)))
+	buffer.Write(lengthBuf)
+	buffer.Write(b)
+
+	_, err = conn.Write(buffer.Bytes())
+
+	return err
+}
+
+type providerBatch struct {
+	provider string
+	entries  []*pb.QueryResponse_Item
+	timedout bool
+}
+
+// streamQuery sends every provider's results as soon as the provider is done,
+// followed by a QueryProviderDone frame. Websearch only acts as a fallback
+// when querying multiple providers, so its results are held back until all
+// other providers are done. After all providers are done a QueryOrder frame
+// with the merged order is sent.
+func streamQuery(ctx context.Context, format uint8, qqid uint32, req *pb.QueryRequest, wsprefix string, conn net.Conn, isCncld func() bool) {
+	batches := make(chan providerBatch, len(req.Providers))
+
+	for _, v := range req.Providers {
+		go func(v string) {
+			res, timedout := queryProvider(ctx, format, qqid, v, req, conn)
 
-		req := pb.QueryResponse{
-			Qid:   int32(qqid),
-			Query: req.Query,
-			Item:  v,
+			batches <- providerBatch{
+				provider: v,
+				entries:  res,
+				timedout: timedout,
+			}
+		}(v)
+	}
+
+	merged := []*pb.QueryResponse_Item{}
+	var websearch *providerBatch
+
+	sendBatch := func(b providerBatch, hideWebsearch bool) bool {
+		slices.SortFunc(b.entries, sortEntries)
+
+		if len(b.entries) > int(req.Maxresults) {
+			b.entries = b.entries[:req.Maxresults]
 		}
 
-		var b []byte
-		var err error
+		addHistoryActions(b.entries)
+
+		sent := 0
 
-		switch format {
-		case 0:
-			b, err = proto.Marshal(&req)
-		case 1:
-			b, err = json.Marshal(&req)
+		for _, v := range b.entries {
+			if isCncld() {
+				return false
+			}
+
+			if v.Provider == "websearch" && hideWebsearch && v.Text != wsprefix {
+				continue
+			}
+
+			if err := writeItem(format, qqid, 0, req, v, conn); err != nil {
+				slog.Error("queryrequesthandler", "write", err, "item", v.Text)
+				return false
+			}
+
+			merged = append(merged, v)
+			sent++
 		}
 
+		err := writeMessage(QueryProviderDone, format, &pb.QueryProviderDone{
+			Provider: b.provider,
+			Results:  int32(sent),
+			Qid:      int32(qqid),
+			Rid:      req.Rid,
+			Timedout: b.timedout,
+		}, conn)
 		if err != nil {
-			slog.Error("queryrequesthandler", "marshal", err)
+			slog.Error("queryrequesthandler", "write", err, "provider", b.provider)
+			return false
+		}
+
+		return true
+	}
+
+	for range req.Providers {
+		b := <-batches
+
+		if isCncld() {
+			return
+		}
+
+		if b.provider == "websearch" && len(req.Providers) > 1 {
+			websearch = &b
 			continue
 		}
 
-		var buffer bytes.Buffer
-		buffer.Write([]byte{QueryItem})
+		if !sendBatch(b, false) {
+			return
+		}
+	}
 
-		lengthBuf := make([]byte, 4)
-		binary.BigEndian.PutUint32(lengthBuf, uint32(len(b)))
-		buffer.Write(lengthBuf)
-		buffer.Write(b)
+	if websearch != nil {
+		ws := getWebsearch()
+		hideWebsearch := len(merged)+len(websearch.entries) > ws.maxGlobalItems && !ws.alwaysShow
 
-		_, err = conn.Write(buffer.Bytes())
-		if err != nil {
-			slog.Error("queryrequesthandler", "write", err, "item", v.Text)
+		if !sendBatch(*websearch, hideWebsearch) {
 			return
 		}
 	}
 
-	writeStatus(QueryDone, conn)
+	if len(merged) == 0 {
+		writeStatus(QueryNoResults, format, req.Rid, conn)
+		writeStatus(QueryDone, format, req.Rid, conn)
+		return
+	}
 
-	slog.Info("providers", "p", strings.Join(req.Providers, ","), "results", len(entries), "time", time.Since(start))
+	slices.SortFunc(merged, sortEntries)
+
+	if len(merged) > int(req.Maxresults) {
+		merged = merged[:req.Maxresults]
+	}
+
+	order := &pb.QueryOrder{
+		Qid: int32(qqid),
+		Rid: req.Rid,
+	}
+
+	for _, v := range merged {
+		order.Entries = append(order.Entries, &pb.QueryOrder_Entry{
+			Provider:   v.Provider,
+			Identifier: v.Identifier,
+		})
+	}
+
+	if err := writeMessage(QueryOrder, format, order, conn); err != nil {
+		slog.Error("queryrequesthandler", "write", err)
+		return
+	}
+
+	writeStatus(QueryDone, format, req.Rid, conn)
 }
 
 func sortEntries(a *pb.QueryResponse_Item, b *pb.QueryResponse_Item) int {
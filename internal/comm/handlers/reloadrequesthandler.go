@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"log/slog"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/abenz1267/elephant/v2/internal/providers"
+	"github.com/abenz1267/elephant/v2/pkg/common"
+	"github.com/abenz1267/elephant/v2/pkg/pb/pb"
+)
+
+var reloadMut sync.Mutex
+
+type ReloadRequest struct{}
+
+func (a *ReloadRequest) Handle(format uint8, cid uint32, conn net.Conn, data []byte) {
+	req := &pb.ReloadRequest{}
+
+	if !unmarshal(format, ReloadRequestType, data, req, conn) {
+		return
+	}
+
+	if err := Reload(); err != nil {
+		Reject(format, &pb.ErrorResponse{
+			Code:    pb.ErrorResponse_INVALID_CONFIG,
+			Message: err.Error(),
+			Type:    ReloadRequestType,
+			Rid:     req.Rid,
+		}, conn)
+
+		return
+	}
+
+	writeStatus(StatusDone, format, req.Rid, conn)
+}
+
+// Reload re-reads elephant.toml, every provider config and the menus. All
+// configs are validated first, if one is invalid nothing gets applied.
+// Subscribers of every provider are notified afterwards.
+func Reload() error {
+	reloadMut.Lock()
+	defer reloadMut.Unlock()
+
+	return reload()
+}
+
+func reload() error {
+	start := time.Now()
+
+	if err := common.ValidateConfigs(); err != nil {
+		slog.Error("reload", "config", err)
+		return err
+	}
+
+	common.LoadGlobalConfig()
+
+	if err := common.SetupLogging(); err != nil {
+		slog.Error("reload", "logging", err)
+	}
+
+	for k, v := range providers.Providers {
+		providers.Resume(k)
+		v.RunLoadConfig()
+	}
+
+	common.RecoverMenu(common.LoadMenus)
+
+	for k := range providers.Providers {
+		ProviderUpdated <- k
+	}
+
+	slog.Info("reload", "time", time.Since(start))
+
+	return nil
+}
+
+// ReloadProvider applies the config of a single provider, "elephant" being
+// the global config and "menus" the menu definitions. The config has to be
+// validated beforehand.
+func ReloadProvider(provider string) {
+	reloadMut.Lock()
+	defer reloadMut.Unlock()
+
+	switch provider {
+	case "elephant":
+		common.LoadGlobalConfig()
+
+		if err := common.SetupLogging(); err != nil {
+			slog.Error("reload", "logging", err)
+		}
+
+		return
+	case "menus":
+		common.RecoverMenu(common.LoadMenus)
+	default:
+		p, ok := providers.Providers[provider]
+		if !ok {
+			return
+		}
+
+		providers.Resume(provider)
+		p.RunLoadConfig()
+	}
+
+	ProviderUpdated <- provider
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/binary"
 	"encoding/json"
+	"fmt"
 	"log/slog"
 	"net"
 	"strings"
@@ -18,24 +19,21 @@ type StateRequest struct{}
 func (a *StateRequest) Handle(format uint8, cid uint32, conn net.Conn, data []byte) {
 	req := &pb.ProviderStateRequest{}
 
-	switch format {
-	case 0:
-		if err := proto.Unmarshal(data, req); err != nil {
-			slog.Error("staterequesthandler", "protobuf", err)
-
-			return
-		}
-	case 1:
-		if err := json.Unmarshal(data, req); err != nil {
-			slog.Error("staterequesthandler", "protobuf", err)
-
-			return
-		}
+	if !unmarshal(format, StateRequestType, data, req, conn) {
+		return
 	}
 
 	p := req.Provider
 
 	if p == "menus" || p == "menus:" {
+		Reject(format, &pb.ErrorResponse{
+			Code:     pb.ErrorResponse_INVALID_REQUEST,
+			Message:  "missing menu, use menus:<menu>",
+			Type:     StateRequestType,
+			Rid:      req.Rid,
+			Provider: req.Provider,
+		}, conn)
+
 		return
 	}
 
@@ -43,18 +41,39 @@ func (a *StateRequest) Handle(format uint8, cid uint32, conn net.Conn, data []by
 		p = "menus"
 	}
 
-	provider, ok := providers.Providers[p]
+	provider, ok := providers.Get(p)
 
 	if !ok {
 		slog.Error("staterequesthandler", "missing provider", p)
+
+		Reject(format, &pb.ErrorResponse{
+			Code:     pb.ErrorResponse_UNKNOWN_PROVIDER,
+			Message:  fmt.Sprintf("unknown provider: %s", p),
+			Type:     StateRequestType,
+			Rid:      req.Rid,
+			Provider: req.Provider,
+		}, conn)
+
+		return
+	}
+
+	res, err := provider.RunState(req.Provider)
+	if err != nil {
+		Reject(format, &pb.ErrorResponse{
+			Code:     providerErrorCode(err, pb.ErrorResponse_INTERNAL),
+			Message:  err.Error(),
+			Type:     StateRequestType,
+			Rid:      req.Rid,
+			Provider: req.Provider,
+		}, conn)
+
 		return
 	}
 
-	res := provider.State(req.Provider)
 	res.Provider = req.Provider
+	res.Rid = req.Rid
 
 	var b []byte
-	var err error
 
 	switch format {
 	case 0:
@@ -65,11 +84,20 @@ func (a *StateRequest) Handle(format uint8, cid uint32, conn net.Conn, data []by
 
 	if err != nil {
 		slog.Error("staterequesthandler", "marshal", err)
+
+		Reject(format, &pb.ErrorResponse{
+			Code:     pb.ErrorResponse_INTERNAL,
+			Message:  err.Error(),
+			Type:     StateRequestType,
+			Rid:      req.Rid,
+			Provider: req.Provider,
+		}, conn)
+
 		return
 	}
 
 	var buffer bytes.Buffer
-	buffer.Write([]byte{3})
+	buffer.Write([]byte{ProviderState})
 
 	lengthBuf := make([]byte, 4)
 	binary.BigEndian.PutUint32(lengthBuf, uint32(len(b)))
@@ -82,5 +110,5 @@ func (a *StateRequest) Handle(format uint8, cid uint32, conn net.Conn, data []by
 		return
 	}
 
-	writeStatus(StatusDone, conn)
+	writeStatus(StatusDone, format, req.Rid, conn)
 }
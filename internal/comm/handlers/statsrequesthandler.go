@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"log/slog"
+	"net"
+	"runtime"
+	"time"
+
+	"github.com/abenz1267/elephant/v2/pkg/pb/pb"
+)
+
+type StatsRequest struct{}
+
+func (a *StatsRequest) Handle(format uint8, cid uint32, conn net.Conn, data []byte) {
+	req := &pb.StatsRequest{}
+
+	if !unmarshal(format, StatsRequestType, data, req, conn) {
+		return
+	}
+
+	res := snapshot()
+	res.Rid = req.Rid
+
+	if err := writeMessage(ServerStats, format, res, conn); err != nil {
+		slog.Error("statsrequesthandler", "write", err)
+		return
+	}
+
+	writeStatus(StatusDone, format, req.Rid, conn)
+}
+
+// snapshot collects the metrics of all providers and the process.
+func snapshot() *pb.StatsResponse {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	mut.Lock()
+	subscriptions := len(subs)
+	mut.Unlock()
+
+	return &pb.StatsResponse{
+		Providers:     Stats(),
+		Connections:   uint32(Connections()),
+		Subscriptions: uint32(subscriptions),
+		HeapAlloc:     mem.HeapAlloc,
+		HeapSys:       mem.HeapSys,
+		Sys:           mem.Sys,
+		Goroutines:    uint32(runtime.NumGoroutine()),
+		Uptime:        int64(time.Since(started).Seconds()),
+	}
+}
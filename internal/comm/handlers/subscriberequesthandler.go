@@ -3,8 +3,10 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/binary"
 	"encoding/json"
+	"fmt"
 	"log/slog"
 	"net"
 	"slices"
@@ -23,22 +25,25 @@ type SubscribeRequest struct{}
 func (a *SubscribeRequest) Handle(format uint8, cid uint32, conn net.Conn, data []byte) {
 	req := &pb.SubscribeRequest{}
 
-	switch format {
-	case 0:
-		if err := proto.Unmarshal(data, req); err != nil {
-			slog.Error("activationrequesthandler", "protobuf", err)
+	if !unmarshal(format, SubscribeRequestType, data, req, conn) {
+		return
+	}
 
-			return
-		}
-	case 1:
-		if err := json.Unmarshal(data, req); err != nil {
-			slog.Error("activationrequesthandler", "protobuf", err)
+	provider, _, _ := strings.Cut(req.Provider, ":")
 
-			return
-		}
+	if _, ok := providers.Get(provider); !ok {
+		Reject(format, &pb.ErrorResponse{
+			Code:     pb.ErrorResponse_UNKNOWN_PROVIDER,
+			Message:  fmt.Sprintf("unknown provider: %s", provider),
+			Type:     SubscribeRequestType,
+			Rid:      req.Rid,
+			Provider: req.Provider,
+		}, conn)
+
+		return
 	}
 
-	subscribe(format, int(req.Interval), req.Provider, req.Query, conn)
+	subscribe(format, req.Rid, int(req.Interval), req.Provider, req.Query, conn)
 }
 
 var (
@@ -55,6 +60,7 @@ const (
 
 type sub struct {
 	format   uint8
+	rid      uint32
 	sid      uint32
 	interval int
 	provider string
@@ -68,6 +74,10 @@ func init() {
 	subs = make(map[uint32]*sub)
 	ProviderUpdated = make(chan string)
 
+	providers.Updated = func(provider string) {
+		ProviderUpdated <- provider
+	}
+
 	// go checkHealth()
 
 	// handle general realtime subs
@@ -83,28 +93,31 @@ func init() {
 				p = "bluetooth"
 			}
 
-			toDelete := []uint32{}
+			mut.Lock()
+			targets := []*sub{}
 
-			for k, v := range subs {
+			for _, v := range subs {
 				if v.provider == p && v.interval == 0 && v.query == "" {
-					if ok := updated(v.format, v.conn, value); !ok {
-						toDelete = append(toDelete, k)
-					}
+					targets = append(targets, v)
 				}
 			}
+			mut.Unlock()
 
-			for _, v := range toDelete {
-				delete(subs, v)
+			for _, v := range targets {
+				if ok := updated(v.format, v.rid, v.conn, value); !ok {
+					unsubscribe(v.sid)
+				}
 			}
 		}
 	}()
 }
 
-func subscribe(format uint8, interval int, provider, query string, conn net.Conn) {
+func subscribe(format uint8, rid uint32, interval int, provider, query string, conn net.Conn) {
 	sid.Add(1)
 
 	sub := &sub{
 		format:   format,
+		rid:      rid,
 		sid:      sid.Load(),
 		interval: interval,
 		provider: provider,
@@ -130,11 +143,18 @@ func watch(format uint8, s *sub, conn net.Conn) {
 	for {
 		time.Sleep(time.Duration(s.interval) * time.Millisecond)
 
-		if _, ok := subs[s.sid]; !ok {
+		mut.Lock()
+		_, ok := subs[s.sid]
+		mut.Unlock()
+
+		if !ok {
 			return
 		}
 
-		res := p.Query(conn, s.query, true, false, format)
+		res, err := p.RunQuery(context.Background(), withRequest(conn, s.rid, 0), s.query, true, false, format)
+		if err != nil {
+			continue
+		}
 
 		slices.SortFunc(res, sortEntries)
 
@@ -143,8 +163,8 @@ func watch(format uint8, s *sub, conn net.Conn) {
 			if len(res) != len(s.results) {
 				s.results = res
 
-				if ok := updated(format, conn, ""); !ok {
-					delete(subs, s.sid)
+				if ok := updated(format, s.rid, conn, ""); !ok {
+					unsubscribe(s.sid)
 				}
 
 				continue
@@ -155,8 +175,8 @@ func watch(format uint8, s *sub, conn net.Conn) {
 				if !equals(v, s.results[k]) {
 					s.results = res
 
-					if ok := updated(format, conn, ""); !ok {
-						delete(subs, s.sid)
+					if ok := updated(format, s.rid, conn, ""); !ok {
+						unsubscribe(s.sid)
 					}
 
 					break
@@ -168,9 +188,16 @@ func watch(format uint8, s *sub, conn net.Conn) {
 	}
 }
 
-func updated(format uint8, conn net.Conn, value string) bool {
+func unsubscribe(sid uint32) {
+	mut.Lock()
+	delete(subs, sid)
+	mut.Unlock()
+}
+
+func updated(format uint8, rid uint32, conn net.Conn, value string) bool {
 	resp := pb.SubscribeResponse{
 		Value: value,
+		Rid:   rid,
 	}
 
 	var b []byte
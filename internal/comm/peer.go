@@ -0,0 +1,97 @@
+package comm
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+
+	"github.com/abenz1267/elephant/v2/pkg/common"
+)
+
+var errForeignPeer = errors.New("peer belongs to another user")
+
+// peerCred returns the credentials of the process connected to the socket.
+func peerCred(conn *net.UnixConn) (*syscall.Ucred, error) {
+	raw, err := conn.SyscallConn()
+	if err != nil {
+		return nil, err
+	}
+
+	var cred *syscall.Ucred
+	var credErr error
+
+	err = raw.Control(func(fd uintptr) {
+		cred, credErr = syscall.GetsockoptUcred(int(fd), syscall.SOL_SOCKET, syscall.SO_PEERCRED)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return cred, credErr
+}
+
+// checkPeer rejects processes of other users and reports whether the process
+// may activate items. If activate_allowlist is set, only the listed
+// executables may, everyone else can only query.
+func checkPeer(conn *net.UnixConn) (bool, error) {
+	cred, err := peerCred(conn)
+	if err != nil {
+		return false, err
+	}
+
+	if int(cred.Uid) != os.Getuid() {
+		return false, fmt.Errorf("%w: uid %d", errForeignPeer, cred.Uid)
+	}
+
+	cfg := common.GetElephantConfig()
+
+	if cfg == nil || len(cfg.ActivateAllowlist) == 0 {
+		return true, nil
+	}
+
+	exe, err := os.Readlink(fmt.Sprintf("/proc/%d/exe", cred.Pid))
+	if err != nil {
+		slog.Warn("comm", "peer", err, "pid", cred.Pid)
+		return false, nil
+	}
+
+	if slices.Contains(allowedExecutables(cfg.ActivateAllowlist), exe) {
+		return true, nil
+	}
+
+	slog.Info("comm", "peer", "query only", "exe", exe)
+
+	return false, nil
+}
+
+// allowedExecutables resolves the allow-list to absolute paths. Entries
+// without a path are looked up in $PATH.
+func allowedExecutables(list []string) []string {
+	res := []string{}
+
+	for _, v := range list {
+		if !strings.Contains(v, "/") {
+			p, err := exec.LookPath(v)
+			if err != nil {
+				continue
+			}
+
+			v = p
+		}
+
+		if p, err := filepath.EvalSymlinks(v); err == nil {
+			v = p
+		}
+
+		res = append(res, v)
+	}
+
+	return res
+}
@@ -0,0 +1,235 @@
+package comm
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/abenz1267/elephant/v2/pkg/common"
+	"golang.org/x/net/websocket"
+)
+
+// TokenFile returns the path of the token remote clients have to
+// authenticate with.
+func TokenFile() string {
+	dir, err := common.ConfigDir()
+	if err != nil {
+		slog.Error("comm", "token", err)
+		return ""
+	}
+
+	return filepath.Join(dir, "token")
+}
+
+// loadToken reads the token, generating it on first use.
+func loadToken() string {
+	file := TokenFile()
+	if file == "" {
+		return ""
+	}
+
+	if b, err := os.ReadFile(file); err == nil {
+		token := strings.TrimSpace(string(b))
+
+		if token == "" {
+			slog.Error("comm", "token", "token file is empty", "file", file)
+		}
+
+		return token
+	}
+
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		slog.Error("comm", "token", err)
+		return ""
+	}
+
+	token := hex.EncodeToString(b)
+
+	if err := os.MkdirAll(filepath.Dir(file), 0o700); err != nil {
+		slog.Error("comm", "token", err)
+		return ""
+	}
+
+	if err := os.WriteFile(file, []byte(token+"\n"), 0o600); err != nil {
+		slog.Error("comm", "token", err)
+		return ""
+	}
+
+	slog.Info("comm", "token", "generated", "file", file)
+
+	return token
+}
+
+// startRemote starts the optional tcp and websocket listeners. Clients of
+// both have to authenticate before sending any other request.
+func startRemote() {
+	cfg := common.GetElephantConfig()
+
+	if cfg == nil || (cfg.TCPListen == "" && cfg.WebsocketListen == "") {
+		return
+	}
+
+	auth.Token = loadToken()
+
+	if auth.Token == "" {
+		slog.Error("comm", "remote", "no token, not starting tcp/websocket listeners")
+		return
+	}
+
+	if cfg.TCPListen != "" {
+		if err := checkListenAddr(cfg.TCPListen, cfg.RemoteNetwork); err != nil {
+			slog.Error("comm", "tcp", err)
+		} else {
+			go listenTCP(cfg.TCPListen)
+		}
+	}
+
+	if cfg.WebsocketListen != "" {
+		if err := checkListenAddr(cfg.WebsocketListen, cfg.RemoteNetwork); err != nil {
+			slog.Error("comm", "websocket", err)
+		} else {
+			go listenWebsocket(cfg.WebsocketListen)
+		}
+	}
+}
+
+// checkListenAddr rejects addresses reachable from other hosts, unless
+// allowed by remote_network. The token isn't encrypted in transit.
+func checkListenAddr(addr string, allowRemote bool) error {
+	if allowRemote {
+		return nil
+	}
+
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return err
+	}
+
+	if host == "localhost" {
+		return nil
+	}
+
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return nil
+	}
+
+	return fmt.Errorf("%s is not a loopback address, set remote_network = true to listen on it anyway", addr)
+}
+
+func listenTCP(addr string) {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		slog.Error("comm", "tcp", err)
+		return
+	}
+	defer l.Close()
+
+	addListener(l)
+
+	slog.Info("comm", "tcp", addr)
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+
+			slog.Error("comm", "accept", err)
+			continue
+		}
+
+		slog.Info("comm", "connection", "new", "remote", conn.RemoteAddr())
+
+		go handle(newConn(conn), cid.Add(1), access{activate: remoteActivate()})
+	}
+}
+
+// remoteActivate reports whether tcp and websocket clients may activate
+// items. They can't be matched against activate_allowlist, so they can only
+// query once it's set.
+func remoteActivate() bool {
+	cfg := common.GetElephantConfig()
+
+	return cfg == nil || len(cfg.ActivateAllowlist) == 0
+}
+
+// bearerToken returns the token of an "Authorization: Bearer <token>" header.
+func bearerToken(r *http.Request) string {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return ""
+	}
+
+	return strings.TrimSpace(token)
+}
+
+// checkOrigin rejects websocket handshakes of browsers on other sites.
+// Requests without an Origin header don't come from a browser and are
+// accepted, as are origins matching the host or listed in websocket_origins.
+func checkOrigin(cfg *websocket.Config, r *http.Request) error {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return nil
+	}
+
+	u, err := websocket.Origin(cfg, r)
+	if err != nil {
+		return err
+	}
+
+	if u.Host == r.Host {
+		return nil
+	}
+
+	if c := common.GetElephantConfig(); c != nil && slices.Contains(c.WebsocketOrigins, origin) {
+		return nil
+	}
+
+	slog.Warn("comm", "websocket", "rejected origin", "origin", origin, "remote", r.RemoteAddr)
+
+	return fmt.Errorf("origin not allowed: %s", origin)
+}
+
+// listenWebsocket serves websocket connections on /ws. Every websocket
+// message carries exactly one frame. The token can be passed in an
+// "Authorization: Bearer <token>" header instead of sending an AuthRequest.
+func listenWebsocket(addr string) {
+	mux := http.NewServeMux()
+
+	mux.Handle("/ws", websocket.Server{
+		Handshake: checkOrigin,
+		Handler: func(ws *websocket.Conn) {
+			ws.PayloadType = websocket.BinaryFrame
+
+			slog.Info("comm", "connection", "new", "remote", ws.Request().RemoteAddr)
+
+			handle(newConn(ws), cid.Add(1), access{
+				authenticated: auth.Valid(bearerToken(ws.Request())),
+				activate:      remoteActivate(),
+			})
+		},
+	})
+
+	srv := &http.Server{
+		Addr:    addr,
+		Handler: mux,
+	}
+
+	addListener(srv)
+
+	slog.Info("comm", "websocket", addr)
+
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		slog.Error("comm", "websocket", err)
+	}
+}
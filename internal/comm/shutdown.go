@@ -0,0 +1,83 @@
+package comm
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+
+	"github.com/abenz1267/elephant/v2/internal/comm/handlers"
+)
+
+var (
+	conns     = make(map[uint32]*conn)
+	listeners []io.Closer
+	connsMu   sync.Mutex
+)
+
+func addListener(l io.Closer) {
+	connsMu.Lock()
+	listeners = append(listeners, l)
+	connsMu.Unlock()
+}
+
+func addConn(cid uint32, c *conn) {
+	connsMu.Lock()
+	conns[cid] = c
+	connsMu.Unlock()
+}
+
+func removeConn(cid uint32) {
+	connsMu.Lock()
+	delete(conns, cid)
+	connsMu.Unlock()
+}
+
+func connections() int {
+	connsMu.Lock()
+	defer connsMu.Unlock()
+
+	return len(conns)
+}
+
+// Shutdown stops accepting connections, notifies all clients with a
+// ServerShutdown frame and closes their connections after flushing pending
+// frames. It returns early if ctx is done.
+func Shutdown(ctx context.Context) error {
+	connsMu.Lock()
+
+	for _, v := range listeners {
+		v.Close()
+	}
+
+	listeners = nil
+
+	var wg sync.WaitGroup
+
+	for _, v := range conns {
+		wg.Go(func() {
+			handlers.NotifyShutdown(v)
+			v.Close()
+		})
+	}
+
+	connsMu.Unlock()
+
+	os.Remove(Socket)
+
+	done := make(chan struct{})
+
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		slog.Info("comm", "shutdown", "all connections closed")
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -1,8 +1,7 @@
-package main
+package onepassword
 
 import (
 	"encoding/json"
-	"log/slog"
 	"os/exec"
 	"time"
 )
@@ -41,14 +40,14 @@ func initItems() {
 
 		output, err := cmd.CombinedOutput()
 		if err != nil {
-			slog.Error(Name, "init", err, "msg", output)
+			logger.Error(Name, "init", err, "msg", output)
 			continue
 		}
 
 		var items []OpItem
 
 		if err := json.Unmarshal(output, &items); err != nil {
-			slog.Error(Name, "parse", err, "msg", output)
+			logger.Error(Name, "parse", err, "msg", output)
 			continue
 		}
 
@@ -0,0 +1,6 @@
+// Package main builds the 1password provider as plugin.
+package main
+
+import onepassword "github.com/abenz1267/elephant/v2/internal/providers/1password"
+
+var Manifest = onepassword.Manifest
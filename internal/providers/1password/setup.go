@@ -1,4 +1,4 @@
-package main
+package onepassword
 
 import (
 	"fmt"
@@ -18,10 +18,33 @@ import (
 var (
 	Name        = "1password"
 	NamePretty  = "1Password"
+	logger      = slog.Default()
 	config      *Config
 	cachedItems []OpItem
 )
 
+var Requirements = []common.Requirement{
+	{Name: "1password-cli", Executables: []string{"op"}},
+}
+
+var Manifest = common.Manifest{
+	ABI:                  common.ABIVersion,
+	Name:                 Name,
+	NamePretty:           &NamePretty,
+	Available:            Available,
+	Setup:                Setup,
+	LoadConfig:           LoadConfig,
+	PrintDoc:             PrintDoc,
+	Icon:                 Icon,
+	HideFromProviderlist: HideFromProviderlist,
+	State:                State,
+	Activate:             Activate,
+	Query:                Query,
+	Requirements:         Requirements,
+	ActivateErr:          ActivateErr,
+	Logger:               &logger,
+}
+
 //go:embed README.md
 var readme string
 
@@ -34,7 +57,7 @@ type Config struct {
 }
 
 func LoadConfig() {
-	config = &Config{
+	cfg := &Config{
 		Config: common.Config{
 			Icon:     "1password",
 			MinScore: 20,
@@ -43,18 +66,20 @@ func LoadConfig() {
 		ClearAfter: 5,
 	}
 
-	common.LoadConfig(Name, config)
+	common.LoadConfig(Name, cfg)
+
+	if cfg.NamePretty != "" {
+		NamePretty = cfg.NamePretty
+	}
+
+	config = cfg
 }
 
 func Setup() {
 	LoadConfig()
 
-	if config.NamePretty != "" {
-		NamePretty = config.NamePretty
-	}
-
 	if len(config.Vaults) == 0 {
-		slog.Error(Name, "config", "no vaults")
+		logger.Error(Name, "config", "no vaults")
 		return
 	}
 
@@ -62,13 +87,7 @@ func Setup() {
 }
 
 func Available() bool {
-	p, err := exec.LookPath("op")
-	if p == "" || err != nil {
-		slog.Info(Name, "available", "1password cli not found.")
-		return false
-	}
-
-	return true
+	return common.RequirementsMet(Name, Requirements)
 }
 
 func PrintDoc(write bool) {
@@ -98,19 +117,24 @@ func notifyAndClear() {
 }
 
 func Activate(single bool, identifier, action string, query string, args string, format uint8, conn net.Conn) {
+	if err := ActivateErr(single, identifier, action, query, args, format, conn); err != nil {
+		logger.Error(Name, "activate", err)
+	}
+}
+
+func ActivateErr(single bool, identifier, action string, query string, args string, format uint8, conn net.Conn) error {
 	switch action {
 	case ActionCopyPassword:
 		toRun := "wl-copy --sensitive $(op item get %VALUE% --fields password --reveal)"
 
 		cmd := common.ReplaceResultOrStdinCmd(toRun, identifier)
 
-		err := cmd.Run()
-		if err != nil {
-			slog.Error(Name, "copy password", err)
+		if err := cmd.Run(); err != nil {
 			exec.Command("notify-send", "error copying password.").Run()
-		} else {
-			notifyAndClear()
+			return fmt.Errorf("copy password: %w", err)
 		}
+
+		notifyAndClear()
 	case ActionCopyUsername:
 		res := ""
 
@@ -122,26 +146,28 @@ func Activate(single bool, identifier, action string, query string, args string,
 
 		cmd := common.ReplaceResultOrStdinCmd("wl-copy", res)
 
-		err := cmd.Run()
-		if err != nil {
-			slog.Error(Name, "copy username", err)
+		if err := cmd.Run(); err != nil {
 			exec.Command("notify-send", "error copying username.").Run()
-		} else {
-			notifyAndClear()
+			return fmt.Errorf("copy username: %w", err)
 		}
+
+		notifyAndClear()
 	case ActionCopy2FA:
 		toRun := "wl-copy --sensitive $(op item get %VALUE% --otp)"
 
 		cmd := common.ReplaceResultOrStdinCmd(toRun, identifier)
 
-		err := cmd.Run()
-		if err != nil {
-			slog.Error(Name, "copy 2fa", err)
+		if err := cmd.Run(); err != nil {
 			exec.Command("notify-send", "error copying OTP.").Run()
-		} else {
-			notifyAndClear()
+			return fmt.Errorf("copy 2fa: %w", err)
 		}
+
+		notifyAndClear()
+	default:
+		return fmt.Errorf("unknown action: %s", action)
 	}
+
+	return nil
 }
 
 func Query(conn net.Conn, query string, single bool, exact bool, _ uint8) []*pb.QueryResponse_Item {
@@ -181,7 +207,7 @@ func Query(conn net.Conn, query string, single bool, exact bool, _ uint8) []*pb.
 		}
 	}
 
-	slog.Debug(Name, "query", time.Since(start))
+	logger.Debug(Name, "query", time.Since(start))
 
 	return entries
 }
@@ -1,6 +1,6 @@
 // Code generated by github.com/tinylib/msgp DO NOT EDIT.
 
-package main
+package archlinuxpkgs
 
 import (
 	"bytes"
@@ -0,0 +1,6 @@
+// Package main builds the archlinuxpkgs provider as plugin.
+package main
+
+import "github.com/abenz1267/elephant/v2/internal/providers/archlinuxpkgs"
+
+var Manifest = archlinuxpkgs.Manifest
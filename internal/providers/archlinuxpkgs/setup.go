@@ -1,4 +1,4 @@
-package main
+package archlinuxpkgs
 
 import (
 	"bytes"
@@ -25,6 +25,7 @@ import (
 var (
 	Name          = "archlinuxpkgs"
 	NamePretty    = "Arch Linux Packages"
+	logger        = slog.Default()
 	config        *Config
 	installed     = []string{}
 	installedOnly = false
@@ -32,6 +33,28 @@ var (
 	cachedData    = newCachedData()
 )
 
+var Requirements = []common.Requirement{
+	{Name: "aur helper", Executables: []string{"paru", "yay"}, Optional: true},
+}
+
+var Manifest = common.Manifest{
+	ABI:                  common.ABIVersion,
+	Name:                 Name,
+	NamePretty:           &NamePretty,
+	Available:            Available,
+	Setup:                Setup,
+	LoadConfig:           LoadConfig,
+	PrintDoc:             PrintDoc,
+	Icon:                 Icon,
+	HideFromProviderlist: HideFromProviderlist,
+	State:                State,
+	Activate:             Activate,
+	Query:                Query,
+	Requirements:         Requirements,
+	ActivateErr:          ActivateErr,
+	Logger:               &logger,
+}
+
 //go:embed README.md
 var readme string
 
@@ -116,7 +139,7 @@ func clearCache() {
 func LoadConfig() {
 	helper := detectHelper()
 
-	config = &Config{
+	cfg := &Config{
 		Config: common.Config{
 			Icon:     "applications-internet",
 			MinScore: 20,
@@ -126,16 +149,18 @@ func LoadConfig() {
 		AutoWrapWithTerminal: true,
 	}
 
-	common.LoadConfig(Name, config)
+	common.LoadConfig(Name, cfg)
+
+	if cfg.NamePretty != "" {
+		NamePretty = cfg.NamePretty
+	}
+
+	config = cfg
 }
 
 func Setup() {
 	LoadConfig()
 
-	if config.NamePretty != "" {
-		NamePretty = config.NamePretty
-	}
-
 	setup()
 	go clearCache()
 }
@@ -148,7 +173,7 @@ func setup() {
 	var b bytes.Buffer
 	err := msgp.Encode(&b, &cachedData)
 	if err != nil {
-		slog.Error(Name, "setup", err)
+		logger.Error(Name, "setup", err)
 	}
 
 	os.Remove(cacheFile)
@@ -175,35 +200,46 @@ func PrintDoc(write bool) {
 }
 
 func Activate(single bool, identifier, action string, query string, args string, format uint8, conn net.Conn) {
+	if err := ActivateErr(single, identifier, action, query, args, format, conn); err != nil {
+		logger.Error(Name, "activate", err)
+	}
+}
+
+func ActivateErr(single bool, identifier, action string, query string, args string, format uint8, conn net.Conn) error {
 	defer freeMem()
 
 	switch action {
-	case ActionVisitURL:
-		run := strings.TrimSpace(fmt.Sprintf("%s xdg-open '%s'", common.LaunchPrefix(), cachedData.Packages[identifier].URL))
-		cmd := exec.Command("sh", "-c", run)
-
-		err := cmd.Start()
-		if err != nil {
-			slog.Error(Name, "activate", err, "action", action)
-		} else {
-			go func() {
-				cmd.Wait()
-			}()
-		}
-
-		return
 	case ActionRefresh:
 		setup()
-		return
+		return nil
 	case ActionShowAll:
 		installedOnly = false
-		return
+		return nil
 	case ActionShowInstalled:
 		installedOnly = true
-		return
+		return nil
+	}
+
+	pkg, ok := cachedData.Packages[identifier]
+	if !ok {
+		return fmt.Errorf("unknown package: %s", identifier)
+	}
+
+	if action == ActionVisitURL {
+		run := strings.TrimSpace(fmt.Sprintf("%s xdg-open '%s'", common.LaunchPrefix(), pkg.URL))
+		cmd := exec.Command("sh", "-c", run)
+
+		if err := cmd.Start(); err != nil {
+			return err
+		}
+
+		go func() {
+			cmd.Wait()
+		}()
+
+		return nil
 	}
 
-	name := cachedData.Packages[identifier].Name
 	var pkgcmd string
 
 	switch action {
@@ -212,11 +248,10 @@ func Activate(single bool, identifier, action string, query string, args string,
 	case ActionRemove:
 		pkgcmd = config.CommandRemove
 	default:
-		slog.Error(Name, "activate", fmt.Sprintf("unknown action: %s", action))
-		return
+		return fmt.Errorf("unknown action: %s", action)
 	}
 
-	pkgcmd = strings.ReplaceAll(pkgcmd, "%VALUE%", name)
+	pkgcmd = strings.ReplaceAll(pkgcmd, "%VALUE%", pkg.Name)
 	toRun := common.WrapWithTerminal(pkgcmd)
 
 	if !config.AutoWrapWithTerminal {
@@ -224,14 +259,16 @@ func Activate(single bool, identifier, action string, query string, args string,
 	}
 
 	cmd := exec.Command("sh", "-c", toRun)
-	err := cmd.Start()
-	if err != nil {
-		slog.Error(Name, "activate", err)
-	} else {
-		go func() {
-			cmd.Wait()
-		}()
+
+	if err := cmd.Start(); err != nil {
+		return err
 	}
+
+	go func() {
+		cmd.Wait()
+	}()
+
+	return nil
 }
 
 func Query(conn net.Conn, query string, single bool, exact bool, _ uint8) []*pb.QueryResponse_Item {
@@ -243,7 +280,7 @@ func Query(conn net.Conn, query string, single bool, exact bool, _ uint8) []*pb.
 		b, _ := os.ReadFile(cacheFile)
 		err := msgp.Decode(bytes.NewReader(b), &cachedData)
 		if err != nil {
-			slog.Error(Name, "query", err)
+			logger.Error(Name, "query", err)
 			return entries
 		}
 	}
@@ -339,7 +376,7 @@ func getOfficialPkgs() {
 
 	out, err := cmd.CombinedOutput()
 	if err != nil {
-		slog.Error(Name, "pacman", err)
+		logger.Error(Name, "pacman", err)
 	}
 
 	var data strings.Builder
@@ -384,7 +421,7 @@ func getOfficialPkgs() {
 func setupAURPkgs() {
 	resp, err := http.Get("https://aur.archlinux.org/packages-meta-v1.json.gz")
 	if err != nil {
-		slog.Error(Name, "aurdownload", err)
+		logger.Error(Name, "aurdownload", err)
 		return
 	}
 	defer resp.Body.Close()
@@ -402,7 +439,7 @@ func setupAURPkgs() {
 
 	err = decoder.Decode(&aurPackages)
 	if err != nil {
-		slog.Error(Name, "jsondecode", err)
+		logger.Error(Name, "jsondecode", err)
 		return
 	}
 
@@ -425,7 +462,7 @@ func getInstalled() {
 	cmd := exec.Command("pacman", "-Qe")
 	out, err := cmd.CombinedOutput()
 	if err != nil {
-		slog.Error(Name, "installed", err)
+		logger.Error(Name, "installed", err)
 	}
 
 	for line := range strings.Lines(string(out)) {
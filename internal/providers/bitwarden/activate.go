@@ -1,9 +1,8 @@
-package main
+package bitwarden
 
 import (
 	"encoding/json"
 	"fmt"
-	"log/slog"
 	"net"
 	"os/exec"
 	"strings"
@@ -42,48 +41,44 @@ type RbwUris struct {
 	MatchType int    `json:"match_type"`
 }
 
-func syncLocalRbwVault() {
+func syncLocalRbwVault() error {
 	cmd := exec.Command("rbw", "sync")
 	if err := cmd.Run(); err != nil {
-		slog.Error(Name, "sync failed", err)
-		return
+		return fmt.Errorf("sync failed: %w", err)
 	}
 
 	initItems()
 	exec.Command("notify-send", "Vault synced successfully").Run()
+
+	return nil
 }
 
-func getRbwItem(identifier string, action string) *RbwLoginItem {
+func getRbwItem(identifier string) (*RbwLoginItem, error) {
 	cmd := common.ReplaceResultOrStdinCmd("rbw get %VALUE% --full --raw", identifier)
-	stdout, stderr := cmd.CombinedOutput()
-
-	if stderr != nil {
-		slog.Error(Name, action, stderr)
-
+	stdout, err := cmd.CombinedOutput()
+	if err != nil {
 		exec.Command("notify-send", "Failed to fetch data").Run()
-		return nil
+		return nil, fmt.Errorf("fetch: %w", err)
 	}
 
 	item := &RbwLoginItem{}
 	if err := json.Unmarshal(stdout, &item); err != nil {
-		slog.Error(Name, "parse", err)
-		return nil
+		return nil, fmt.Errorf("parse: %w", err)
 	}
 
 	if item.Data.Password == nil {
 		exec.Command("notify-send", "Unsupported Item").Run()
-		return nil
+		return nil, fmt.Errorf("unsupported item: %s", identifier)
 	}
 
-	return item
+	return item, nil
 }
 
-func copyToClipboard(value string, logStr string) {
+func copyToClipboard(value string, logStr string) error {
 	cmd := common.ReplaceResultOrStdinCmd(config.CopyCommand, value)
 	err := cmd.Start()
 	if err != nil {
-		slog.Error(Name, fmt.Sprintf("%s failed", logStr), err)
-		return
+		return fmt.Errorf("%s failed: %w", logStr, err)
 	}
 
 	go func() {
@@ -91,6 +86,8 @@ func copyToClipboard(value string, logStr string) {
 		exec.Command("notify-send", fmt.Sprintf("%s succeeded", logStr)).Run()
 		clearClipboard()
 	}()
+
+	return nil
 }
 
 func clearClipboard() {
@@ -100,7 +97,7 @@ func clearClipboard() {
 	}
 }
 
-func typeValue(value string, logStr string) {
+func typeValue(value string, logStr string) error {
 	if config.AutoTypeDelay > 0 {
 		time.Sleep(time.Duration(config.AutoTypeDelay) * time.Millisecond)
 	}
@@ -108,38 +105,43 @@ func typeValue(value string, logStr string) {
 	cmd := common.ReplaceResultOrStdinCmd(config.AutoTypeCommand, value)
 	err := cmd.Start()
 	if err != nil {
-		slog.Error(Name, fmt.Sprintf("%s failed", logStr), err)
-		return
+		return fmt.Errorf("%s failed: %w", logStr, err)
 	}
 
 	go func() {
 		cmd.Wait()
 	}()
+
+	return nil
 }
 
 func Activate(single bool, identifier, action, query, args string, format uint8, conn net.Conn) {
+	if err := ActivateErr(single, identifier, action, query, args, format, conn); err != nil {
+		logger.Error(Name, "activate", err)
+	}
+}
+
+func ActivateErr(single bool, identifier, action, query, args string, format uint8, conn net.Conn) error {
 	if action == ActionSyncVault {
-		syncLocalRbwVault()
-		return
+		return syncLocalRbwVault()
 	}
 
-	item := getRbwItem(identifier, action)
-	if item == nil {
-		return
+	item, err := getRbwItem(identifier)
+	if err != nil {
+		return err
 	}
 
 	switch action {
 	case ActionCopyUsername:
-		copyToClipboard(item.Data.Username, "Username copy")
+		return copyToClipboard(item.Data.Username, "Username copy")
 	case ActionCopyPassword:
-		copyToClipboard(*item.Data.Password, "Password copy")
+		return copyToClipboard(*item.Data.Password, "Password copy")
 	case ActionCopyTotp:
 		cmd := common.ReplaceResultOrStdinCmd("rbw totp %VALUE% --clipboard", identifier)
 
 		err := cmd.Start()
 		if err != nil {
-			slog.Error(Name, "copy totp", err)
-			return
+			return fmt.Errorf("copy totp: %w", err)
 		}
 
 		go func() {
@@ -153,19 +155,24 @@ func Activate(single bool, identifier, action, query, args string, format uint8,
 			clearClipboard()
 		}()
 	case ActionTypeUsername:
-		typeValue(item.Data.Username, "Typing username")
+		return typeValue(item.Data.Username, "Typing username")
 	case ActionTypePassword:
-		typeValue(*item.Data.Password, "Typing password")
+		return typeValue(*item.Data.Password, "Typing password")
 	case ActionTypeTotp:
 		cmd := common.ReplaceResultOrStdinCmd("rbw totp %VALUE%", identifier)
 
 		output, err := cmd.Output()
 		if err != nil {
 			exec.Command("notify-send", "Entry does not contain totp").Run()
-			return
+			return fmt.Errorf("type totp: %w", err)
 		}
 
 		value := strings.TrimSpace(string(output[:]))
-		typeValue(value, "Typing totp")
+
+		return typeValue(value, "Typing totp")
+	default:
+		return fmt.Errorf("unknown action: %s", action)
 	}
+
+	return nil
 }
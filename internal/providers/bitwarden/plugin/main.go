@@ -0,0 +1,6 @@
+// Package main builds the bitwarden provider as plugin.
+package main
+
+import "github.com/abenz1267/elephant/v2/internal/providers/bitwarden"
+
+var Manifest = bitwarden.Manifest
@@ -1,9 +1,8 @@
-package main
+package bitwarden
 
 import (
 	"encoding/json"
 	"fmt"
-	"log/slog"
 	"net"
 	"os/exec"
 	"strings"
@@ -25,12 +24,12 @@ func initItems() {
 	cmd := exec.Command("rbw", "list", "--raw")
 	output, err := cmd.CombinedOutput()
 	if err != nil {
-		slog.Error(Name, "init", err, "msg", output)
+		logger.Error(Name, "init", err, "msg", output)
 		return
 	}
 
 	if err := json.Unmarshal(output, &cachedItems); err != nil {
-		slog.Error(Name, "parse", err, "msg", output)
+		logger.Error(Name, "parse", err, "msg", output)
 		return
 	}
 }
@@ -86,7 +85,7 @@ func Query(conn net.Conn, query string, single bool, exact bool, format uint8) [
 		}
 	}
 
-	slog.Debug(Name, "query", time.Since(start))
+	logger.Debug(Name, "query", time.Since(start))
 
 	return entries
 }
@@ -1,10 +1,9 @@
-package main
+package bitwarden
 
 import (
 	_ "embed"
 	"fmt"
 	"log/slog"
-	"os/exec"
 
 	"github.com/abenz1267/elephant/v2/internal/util"
 	"github.com/abenz1267/elephant/v2/pkg/common"
@@ -17,10 +16,33 @@ var readme string
 var (
 	Name        = "bitwarden"
 	NamePretty  = "Bitwarden"
+	logger      = slog.Default()
 	config      *Config
 	cachedItems []RbwItem
 )
 
+var Requirements = []common.Requirement{
+	{Name: "rbw", Executables: []string{"rbw"}},
+}
+
+var Manifest = common.Manifest{
+	ABI:                  common.ABIVersion,
+	Name:                 Name,
+	NamePretty:           &NamePretty,
+	Available:            Available,
+	Setup:                Setup,
+	LoadConfig:           LoadConfig,
+	PrintDoc:             PrintDoc,
+	Icon:                 Icon,
+	HideFromProviderlist: HideFromProviderlist,
+	State:                State,
+	Activate:             Activate,
+	Query:                Query,
+	Requirements:         Requirements,
+	ActivateErr:          ActivateErr,
+	Logger:               &logger,
+}
+
 type Config struct {
 	common.Config   `koanf:",squash"`
 	ClearAfter      int    `koanf:"clear_after" desc:"clipboard will be cleared after X seconds. 0 to disable." default:"5"`
@@ -38,7 +60,7 @@ func Setup() {
 }
 
 func LoadConfig() {
-	config = &Config{
+	cfg := &Config{
 		Config: common.Config{
 			Icon:     "bitwarden",
 			MinScore: 20,
@@ -51,22 +73,13 @@ func LoadConfig() {
 		AutoTypeDelay:   500,
 	}
 
-	common.LoadConfig(Name, config)
-}
-
-func executableExists(command string) bool {
-	p, err := exec.LookPath(command)
-
-	if p == "" || err != nil {
-		slog.Info(Name, "available", fmt.Sprintf("%s not found. disabling.", command))
-		return false
-	}
+	common.LoadConfig(Name, cfg)
 
-	return true
+	config = cfg
 }
 
 func Available() bool {
-	return executableExists("rbw")
+	return common.RequirementsMet(Name, Requirements)
 }
 
 func PrintDoc(write bool) {
@@ -1,7 +1,8 @@
-// Package symbols provides symbols/emojis.
-package main
+// Package bluetooth manages bluetooth devices.
+package bluetooth
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"net"
@@ -20,10 +21,34 @@ import (
 var (
 	Name       = "bluetooth"
 	NamePretty = "Bluetooth"
+	logger     = slog.Default()
 	find       = false
 	on         = true
 )
 
+var Requirements = []common.Requirement{
+	{Name: "bluez-utils", Executables: []string{"bluetoothctl"}},
+}
+
+var Manifest = common.Manifest{
+	ABI:                  common.ABIVersion,
+	Name:                 Name,
+	NamePretty:           &NamePretty,
+	Available:            Available,
+	Setup:                Setup,
+	LoadConfig:           LoadConfig,
+	PrintDoc:             PrintDoc,
+	Icon:                 Icon,
+	HideFromProviderlist: HideFromProviderlist,
+	State:                State,
+	Activate:             Activate,
+	Query:                Query,
+	Requirements:         Requirements,
+	QueryContext:         QueryContext,
+	ActivateErr:          ActivateErr,
+	Logger:               &logger,
+}
+
 //go:embed README.md
 var readme string
 
@@ -48,33 +73,28 @@ var config *Config
 func Setup() {
 	LoadConfig()
 
-	if config.NamePretty != "" {
-		NamePretty = config.NamePretty
-	}
-
 	checkPowerState()
 }
 
 func LoadConfig() {
-	config = &Config{
+	cfg := &Config{
 		Config: common.Config{
 			Icon:     "bluetooth-symbolic",
 			MinScore: 20,
 		},
 	}
 
-	common.LoadConfig(Name, config)
-}
-
-func Available() bool {
-	p, err := exec.LookPath("bluetoothctl")
+	common.LoadConfig(Name, cfg)
 
-	if p == "" || err != nil {
-		slog.Info(Name, "available", "bluetoothctl not found. disabling")
-		return false
+	if cfg.NamePretty != "" {
+		NamePretty = cfg.NamePretty
 	}
 
-	return true
+	config = cfg
+}
+
+func Available() bool {
+	return common.RequirementsMet(Name, Requirements)
 }
 
 func PrintDoc(write bool) {
@@ -120,6 +140,12 @@ func checkPowerState() error {
 }
 
 func Activate(single bool, identifier, action string, query string, args string, format uint8, conn net.Conn) {
+	if err := ActivateErr(single, identifier, action, query, args, format, conn); err != nil {
+		logger.Error(Name, "activate", err)
+	}
+}
+
+func ActivateErr(single bool, identifier, action string, query string, args string, format uint8, conn net.Conn) error {
 	cmd := exec.Command("bluetoothctl")
 
 	removed := false
@@ -137,7 +163,7 @@ func Activate(single bool, identifier, action string, query string, args string,
 	case ActionFind:
 		find = true
 		handlers.ProviderUpdated <- "bluetooth:find"
-		return
+		return nil
 	case ActionPair:
 		added = true
 		handlers.ProviderUpdated <- "bluetooth:pair"
@@ -179,20 +205,24 @@ disconnect %s
 quit
 `, identifier))
 	default:
-		slog.Error(Name, "activate", fmt.Sprintf("unknown action: %s", action))
-		return
+		return fmt.Errorf("unknown action: %s", action)
 	}
 
 	out, err := cmd.CombinedOutput()
+
+	logger.Debug(Name, "activate", out)
+
 	if err != nil {
-		slog.Error(Name, "activate", err)
+		err = fmt.Errorf("bluetoothctl: %w: %s", err, strings.TrimSpace(string(out)))
 	}
 
-	slog.Debug(Name, "activate", out)
-
 	if action == ActionPowerOn || action == ActionPowerOff {
 		checkPowerState()
-		return
+		return err
+	}
+
+	if err != nil {
+		return err
 	}
 
 	if added || removed {
@@ -203,7 +233,7 @@ quit
 			cmd = exec.Command("bluetoothctl", "devices", "Paired")
 			out, err = cmd.CombinedOutput()
 			if err != nil {
-				slog.Error(Name, "get devices", err)
+				logger.Error(Name, "get devices", err)
 			}
 
 			for v := range strings.Lines(strings.TrimSpace(string(out))) {
@@ -226,7 +256,7 @@ quit
 			cmd := exec.Command("bluetoothctl", "info", identifier)
 			out, err := cmd.CombinedOutput()
 			if err != nil {
-				slog.Error(Name, "get info", err)
+				logger.Error(Name, "get info", err)
 			}
 
 			for l := range strings.Lines(string(out)) {
@@ -242,9 +272,15 @@ quit
 			}
 		}
 	}
+
+	return nil
+}
+
+func Query(conn net.Conn, query string, single bool, exact bool, format uint8) []*pb.QueryResponse_Item {
+	return QueryContext(context.Background(), conn, query, single, exact, format)
 }
 
-func Query(conn net.Conn, query string, _ bool, exact bool, _ uint8) []*pb.QueryResponse_Item {
+func QueryContext(ctx context.Context, conn net.Conn, query string, _ bool, exact bool, _ uint8) []*pb.QueryResponse_Item {
 	start := time.Now()
 	entries := []*pb.QueryResponse_Item{}
 
@@ -252,7 +288,7 @@ func Query(conn net.Conn, query string, _ bool, exact bool, _ uint8) []*pb.Query
 		return entries
 	}
 
-	getDevices()
+	getDevices(ctx)
 
 	for k, v := range devices {
 		s := []string{}
@@ -314,7 +350,7 @@ func Query(conn net.Conn, query string, _ bool, exact bool, _ uint8) []*pb.Query
 		}
 	}
 
-	slog.Debug(Name, "query", time.Since(start))
+	logger.Debug(Name, "query", time.Since(start))
 	return entries
 }
 
@@ -346,14 +382,14 @@ func State(provider string) *pb.ProviderStateResponse {
 	}
 }
 
-func getDevices() {
+func getDevices(ctx context.Context) {
 	devices = []Device{}
 
 	if find {
-		cmd := exec.Command("bluetoothctl", "--timeout", "5", "scan", "on")
+		cmd := exec.CommandContext(ctx, "bluetoothctl", "--timeout", "5", "scan", "on")
 		out, err := cmd.CombinedOutput()
 		if err != nil {
-			slog.Error(Name, "find devices", err)
+			logger.Error(Name, "find devices", err)
 			return
 		}
 
@@ -380,14 +416,18 @@ func getDevices() {
 
 	devices = []Device{}
 
-	cmd := exec.Command("bluetoothctl", "devices", "Paired")
+	cmd := exec.CommandContext(ctx, "bluetoothctl", "devices", "Paired")
 
 	out, err := cmd.CombinedOutput()
 	if err != nil {
-		slog.Error(Name, "get devices", err)
+		logger.Error(Name, "get devices", err)
 	}
 
 	for v := range strings.Lines(string(out)) {
+		if ctx.Err() != nil {
+			return
+		}
+
 		if strings.Contains(v, "Device") {
 			fields := strings.SplitN(v, " ", 3)
 			d := Device{
@@ -395,10 +435,10 @@ func getDevices() {
 				Mac:  fields[1],
 			}
 
-			cmd := exec.Command("bluetoothctl", "info", d.Mac)
+			cmd := exec.CommandContext(ctx, "bluetoothctl", "info", d.Mac)
 			out, err := cmd.CombinedOutput()
 			if err != nil {
-				slog.Error(Name, "get info", err)
+				logger.Error(Name, "get info", err)
 			}
 
 			for l := range strings.Lines(string(out)) {
@@ -1,4 +1,4 @@
-package main
+package bookmarks
 
 import (
 	_ "embed"
@@ -25,6 +25,7 @@ import (
 var (
 	Name              = "bookmarks"
 	NamePretty        = "Bookmarks"
+	logger            = slog.Default()
 	config            *Config
 	bookmarks         = []Bookmark{}
 	availableBrowsers = make(map[string]string)
@@ -34,6 +35,23 @@ var (
 	creating          bool
 )
 
+var Manifest = common.Manifest{
+	ABI:                  common.ABIVersion,
+	Name:                 Name,
+	NamePretty:           &NamePretty,
+	Available:            Available,
+	Setup:                Setup,
+	LoadConfig:           LoadConfig,
+	PrintDoc:             PrintDoc,
+	Icon:                 Icon,
+	HideFromProviderlist: HideFromProviderlist,
+	State:                State,
+	Activate:             Activate,
+	Query:                Query,
+	ActivateErr:          ActivateErr,
+	Logger:               &logger,
+}
+
 //go:embed README.md
 var readme string
 
@@ -120,7 +138,7 @@ func (b *Bookmark) fromCSVRow(row string) error {
 		b.Browser = parts[3]
 		t, err := time.Parse(time.RFC1123Z, parts[4])
 		if err != nil {
-			slog.Error(Name, "timeparse", err)
+			logger.Error(Name, "timeparse", err)
 			b.CreatedAt = time.Now()
 		} else {
 			b.CreatedAt = t
@@ -128,7 +146,7 @@ func (b *Bookmark) fromCSVRow(row string) error {
 	} else {
 		t, err := time.Parse(time.RFC1123Z, parts[3])
 		if err != nil {
-			slog.Error(Name, "timeparse", err)
+			logger.Error(Name, "timeparse", err)
 			b.CreatedAt = time.Now()
 		} else {
 			b.CreatedAt = t
@@ -184,13 +202,13 @@ func saveBookmarks() {
 
 	err := os.MkdirAll(filepath.Dir(f), 0o755)
 	if err != nil {
-		slog.Error(Name, "mkdirall", err)
+		logger.Error(Name, "mkdirall", err)
 		return
 	}
 
 	file, err := os.Create(f)
 	if err != nil {
-		slog.Error(Name, "createfile", err)
+		logger.Error(Name, "createfile", err)
 		return
 	}
 	defer file.Close()
@@ -204,7 +222,7 @@ func saveBookmarks() {
 	content := strings.Join(lines, "\n")
 	_, err = file.WriteString(content)
 	if err != nil {
-		slog.Error(Name, "writefile", err)
+		logger.Error(Name, "writefile", err)
 	}
 
 	if config.w != nil {
@@ -239,7 +257,7 @@ func loadBookmarks() {
 
 	data, err := os.ReadFile(file)
 	if err != nil {
-		slog.Error(Name, "readfile", err)
+		logger.Error(Name, "readfile", err)
 		return
 	}
 
@@ -256,7 +274,7 @@ func loadBookmarks() {
 
 		b := Bookmark{}
 		if err := b.fromCSVRow(line); err != nil {
-			slog.Error(Name, "parserow", err)
+			logger.Error(Name, "parserow", err)
 			continue
 		}
 
@@ -269,10 +287,6 @@ func loadBookmarks() {
 func Setup() {
 	LoadConfig()
 
-	if config.NamePretty != "" {
-		NamePretty = config.NamePretty
-	}
-
 	if strings.HasPrefix(config.Location, "https://") {
 		isGit = true
 	}
@@ -298,7 +312,7 @@ func Setup() {
 }
 
 func LoadConfig() {
-	config = &Config{
+	cfg := &Config{
 		Config: common.Config{
 			Icon:     "user-bookmarks",
 			MinScore: 20,
@@ -308,7 +322,13 @@ func LoadConfig() {
 		SetBrowserOnImport: false,
 	}
 
-	common.LoadConfig(Name, config)
+	common.LoadConfig(Name, cfg)
+
+	if cfg.NamePretty != "" {
+		NamePretty = cfg.NamePretty
+	}
+
+	config = cfg
 }
 
 func Available() bool {
@@ -324,30 +344,43 @@ func PrintDoc(write bool) {
 }
 
 func Activate(single bool, identifier, action string, query string, args string, format uint8, conn net.Conn) {
-	i, _ := strconv.Atoi(identifier)
+	if err := ActivateErr(single, identifier, action, query, args, format, conn); err != nil {
+		logger.Error(Name, "activate", err)
+	}
+}
+
+func ActivateErr(single bool, identifier, action string, query string, args string, format uint8, conn net.Conn) error {
+	i, err := strconv.Atoi(identifier)
+
+	switch action {
+	case ActionChangeCategory, ActionChangeBrowser, ActionDelete, ActionOpen, "":
+		if err != nil || i < 0 || i >= len(bookmarks) {
+			return fmt.Errorf("unknown bookmark: %s", identifier)
+		}
+	}
 
 	switch action {
 	case history.ActionDelete:
 		h.Remove(identifier)
-		return
+		return nil
 	case ActionImport:
 		if action == ActionImport {
 			importBrowserBookmarks()
-			return
+			return nil
 		}
 	case ActionSave:
 		if after, ok := strings.CutPrefix(identifier, "CREATE:"); ok {
 			creating = false
 			store(after)
 
-			return
+			return nil
 		}
 	case ActionSearch:
 		creating = false
-		return
+		return nil
 	case ActionCreate:
 		creating = true
-		return
+		return nil
 	case ActionChangeCategory:
 		bookmarks[i].Imported = false
 		currentCategory := bookmarks[i].Category
@@ -417,26 +450,26 @@ func Activate(single bool, identifier, action string, query string, args string,
 		}
 
 		cmd := exec.Command("sh", "-c", command)
-		err := cmd.Start()
-		if err != nil {
-			slog.Error(Name, "open", err)
-		} else {
-			go func() {
-				cmd.Wait()
-			}()
+		if err := cmd.Start(); err != nil {
+			return fmt.Errorf("open: %w", err)
 		}
 
+		go func() {
+			cmd.Wait()
+		}()
+
 		if config.History {
 			h.Save(query, identifier)
 		}
 
-		return
+		return nil
 	default:
-		slog.Error(Name, "activate", fmt.Sprintf("unknown action: %s", action))
-		return
+		return fmt.Errorf("unknown action: %s", action)
 	}
 
 	saveBookmarks()
+
+	return nil
 }
 
 func store(query string) {
@@ -538,7 +571,7 @@ func readChromiumBookmarks(path string) map[string]Bookmark {
 	cmd := exec.Command("sh", "-c", fmt.Sprintf(`jq -r '.roots | .. | objects | select(.type == "url") | "\(.name)|||\(.url)"' "%s" 2>/dev/null`, path))
 	out, err := cmd.Output()
 	if err != nil {
-		slog.Error(Name, "jq", err)
+		logger.Error(Name, "jq", err)
 		return bookmarkMap
 	}
 
@@ -573,7 +606,7 @@ func readFirefoxBookmarks(path string) map[string]Bookmark {
 	cmd := exec.Command("sh", "-c", fmt.Sprintf(`sqlite3 -separator "|||" "file:%s?immutable=1" "SELECT mb.title, mp.url FROM moz_bookmarks mb JOIN moz_places mp ON mb.fk = mp.id WHERE mb.type = 1 AND LENGTH(mb.title) > 0" 2>/dev/null`, escapedPath))
 	out, err := cmd.Output()
 	if err != nil {
-		slog.Error(Name, "sqlite3", err)
+		logger.Error(Name, "sqlite3", err)
 		return bookmarkMap
 	}
 
@@ -634,9 +667,9 @@ func importBrowserBookmarks() {
 
 	if imported > 0 {
 		saveBookmarks()
-		slog.Info(Name, "imported", fmt.Sprintf("%d bookmarks", imported))
+		logger.Info(Name, "imported", fmt.Sprintf("%d bookmarks", imported))
 	} else {
-		slog.Info(Name, "imported", "no new bookmarks found")
+		logger.Info(Name, "imported", "no new bookmarks found")
 	}
 }
 
@@ -0,0 +1,6 @@
+// Package main builds the calc provider as plugin.
+package main
+
+import "github.com/abenz1267/elephant/v2/internal/providers/calc"
+
+var Manifest = calc.Manifest
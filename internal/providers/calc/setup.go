@@ -1,7 +1,8 @@
-package main
+package calc
 
 import (
 	"bytes"
+	"context"
 	"crypto/md5"
 	"encoding/gob"
 	"encoding/hex"
@@ -27,9 +28,33 @@ import (
 var (
 	Name       = "calc"
 	NamePretty = "Calculator/Unit-Conversion"
+	logger     = slog.Default()
 	config     *Config
 )
 
+var Requirements = []common.Requirement{
+	{Name: "libqalculate", Executables: []string{"qalc"}},
+}
+
+var Manifest = common.Manifest{
+	ABI:                  common.ABIVersion,
+	Name:                 Name,
+	NamePretty:           &NamePretty,
+	Available:            Available,
+	Setup:                Setup,
+	LoadConfig:           LoadConfig,
+	PrintDoc:             PrintDoc,
+	Icon:                 Icon,
+	HideFromProviderlist: HideFromProviderlist,
+	State:                State,
+	Activate:             Activate,
+	Query:                Query,
+	Requirements:         Requirements,
+	ActivateErr:          ActivateErr,
+	QueryContext:         QueryContext,
+	Logger:               &logger,
+}
+
 //go:embed README.md
 var readme string
 
@@ -62,17 +87,13 @@ var history = []HistoryItem{}
 func Setup() {
 	LoadConfig()
 
-	if config.NamePretty != "" {
-		NamePretty = config.NamePretty
-	}
-
 	loadHist()
 
 	// this is to update exchange rate data
 	cmd := exec.Command("qalc", "-e", "1+1")
 	err := cmd.Start()
 	if err != nil {
-		slog.Error(Name, "init", err)
+		logger.Error(Name, "init", err)
 	} else {
 		go func() {
 			cmd.Wait()
@@ -81,7 +102,7 @@ func Setup() {
 }
 
 func LoadConfig() {
-	config = &Config{
+	cfg := &Config{
 		Config: common.Config{
 			Icon: "accessories-calculator",
 		},
@@ -94,18 +115,17 @@ func LoadConfig() {
 		Autosave:      false,
 	}
 
-	common.LoadConfig(Name, config)
-}
+	common.LoadConfig(Name, cfg)
 
-func Available() bool {
-	p, err := exec.LookPath("qalc")
-
-	if p == "" || err != nil {
-		slog.Info(Name, "available", "libqalculate not found. disabling")
-		return false
+	if cfg.NamePretty != "" {
+		NamePretty = cfg.NamePretty
 	}
 
-	return true
+	config = cfg
+}
+
+func Available() bool {
+	return common.RequirementsMet(Name, Requirements)
 }
 
 func PrintDoc(write bool) {
@@ -118,6 +138,12 @@ func PrintDoc(write bool) {
 }
 
 func Activate(single bool, identifier, action string, query string, args string, format uint8, conn net.Conn) {
+	if err := ActivateErr(single, identifier, action, query, args, format, conn); err != nil {
+		logger.Error(Name, "activate", err)
+	}
+}
+
+func ActivateErr(single bool, identifier, action string, query string, args string, format uint8, conn net.Conn) error {
 	i := slices.IndexFunc(history, func(item HistoryItem) bool {
 		return item.Identifier == identifier
 	})
@@ -131,8 +157,7 @@ func Activate(single bool, identifier, action string, query string, args string,
 		cmd := exec.Command("qalc", "-t", query)
 		out, err := cmd.CombinedOutput()
 		if err != nil {
-			slog.Error(Name, "result", err)
-			return
+			return fmt.Errorf("qalc: %w: %s", err, strings.TrimSpace(string(out)))
 		}
 
 		result = strings.TrimSpace(string(out))
@@ -145,13 +170,13 @@ func Activate(single bool, identifier, action string, query string, args string,
 
 		err := cmd.Start()
 		if err != nil {
-			slog.Error(Name, "copy", err)
-		} else {
-			go func() {
-				cmd.Wait()
-			}()
+			return fmt.Errorf("copy: %w", err)
 		}
 
+		go func() {
+			cmd.Wait()
+		}()
+
 		if createHistoryItem {
 			saveToHistory(query, result)
 		}
@@ -179,9 +204,10 @@ func Activate(single bool, identifier, action string, query string, args string,
 
 		saveHist()
 	default:
-		slog.Error(Name, "activate", fmt.Sprintf("unknown action: %s", action))
-		return
+		return fmt.Errorf("unknown action: %s", action)
 	}
+
+	return nil
 }
 
 func saveToHistory(query, result string) {
@@ -199,7 +225,11 @@ func saveToHistory(query, result string) {
 	saveHist()
 }
 
-func Query(conn net.Conn, query string, single bool, _ bool, format uint8) []*pb.QueryResponse_Item {
+func Query(conn net.Conn, query string, single bool, exact bool, format uint8) []*pb.QueryResponse_Item {
+	return QueryContext(context.Background(), conn, query, single, exact, format)
+}
+
+func QueryContext(ctx context.Context, conn net.Conn, query string, single bool, _ bool, format uint8) []*pb.QueryResponse_Item {
 	start := time.Now()
 
 	entries := []*pb.QueryResponse_Item{}
@@ -245,7 +275,7 @@ func Query(conn net.Conn, query string, single bool, _ bool, format uint8) []*pb
 				if err == nil {
 					e.Text = strings.TrimSpace(string(out))
 				} else {
-					slog.Error(Name, "qalc", err, "out", out)
+					logger.Error(Name, "qalc", err, "out", out)
 					e.Text = "%DELETE%"
 				}
 
@@ -258,7 +288,7 @@ func Query(conn net.Conn, query string, single bool, _ bool, format uint8) []*pb
 
 			entries = append(entries, e)
 		} else {
-			cmd := exec.Command("qalc", "-t", query)
+			cmd := exec.CommandContext(ctx, "qalc", "-t", query)
 
 			out, err := cmd.Output()
 			if err == nil {
@@ -291,7 +321,7 @@ func Query(conn net.Conn, query string, single bool, _ bool, format uint8) []*pb
 		}
 	}
 
-	slog.Debug(Name, "query", time.Since(start))
+	logger.Debug(Name, "query", time.Since(start))
 
 	return entries
 }
@@ -302,13 +332,13 @@ func loadHist() {
 	if common.FileExists(file) {
 		f, err := os.ReadFile(file)
 		if err != nil {
-			slog.Error(Name, "history", err)
+			logger.Error(Name, "history", err)
 		} else {
 			decoder := gob.NewDecoder(bytes.NewReader(f))
 
 			err = decoder.Decode(&history)
 			if err != nil {
-				slog.Error(Name, "decoding", err)
+				logger.Error(Name, "decoding", err)
 			}
 		}
 	}
@@ -324,19 +354,19 @@ func saveHist() {
 
 	err := encoder.Encode(history)
 	if err != nil {
-		slog.Error("history", "encode", err)
+		logger.Error("history", "encode", err)
 		return
 	}
 
 	err = os.MkdirAll(filepath.Dir(common.CacheFile(fmt.Sprintf("%s.gob", Name))), 0o755)
 	if err != nil {
-		slog.Error("history", "createdirs", err)
+		logger.Error("history", "createdirs", err)
 		return
 	}
 
 	err = os.WriteFile(common.CacheFile(fmt.Sprintf("%s.gob", Name)), b.Bytes(), 0o600)
 	if err != nil {
-		slog.Error("history", "writefile", err)
+		logger.Error("history", "writefile", err)
 	}
 }
 
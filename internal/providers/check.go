@@ -0,0 +1,43 @@
+package providers
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/abenz1267/elephant/v2/pkg/common"
+)
+
+// CheckConfigs checks the config of the given provider, or of elephant, the
+// menus and all providers if empty. Providers are expected to be loaded
+// without setup and common.EnableConfigCheck to be called before.
+func CheckConfigs(provider string) []common.ConfigIssue {
+	res := []common.ConfigIssue{}
+
+	if provider == "" || provider == "elephant" {
+		res = append(res, common.CheckConfig("elephant")...)
+	}
+
+	if provider == "" || provider == "menus" {
+		res = append(res, common.CheckConfig("menus")...)
+		res = append(res, common.CheckMenus()...)
+	}
+
+	for _, v := range slices.Sorted(maps.Keys(Providers)) {
+		if v == "menus" || (provider != "" && provider != v) {
+			continue
+		}
+
+		p := Providers[v]
+		p.LoadConfig()
+
+		res = append(res, common.CheckConfig(v)...)
+
+		// cross-field checks need the decoded config, unknown keys don't
+		// prevent that.
+		if p.CheckConfig != nil && common.ValidateConfig(v) == nil {
+			res = append(res, p.CheckConfig()...)
+		}
+	}
+
+	return res
+}
@@ -1,5 +1,5 @@
 // Package clipboard provides access to the clipboard history.
-package main
+package clipboard
 
 import (
 	"bufio"
@@ -9,6 +9,7 @@ import (
 	"encoding/gob"
 	"encoding/hex"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"log"
 	"log/slog"
@@ -20,6 +21,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"syscall"
 	"time"
 	"unicode/utf8"
@@ -32,6 +34,7 @@ import (
 var (
 	Name             = "clipboard"
 	NamePretty       = "Clipboard"
+	logger           = slog.Default()
 	file             = common.CacheFile("clipboard.gob")
 	imgTypes         = make(map[string]string)
 	config           *Config
@@ -44,6 +47,32 @@ var (
 	hasLocalsend     bool
 )
 
+var Requirements = []common.Requirement{
+	{Name: "wl-clipboard", Executables: []string{"wl-paste"}},
+	{Name: "imagemagick", Executables: []string{"identify"}},
+	{Name: "localsend", Executables: []string{"localsend"}, Optional: true},
+}
+
+var Manifest = common.Manifest{
+	ABI:                  common.ABIVersion,
+	Name:                 Name,
+	NamePretty:           &NamePretty,
+	Available:            Available,
+	Setup:                Setup,
+	LoadConfig:           LoadConfig,
+	PrintDoc:             PrintDoc,
+	Icon:                 Icon,
+	HideFromProviderlist: HideFromProviderlist,
+	State:                State,
+	Activate:             Activate,
+	Query:                Query,
+	Requirements:         Requirements,
+	Shutdown:             Shutdown,
+	Metrics:              Metrics,
+	ActivateErr:          ActivateErr,
+	Logger:               &logger,
+}
+
 //go:embed README.md
 var readme string
 
@@ -54,8 +83,11 @@ var unicodedata string
 var symbolsdata string
 
 var (
-	paused       bool
-	saveFileChan = make(chan struct{})
+	paused        bool
+	saveFileChan  = make(chan struct{})
+	flushFileChan = make(chan chan struct{})
+	// saving is set once handleSaveToFile runs, which reads flushFileChan.
+	saving atomic.Bool
 )
 
 const StateEditable = "editable"
@@ -84,10 +116,6 @@ func Setup() {
 
 	LoadConfig()
 
-	if config.NamePretty != "" {
-		NamePretty = config.NamePretty
-	}
-
 	imgTypes["image/png"] = "png"
 	imgTypes["image/jpg"] = "jpg"
 	imgTypes["image/jpeg"] = "jpeg"
@@ -101,6 +129,8 @@ func Setup() {
 	loadFromFile()
 
 	go handleChange()
+
+	saving.Store(true)
 	go handleSaveToFile()
 
 	if config.IgnoreSymbols {
@@ -119,11 +149,11 @@ func Setup() {
 		}
 	}
 
-	slog.Info(Name, "history", len(clipboardhistory), "time", time.Since(start))
+	logger.Info(Name, "history", len(clipboardhistory), "time", time.Since(start))
 }
 
 func LoadConfig() {
-	config = &Config{
+	cfg := &Config{
 		Config: common.Config{
 			Icon:     "user-bookmarks",
 			MinScore: 30,
@@ -136,23 +166,17 @@ func LoadConfig() {
 		AutoCleanup:    0,
 	}
 
-	common.LoadConfig(Name, config)
-}
+	common.LoadConfig(Name, cfg)
 
-func Available() bool {
-	p, err := exec.LookPath("wl-paste")
-	if p == "" || err != nil {
-		slog.Info(Name, "available", "wl-clipboard not found. disabling")
-		return false
+	if cfg.NamePretty != "" {
+		NamePretty = cfg.NamePretty
 	}
 
-	p, err = exec.LookPath("identify")
-	if p == "" || err != nil {
-		slog.Info(Name, "available", "imagemagick not found. disabling")
-		return false
-	}
+	config = cfg
+}
 
-	return true
+func Available() bool {
+	return common.RequirementsMet(Name, Requirements)
 }
 
 func cleanup() {
@@ -172,7 +196,7 @@ func cleanup() {
 
 		if i != 0 {
 			saveToFile()
-			slog.Info(Name, "cleanup", i)
+			logger.Info(Name, "cleanup", i)
 		}
 	}
 }
@@ -224,7 +248,7 @@ func setupUnicodeSymbols() {
 
 		codePoint, err := strconv.ParseInt(fields[0], 16, 32)
 		if err != nil {
-			slog.Error(Name, "activate parse unicode", err)
+			logger.Error(Name, "activate parse unicode", err)
 			return
 		}
 
@@ -255,13 +279,13 @@ func loadFromFile() {
 	if common.FileExists(file) {
 		f, err := os.ReadFile(file)
 		if err != nil {
-			slog.Error("history", "load", err)
+			logger.Error("history", "load", err)
 		} else {
 			decoder := gob.NewDecoder(bytes.NewReader(f))
 
 			err = decoder.Decode(&clipboardhistory)
 			if err != nil {
-				slog.Error("history", "decoding", err)
+				logger.Error("history", "decoding", err)
 			}
 		}
 	}
@@ -277,19 +301,19 @@ func saveToFile() {
 
 	err := encoder.Encode(clipboardhistory)
 	if err != nil {
-		slog.Error(Name, "encode", err)
+		logger.Error(Name, "encode", err)
 		return
 	}
 
 	err = os.MkdirAll(filepath.Dir(file), 0o755)
 	if err != nil {
-		slog.Error(Name, "createdirs", err)
+		logger.Error(Name, "createdirs", err)
 		return
 	}
 
 	err = os.WriteFile(file, b.Bytes(), 0o600)
 	if err != nil {
-		slog.Error(Name, "writefile", err)
+		logger.Error(Name, "writefile", err)
 	}
 }
 
@@ -339,7 +363,7 @@ func getClipboardImage() ([]byte, error) {
 	cmd := exec.Command("wl-paste", "-t", "image", "-n")
 	out, err := cmd.CombinedOutput()
 	if err != nil {
-		slog.Debug(Name, "get clipboard img", string(out))
+		logger.Debug(Name, "get clipboard img", string(out))
 	}
 
 	return out, err
@@ -349,7 +373,7 @@ func getClipboardText() (string, error) {
 	cmd := exec.Command("wl-paste", "-t", "text", "-n")
 	out, err := cmd.CombinedOutput()
 	if err != nil {
-		slog.Debug(Name, "get clipboard text", string(out))
+		logger.Debug(Name, "get clipboard text", string(out))
 	}
 
 	return string(out), err
@@ -371,10 +395,28 @@ func handleSaveToFile() {
 				saveToFile()
 				do = false
 			}
+		case done := <-flushFileChan:
+			if do {
+				saveToFile()
+				do = false
+			}
+
+			close(done)
 		}
 	}
 }
 
+// Shutdown writes pending history changes.
+func Shutdown() {
+	if !saving.Load() {
+		return
+	}
+
+	done := make(chan struct{})
+	flushFileChan <- done
+	<-done
+}
+
 func updateImage(out []byte) {
 	mt := getMimetypes()
 
@@ -399,7 +441,7 @@ func updateImage(out []byte) {
 
 		res, err := cmd.CombinedOutput()
 		if err != nil {
-			slog.Error(Name, "update image", err, "msg", res)
+			logger.Error(Name, "update image", err, "msg", res)
 			return
 		}
 
@@ -474,7 +516,7 @@ func updateText(text string) bool {
 		val.Time = time.Now()
 	} else {
 		if !utf8.Valid(b) {
-			slog.Error(Name, "updating", "string content contains invalid UTF-8")
+			logger.Error(Name, "updating", "string content contains invalid UTF-8")
 		}
 
 		if isURIList {
@@ -529,7 +571,7 @@ func saveImg(b []byte, ext string) string {
 
 	_, err = outfile.Write(b)
 	if err != nil {
-		slog.Error("clipboard", "writeimage", err)
+		logger.Error("clipboard", "writeimage", err)
 		return ""
 	}
 
@@ -565,10 +607,27 @@ const (
 )
 
 func Activate(single bool, identifier, action string, query string, args string, format uint8, conn net.Conn) {
+	if err := ActivateErr(single, identifier, action, query, args, format, conn); err != nil {
+		logger.Error(Name, "activate", err)
+	}
+}
+
+func ActivateErr(single bool, identifier, action string, query string, args string, format uint8, conn net.Conn) error {
 	if action == "" {
 		action = ActionCopy
 	}
 
+	switch action {
+	case ActionLocalsend, ActionEdit, ActionCopy:
+		mu.Lock()
+		_, ok := clipboardhistory[identifier]
+		mu.Unlock()
+
+		if !ok {
+			return fmt.Errorf("unknown item: %s", identifier)
+		}
+	}
+
 	switch action {
 	case ActionLocalsend:
 		item := clipboardhistory[identifier]
@@ -580,12 +639,14 @@ func Activate(single bool, identifier, action string, query string, args string,
 		} else {
 			f, err := os.CreateTemp(os.TempDir(), "clipboard_*.txt")
 			if err != nil {
-				slog.Error(Name, "actionlocalsend", err)
+				return fmt.Errorf("localsend: %w", err)
 			}
 
 			_, err = f.WriteString(item.Content)
+			f.Close()
+
 			if err != nil {
-				slog.Error(Name, "actionlocalsend", err)
+				return fmt.Errorf("localsend: %w", err)
 			}
 
 			path = f.Name()
@@ -597,14 +658,13 @@ func Activate(single bool, identifier, action string, query string, args string,
 			Setsid: true,
 		}
 
-		err := cmd.Start()
-		if err != nil {
-			slog.Error(Name, "actionlocalsend", err)
-		} else {
-			go func() {
-				cmd.Wait()
-			}()
+		if err := cmd.Start(); err != nil {
+			return fmt.Errorf("localsend: %w", err)
 		}
+
+		go func() {
+			cmd.Wait()
+		}()
 	case ActionPause:
 		paused = true
 	case ActionUnpause:
@@ -621,36 +681,32 @@ func Activate(single bool, identifier, action string, query string, args string,
 	case ActionEdit:
 		item := clipboardhistory[identifier]
 		if item.State != StateEditable {
-			return
+			return fmt.Errorf("item is not editable: %s", identifier)
 		}
 
 		if item.Img != "" {
 			if config.ImageEditorCmd == "" {
-				slog.Info(Name, "edit", "image_editor not set")
-				return
+				return errors.New("image_editor not set")
 			}
 
 			toRun := strings.ReplaceAll(config.ImageEditorCmd, "%FILE%", item.Img)
 
 			cmd := exec.Command("sh", "-c", toRun)
 
-			err := cmd.Start()
-			if err != nil {
-				slog.Error(Name, "openedit", err)
-				return
-			} else {
-				go func() {
-					cmd.Wait()
-				}()
+			if err := cmd.Start(); err != nil {
+				return fmt.Errorf("edit: %w", err)
 			}
 
-			return
+			go func() {
+				cmd.Wait()
+			}()
+
+			return nil
 		}
 
 		tmpFile, err := os.CreateTemp("", "*.txt")
 		if err != nil {
-			slog.Error(Name, "edit", err)
-			return
+			return fmt.Errorf("edit: %w", err)
 		}
 
 		tmpFile.Write([]byte(item.Content))
@@ -668,17 +724,15 @@ func Activate(single bool, identifier, action string, query string, args string,
 		}
 
 		cmd := exec.Command("sh", "-c", run)
-		err = cmd.Start()
-		if err != nil {
-			slog.Error(Name, "openedit", err)
-			return
-		} else {
-			cmd.Wait()
-
-			b, _ := os.ReadFile(tmpFile.Name())
-			item.Content = string(b)
-			saveToFile()
+		if err := cmd.Start(); err != nil {
+			return fmt.Errorf("edit: %w", err)
 		}
+
+		cmd.Wait()
+
+		b, _ := os.ReadFile(tmpFile.Name())
+		item.Content = string(b)
+		saveToFile()
 	case ActionRemove:
 		mu.Lock()
 
@@ -773,19 +827,18 @@ func Activate(single bool, identifier, action string, query string, args string,
 			}
 		}
 
-		err := cmd.Start()
-		if err != nil {
-			slog.Error("clipboard", "activate", err)
-			return
-		} else {
-			go func() {
-				cmd.Wait()
-			}()
+		if err := cmd.Start(); err != nil {
+			return fmt.Errorf("copy: %w", err)
 		}
+
+		go func() {
+			cmd.Wait()
+		}()
 	default:
-		slog.Error(Name, "activate", fmt.Sprintf("unknown action: %s", action))
-		return
+		return fmt.Errorf("unknown action: %s", action)
 	}
+
+	return nil
 }
 
 func Query(conn net.Conn, query string, _ bool, exact bool, _ uint8) []*pb.QueryResponse_Item {
@@ -954,3 +1007,16 @@ func State(provider string) *pb.ProviderStateResponse {
 		Actions: actions,
 	}
 }
+
+func Metrics() []common.Metric {
+	mu.Lock()
+	defer mu.Unlock()
+
+	return []common.Metric{
+		{
+			Name:  "clipboard_history_entries",
+			Help:  "entries in the clipboard history",
+			Value: float64(len(clipboardhistory)),
+		},
+	}
+}
@@ -0,0 +1,41 @@
+package clipboard
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/abenz1267/elephant/v2/pkg/common"
+)
+
+func TestLoadConfigExpandsImageEditorCmd(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Setenv("HOME", dir)
+	t.Setenv("ELEPHANT_TEST_EDITOR", "swappy")
+
+	cfg := "image_editor_cmd = \"${ELEPHANT_TEST_EDITOR} -f %FILE%\"\ntext_editor_cmd = \"~/bin/edit %FILE%\"\ncommand = \"${ELEPHANT_TEST_UNSET:-wl-copy}\"\n"
+
+	if err := os.WriteFile(filepath.Join(dir, "clipboard.toml"), []byte(cfg), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	common.SetExplicitDir(dir)
+	defer common.SetExplicitDir("")
+
+	LoadConfig()
+
+	if config.ImageEditorCmd != "swappy -f %FILE%" {
+		t.Errorf("image_editor_cmd = %q, expected %q", config.ImageEditorCmd, "swappy -f %FILE%")
+	}
+
+	expected := filepath.Join(dir, "bin/edit %FILE%")
+
+	if config.TextEditorCmd != expected {
+		t.Errorf("text_editor_cmd = %q, expected %q", config.TextEditorCmd, expected)
+	}
+
+	if config.Command != "wl-copy" {
+		t.Errorf("command = %q, expected %q", config.Command, "wl-copy")
+	}
+}
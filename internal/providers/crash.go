@@ -0,0 +1,147 @@
+package providers
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/abenz1267/elephant/v2/pkg/common"
+)
+
+// ErrSuspended is returned for calls of a provider that is disabled after
+// repeated crashes.
+var ErrSuspended = errors.New("disabled after repeated crashes")
+
+// PanicError is returned for calls of a provider that panicked.
+type PanicError struct {
+	Provider string
+	Value    any
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("provider crashed: %v", e.Value)
+}
+
+// Crash holds the crashes of a provider.
+type Crash struct {
+	Count int
+	// Until is the time the provider is disabled until, zero if it isn't.
+	Until time.Time
+	// recent holds the crashes within the crash window.
+	recent []time.Time
+}
+
+var (
+	crashes  = make(map[string]*Crash)
+	crashMut sync.Mutex
+)
+
+func init() {
+	common.RecoverMenu = func(f func()) {
+		name := "menus"
+		Provider{Name: &name}.Recover(f)
+	}
+}
+
+// Recover runs f, a call of the provider, returning a panic as *PanicError.
+// Crashes are counted and disable the provider once they reach crash_limit.
+func (p Provider) Recover(f func()) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			slog.Error(*p.Name, "panic", r, "stack", string(debug.Stack()))
+
+			crashed(*p.Name)
+
+			err = &PanicError{Provider: *p.Name, Value: r}
+		}
+	}()
+
+	f()
+
+	return nil
+}
+
+// suspended returns ErrSuspended if the provider is disabled.
+func (p Provider) suspended() error {
+	if until, ok := Suspended(*p.Name); ok {
+		return fmt.Errorf("%w until %s", ErrSuspended, until.Format(time.TimeOnly))
+	}
+
+	return nil
+}
+
+func crashed(provider string) {
+	now := time.Now()
+	limit, window, cooldown := 0, time.Duration(0), time.Duration(0)
+
+	if cfg := common.GetElephantConfig(); cfg != nil {
+		limit = cfg.CrashLimit
+		window = time.Duration(cfg.CrashWindow) * time.Millisecond
+		cooldown = time.Duration(cfg.CrashCooldown) * time.Millisecond
+	}
+
+	crashMut.Lock()
+	defer crashMut.Unlock()
+
+	c, ok := crashes[provider]
+	if !ok {
+		c = &Crash{}
+		crashes[provider] = c
+	}
+
+	c.Count++
+	c.recent = slices.DeleteFunc(c.recent, func(t time.Time) bool {
+		return now.Sub(t) > window
+	})
+	c.recent = append(c.recent, now)
+
+	if limit > 0 && len(c.recent) >= limit {
+		c.Until = now.Add(cooldown)
+		c.recent = nil
+
+		slog.Error(provider, "disabled", fmt.Sprintf("%d crashes within %s", limit, window), "until", c.Until.Format(time.TimeOnly))
+	}
+}
+
+// Suspended reports whether the provider is disabled after repeated crashes,
+// and until when.
+func Suspended(provider string) (time.Time, bool) {
+	crashMut.Lock()
+	defer crashMut.Unlock()
+
+	c, ok := crashes[provider]
+	if !ok || !time.Now().Before(c.Until) {
+		return time.Time{}, false
+	}
+
+	return c.Until, true
+}
+
+// Resume enables a provider disabled after repeated crashes.
+func Resume(provider string) {
+	crashMut.Lock()
+	defer crashMut.Unlock()
+
+	if c, ok := crashes[provider]; ok {
+		c.Until = time.Time{}
+		c.recent = nil
+	}
+}
+
+// Crashes returns the crashes of all providers that crashed.
+func Crashes() map[string]Crash {
+	crashMut.Lock()
+	defer crashMut.Unlock()
+
+	res := make(map[string]Crash, len(crashes))
+
+	for k, v := range crashes {
+		res[k] = Crash{Count: v.Count, Until: v.Until}
+	}
+
+	return res
+}
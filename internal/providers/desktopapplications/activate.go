@@ -1,10 +1,9 @@
-package main
+package desktopapplications
 
 import (
 	"bytes"
 	"encoding/gob"
 	"fmt"
-	"log/slog"
 	"net"
 	"os"
 	"os/exec"
@@ -29,6 +28,12 @@ const (
 )
 
 func Activate(single bool, identifier, action string, query string, args string, format uint8, conn net.Conn) {
+	if err := ActivateErr(single, identifier, action, query, args, format, conn); err != nil {
+		logger.Error(Name, "activate", err)
+	}
+}
+
+func ActivateErr(single bool, identifier, action string, query string, args string, format uint8, conn net.Conn) error {
 	switch action {
 	case ActionPinUp:
 		movePin(identifier, false)
@@ -36,20 +41,28 @@ func Activate(single bool, identifier, action string, query string, args string,
 		movePin(identifier, true)
 	case ActionPin, ActionUnpin:
 		pinItem(identifier)
-		return
+		return nil
 	case history.ActionDelete:
 		h.Remove(identifier)
-		return
+		return nil
 	case ActionStart, ActionNewInstance:
 		toRun := ""
 		prefix := common.LaunchPrefix()
 
 		parts := strings.Split(identifier, ":")
 
+		filesMu.RLock()
+		file, ok := files[parts[0]]
+		filesMu.RUnlock()
+
+		if !ok {
+			return fmt.Errorf("unknown application: %s", parts[0])
+		}
+
 		isAction := false
 
 		if len(parts) == 2 {
-			for _, v := range files[parts[0]].Actions {
+			for _, v := range file.Actions {
 				if v.Action == parts[1] {
 					toRun = v.Exec
 					isAction = true
@@ -57,34 +70,34 @@ func Activate(single bool, identifier, action string, query string, args string,
 				}
 			}
 		} else {
-			toRun = files[parts[0]].Exec
+			toRun = file.Exec
 		}
 
 		if args == "" && config.WindowIntegration && wlr.IsSetup && action != ActionNewInstance {
 			if !isAction || !config.WindowIntegrationIgnoreActions {
-				if id, ok := appHasWindow(files[parts[0]]); ok {
+				if id, ok := appHasWindow(file); ok {
 					if err := wlr.Activate(id); err == nil {
 
 						if config.History {
 							h.Save(query, identifier)
 						}
 
-						return
+						return nil
 					} else {
-						slog.Error(Name, "focus window", err)
+						logger.Error(Name, "focus window", err)
 					}
 				}
 			}
 		}
 
-		if files[parts[0]].Terminal {
+		if file.Terminal {
 			toRun = common.WrapWithTerminal(toRun)
 		}
 
 		cmd := exec.Command("sh", "-c", strings.TrimSpace(fmt.Sprintf("%s %s %s", prefix, toRun, args)))
 
-		if files[parts[0]].Path != "" {
-			cmd.Dir = files[parts[0]].Path
+		if file.Path != "" {
+			cmd.Dir = file.Path
 		}
 
 		cmd.SysProcAttr = &syscall.SysProcAttr{
@@ -92,34 +105,33 @@ func Activate(single bool, identifier, action string, query string, args string,
 		}
 
 		if config.WMIntegration && wmi != nil {
-			appid := files[parts[0]].StartupWMClass
+			appid := file.StartupWMClass
 
 			if !slices.Contains(config.SingleInstanceApps, appid) || !slices.Contains(wmi.GetCurrentWindows(), appid) {
 				go wmi.MoveToWorkspace(wmi.GetWorkspace(), appid)
 			}
 		}
 
-		slog.Debug(Name, "activate", cmd.String())
+		logger.Debug(Name, "activate", cmd.String())
 
-		err := cmd.Start()
-		if err != nil {
-			slog.Error(Name, "activate", identifier, "error", err)
-			return
-		} else {
-			go func() {
-				cmd.Wait()
-			}()
+		if err := cmd.Start(); err != nil {
+			return fmt.Errorf("%s: %w", identifier, err)
 		}
 
+		go func() {
+			cmd.Wait()
+		}()
+
 		if config.History {
 			h.Save(query, identifier)
 		}
 
-		slog.Info(Name, "activated", identifier)
+		logger.Info(Name, "activated", identifier)
 	default:
-		slog.Error(Name, "activate", fmt.Sprintf("unknown action: %s", action))
-		return
+		return fmt.Errorf("unknown action: %s", action)
 	}
+
+	return nil
 }
 
 func movePin(identifier string, down bool) {
@@ -170,19 +182,19 @@ func pinItem(identifier string) {
 
 	err := encoder.Encode(pins)
 	if err != nil {
-		slog.Error("pinned", "encode", err)
+		logger.Error("pinned", "encode", err)
 		return
 	}
 
 	err = os.MkdirAll(filepath.Dir(common.CacheFile(fmt.Sprintf("%s_pinned.gob", Name))), 0o755)
 	if err != nil {
-		slog.Error("pinned", "createdirs", err)
+		logger.Error("pinned", "createdirs", err)
 		return
 	}
 
 	err = os.WriteFile(common.CacheFile(fmt.Sprintf("%s_pinned.gob", Name)), b.Bytes(), 0o600)
 	if err != nil {
-		slog.Error("pinned", "writefile", err)
+		logger.Error("pinned", "writefile", err)
 	}
 }
 
@@ -1,4 +1,4 @@
-package main
+package desktopapplications
 
 import (
 	"fmt"
@@ -1,8 +1,7 @@
-package main
+package desktopapplications
 
 import (
 	"io/fs"
-	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
@@ -39,7 +38,7 @@ func loadFiles() {
 	var err error
 	watcher, err = fsnotify.NewWatcher()
 	if err != nil {
-		slog.Error(Name, "watcher_init", err)
+		logger.Error(Name, "watcher_init", err)
 		return
 	}
 
@@ -49,7 +48,7 @@ func loadFiles() {
 		}
 
 		if err := fastwalk.Walk(&conf, root, walkFunction); err != nil {
-			slog.Error(Name, "walk", err)
+			logger.Error(Name, "walk", err)
 			continue
 		}
 	}
@@ -58,16 +57,16 @@ func loadFiles() {
 		// With moss package manager, /usr is atomically replaced on package changes.
 		// Watch it directly so we can detect the swap and reinitialize inotify watches.
 		if err := watcher.Add("/usr"); err != nil {
-			slog.Warn(Name, "usr_watcher_add", err)
+			logger.Warn(Name, "usr_watcher_add", err)
 		}
 	}
 
 	fileCount := len(files)
-	slog.Info(Name, "files", fileCount, "time", time.Since(start))
+	logger.Info(Name, "files", fileCount, "time", time.Since(start))
 
-	slog.Info(Name, "watcher_dirs", len(watchedDirs))
+	logger.Info(Name, "watcher_dirs", len(watchedDirs))
 	go watchFiles()
-	slog.Info(Name, "watcher", "started")
+	logger.Info(Name, "watcher", "started")
 }
 
 func setVars() {
@@ -133,7 +132,7 @@ func trackSymlinks(filename string) {
 
 	addDirToWatcher(filepath.Dir(targetPath), watchedDirs)
 
-	slog.Debug(Name, "symlink_tracked", filename, "target", targetPath)
+	logger.Debug(Name, "symlink_tracked", filename, "target", targetPath)
 }
 
 func addDirToWatcher(dir string, watchedDirs map[string]bool) {
@@ -144,7 +143,7 @@ func addDirToWatcher(dir string, watchedDirs map[string]bool) {
 	}
 
 	if err := watcher.Add(dir); err != nil {
-		slog.Warn(Name, "watcher_add", err, "dir", dir)
+		logger.Warn(Name, "watcher_add", err, "dir", dir)
 		return
 	}
 
@@ -169,7 +168,7 @@ func watchFiles() {
 			if !ok {
 				return
 			}
-			slog.Error(Name, "watcher", err)
+			logger.Error(Name, "watcher", err)
 		}
 	}
 }
@@ -187,12 +186,12 @@ func handleFileEvent(event fsnotify.Event) {
 	// moss replaces /usr atomically, which invalidates all inotify watches under it.
 	// Detect this and reinitialize.
 	if event.Name == "/usr" && (event.Op&fsnotify.Rename != 0 || event.Op&fsnotify.Remove != 0) {
-		slog.Info(Name, "usr_replaced", "reinitializing inotify watches")
+		logger.Info(Name, "usr_replaced", "reinitializing inotify watches")
 		reinitializeWatcher()
 		return
 	}
 
-	slog.Debug(Name, "file_system_event", event)
+	logger.Debug(Name, "file_system_event", event)
 	if filepath.Ext(event.Name) != ".desktop" {
 		// Handle directory creation to watch new subdirectories
 
@@ -205,7 +204,7 @@ func handleFileEvent(event fsnotify.Event) {
 				}
 
 				if err := watcher.Add(event.Name); err != nil {
-					slog.Warn(Name, "watcher_add_new", err, "dir", event.Name)
+					logger.Warn(Name, "watcher_add_new", err, "dir", event.Name)
 				}
 			}
 		}
@@ -229,7 +228,7 @@ func handleFileEvent(event fsnotify.Event) {
 func handleFileCreate(path string) {
 	clone := realToSymlink[path]
 	_, sym := isSymlink(path)
-	defer slog.Debug(Name, "file_created", path)
+	defer logger.Debug(Name, "file_created", path)
 	if !sym {
 		if clone != nil {
 			for _, symedFile := range clone {
@@ -248,7 +247,7 @@ func handleFileCreate(path string) {
 func handleFileUpdate(path string) {
 	clone := realToSymlink[path]
 
-	defer slog.Debug(Name, "file_updated", path)
+	defer logger.Debug(Name, "file_updated", path)
 
 	_, sym := isSymlink(path)
 	if !sym {
@@ -267,7 +266,7 @@ func handleFileUpdate(path string) {
 
 func handleFileRemove(path string) {
 	originPath, sym := isSymlink(path)
-	defer slog.Debug(Name, "file_removed", path)
+	defer logger.Debug(Name, "file_removed", path)
 
 	filesMu.Lock()
 	delete(files, filepath.Base(path))
@@ -307,7 +306,7 @@ func addNewEntry(path string) {
 	if f, err := parseFile(path, langLocale, regionLocale); err == nil {
 		files[filepath.Base(path)] = f
 	} else {
-		slog.Error(Name, "parsing", err)
+		logger.Error(Name, "parsing", err)
 	}
 	filesMu.Unlock()
 }
@@ -375,5 +374,5 @@ func reinitializeWatcher() {
 
 	loadFiles()
 	handlers.ProviderUpdated <- Name
-	slog.Info(Name, "watcher_reinitialized", len(files))
+	logger.Info(Name, "watcher_reinitialized", len(files))
 }
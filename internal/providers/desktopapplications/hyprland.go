@@ -1,10 +1,9 @@
-package main
+package desktopapplications
 
 import (
 	"bufio"
 	"context"
 	"fmt"
-	"log/slog"
 	"net"
 	"os"
 	"os/exec"
@@ -23,7 +22,7 @@ func (Hyprland) GetWorkspace() string {
 
 	out, err := cmd.CombinedOutput()
 	if err != nil {
-		slog.Error(Name, "hyprlandworkspaces", err)
+		logger.Error(Name, "hyprlandworkspaces", err)
 		return ""
 	}
 
@@ -40,7 +39,7 @@ func (c Hyprland) MoveToWorkspace(workspace, initialWMClass string) {
 	instanceSignature := os.Getenv("HYPRLAND_INSTANCE_SIGNATURE")
 
 	if runtimeDir == "" || instanceSignature == "" {
-		slog.Error(Name, "hyprlandmovetoworkspace", "XDG_RUNTIME_DIR or HYPRLAND_INSTANCE_SIGNATURE missing")
+		logger.Error(Name, "hyprlandmovetoworkspace", "XDG_RUNTIME_DIR or HYPRLAND_INSTANCE_SIGNATURE missing")
 		return
 	}
 
@@ -48,7 +47,7 @@ func (c Hyprland) MoveToWorkspace(workspace, initialWMClass string) {
 
 	conn, err := net.Dial("unix", socket)
 	if err != nil {
-		slog.Error(Name, "unix socket", err)
+		logger.Error(Name, "unix socket", err)
 		return
 	}
 	defer conn.Close()
@@ -69,7 +68,7 @@ func (c Hyprland) MoveToWorkspace(workspace, initialWMClass string) {
 
 					out, err := cmd.CombinedOutput()
 					if err != nil {
-						slog.Error(Name, "movetoworkspace", out)
+						logger.Error(Name, "movetoworkspace", out)
 					}
 
 					return
@@ -78,7 +77,7 @@ func (c Hyprland) MoveToWorkspace(workspace, initialWMClass string) {
 		}
 
 		if err := scanner.Err(); err != nil {
-			slog.Error(Name, "monitor", err)
+			logger.Error(Name, "monitor", err)
 		}
 	}()
 
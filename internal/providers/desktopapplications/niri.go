@@ -1,11 +1,10 @@
-package main
+package desktopapplications
 
 import (
 	"bufio"
 	"context"
 	"encoding/json"
 	"fmt"
-	"log/slog"
 	"os/exec"
 	"strings"
 	"time"
@@ -24,7 +23,7 @@ func (Niri) GetCurrentWindows() []string {
 
 	out, err := cmd.CombinedOutput()
 	if err != nil {
-		slog.Error(Name, "nirigetcurrentwindows", err)
+		logger.Error(Name, "nirigetcurrentwindows", err)
 		return res
 	}
 
@@ -32,7 +31,7 @@ func (Niri) GetCurrentWindows() []string {
 
 	err = json.Unmarshal(out, &windows)
 	if err != nil {
-		slog.Error(Name, "nirigetcurrentwindows", err)
+		logger.Error(Name, "nirigetcurrentwindows", err)
 		return res
 	}
 
@@ -48,7 +47,7 @@ func (Niri) GetWorkspace() string {
 
 	out, err := cmd.CombinedOutput()
 	if err != nil {
-		slog.Error(Name, "niriworkspaces", err)
+		logger.Error(Name, "niriworkspaces", err)
 		return ""
 	}
 
@@ -83,12 +82,12 @@ func (c Niri) MoveToWorkspace(workspace, initialWMClass string) {
 
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
-		slog.Error(Name, "monitor", err)
+		logger.Error(Name, "monitor", err)
 		return
 	}
 
 	if err := cmd.Start(); err != nil {
-		slog.Error(Name, "monitor", err)
+		logger.Error(Name, "monitor", err)
 		return
 	}
 
@@ -103,7 +102,7 @@ func (c Niri) MoveToWorkspace(workspace, initialWMClass string) {
 
 			err := json.Unmarshal(scanner.Bytes(), &e)
 			if err != nil {
-				slog.Error(Name, "event unmarshal", err)
+				logger.Error(Name, "event unmarshal", err)
 				continue
 			}
 
@@ -115,7 +114,7 @@ func (c Niri) MoveToWorkspace(workspace, initialWMClass string) {
 				cmd := exec.Command("niri", "msg", "action", "move-window-to-workspace", workspace, "--window-id", fmt.Sprintf("%d", e.WindowOpenedOrChanged.Window.ID), "--focus", "false")
 				out, err := cmd.CombinedOutput()
 				if err != nil {
-					slog.Error(Name, "nirimovetoworkspace", out)
+					logger.Error(Name, "nirimovetoworkspace", out)
 				}
 
 				continue
@@ -1,10 +1,9 @@
-package main
+package desktopapplications
 
 import (
 	"bytes"
 	"errors"
 	"fmt"
-	"log/slog"
 	"os"
 	"slices"
 	"strings"
@@ -31,11 +30,11 @@ type Data struct {
 }
 
 func parseFile(path, l, ll string) (*DesktopFile, error) {
-	slog.Debug(Name, "parse", path)
+	logger.Debug(Name, "parse", path)
 
 	data, err := os.ReadFile(path)
 	if err != nil {
-		slog.Error(Name, "parseFile", err)
+		logger.Error(Name, "parseFile", err)
 		os.Exit(1)
 	}
 
@@ -166,7 +165,7 @@ func parseData(in []byte, l, ll string) Data {
 		case bytes.HasPrefix(line, []byte("Exec=")):
 			exec, err := parseExec(string(bytes.TrimPrefix(line, []byte("Exec="))))
 			if err != nil {
-				slog.Error(Name, "parsing", err)
+				logger.Error(Name, "parsing", err)
 			}
 
 			res.Exec = exec
@@ -0,0 +1,6 @@
+// Package main builds the desktopapplications provider as plugin.
+package main
+
+import "github.com/abenz1267/elephant/v2/internal/providers/desktopapplications"
+
+var Manifest = desktopapplications.Manifest
@@ -1,8 +1,7 @@
-package main
+package desktopapplications
 
 import (
 	"fmt"
-	"log/slog"
 	"net"
 	"os"
 	"slices"
@@ -283,7 +282,7 @@ func Query(conn net.Conn, query string, _ bool, exact bool, _ uint8) []*pb.Query
 
 	}
 
-	slog.Debug(Name, "query", time.Since(start))
+	logger.Debug(Name, "query", time.Since(start))
 
 	return entries
 }
@@ -0,0 +1,9 @@
+//go:build builtin || builtin_desktopapplications
+
+package desktopapplications
+
+import "github.com/abenz1267/elephant/v2/internal/providers"
+
+func init() {
+	providers.Register(&Manifest)
+}
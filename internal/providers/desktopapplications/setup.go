@@ -1,4 +1,4 @@
-package main
+package desktopapplications
 
 import (
 	"bytes"
@@ -26,6 +26,7 @@ type DesktopFile struct {
 var (
 	Name       = "desktopapplications"
 	NamePretty = "Desktop Applications"
+	logger     = slog.Default()
 	h          = history.Load(Name)
 	pins       = loadpinned()
 	pinsMu     sync.RWMutex
@@ -34,6 +35,23 @@ var (
 	wmi        WMIntegration
 )
 
+var Manifest = common.Manifest{
+	ABI:                  common.ABIVersion,
+	Name:                 Name,
+	NamePretty:           &NamePretty,
+	Available:            Available,
+	Setup:                Setup,
+	LoadConfig:           LoadConfig,
+	PrintDoc:             PrintDoc,
+	Icon:                 Icon,
+	HideFromProviderlist: HideFromProviderlist,
+	State:                State,
+	Activate:             Activate,
+	Query:                Query,
+	ActivateErr:          ActivateErr,
+	Logger:               &logger,
+}
+
 type WMIntegration interface {
 	GetWorkspace() string
 	GetCurrentWindows() []string
@@ -72,13 +90,13 @@ func loadpinned() []string {
 	if common.FileExists(file) {
 		f, err := os.ReadFile(file)
 		if err != nil {
-			slog.Error("pinned", "load", err)
+			logger.Error("pinned", "load", err)
 		} else {
 			decoder := gob.NewDecoder(bytes.NewReader(f))
 
 			err = decoder.Decode(&pinned)
 			if err != nil {
-				slog.Error("pinned", "decoding", err)
+				logger.Error("pinned", "decoding", err)
 			}
 		}
 	}
@@ -90,10 +108,6 @@ func Setup() {
 	start := time.Now()
 	LoadConfig()
 
-	if config.NamePretty != "" {
-		NamePretty = config.NamePretty
-	}
-
 	parseRegexp()
 	loadFiles()
 
@@ -112,11 +126,11 @@ func Setup() {
 		}
 	}
 
-	slog.Info(Name, "desktop files", len(files), "time", time.Since(start))
+	logger.Info(Name, "desktop files", len(files), "time", time.Since(start))
 }
 
 func LoadConfig() {
-	config = &Config{
+	cfg := &Config{
 		Config: common.Config{
 			Icon:     "applications-other",
 			MinScore: 30,
@@ -137,7 +151,13 @@ func LoadConfig() {
 		SingleInstanceApps:      []string{"discord"},
 	}
 
-	common.LoadConfig(Name, config)
+	common.LoadConfig(Name, cfg)
+
+	if cfg.NamePretty != "" {
+		NamePretty = cfg.NamePretty
+	}
+
+	config = cfg
 }
 
 func Available() bool {
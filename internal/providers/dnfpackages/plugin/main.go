@@ -0,0 +1,6 @@
+// Package main builds the dnfpackages provider as plugin.
+package main
+
+import "github.com/abenz1267/elephant/v2/internal/providers/dnfpackages"
+
+var Manifest = dnfpackages.Manifest
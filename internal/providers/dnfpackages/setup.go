@@ -1,11 +1,10 @@
-package main
+package dnfpackages
 
 import (
 	"bufio"
 	"fmt"
 	"log/slog"
 	"net"
-	"os"
 	"os/exec"
 	"strings"
 	"time"
@@ -30,12 +29,35 @@ type PackageDetail struct {
 var (
 	Name              = "dnfpackages"
 	NamePretty        = "DNF Packages"
+	logger            = slog.Default()
 	config            *Config
 	installedPackages = map[string]PackageDetail{}
 	allPackages       = map[string]PackageDetail{}
 	installedOnly     = false
 )
 
+var Requirements = []common.Requirement{
+	{Name: "dnf", Executables: []string{"dnf"}},
+}
+
+var Manifest = common.Manifest{
+	ABI:                  common.ABIVersion,
+	Name:                 Name,
+	NamePretty:           &NamePretty,
+	Available:            Available,
+	Setup:                Setup,
+	LoadConfig:           LoadConfig,
+	PrintDoc:             PrintDoc,
+	Icon:                 Icon,
+	HideFromProviderlist: HideFromProviderlist,
+	State:                State,
+	Activate:             Activate,
+	Query:                Query,
+	Requirements:         Requirements,
+	ActivateErr:          ActivateErr,
+	Logger:               &logger,
+}
+
 const (
 	ActionInstall       = "install"
 	ActionRemove        = "remove"
@@ -54,73 +76,85 @@ type Config struct {
 func Setup() {
 	LoadConfig()
 
-	if config.NamePretty != "" {
-		NamePretty = config.NamePretty
-	}
-
 	refresh()
 }
 
 func LoadConfig() {
-	config = &Config{
+	cfg := &Config{
 		Config: common.Config{
 			Icon:     "system-software-install",
 			MinScore: 20,
 		},
 	}
 
-	common.LoadConfig(Name, config)
+	common.LoadConfig(Name, cfg)
+
+	if cfg.NamePretty != "" {
+		NamePretty = cfg.NamePretty
+	}
+
+	config = cfg
 }
 
 func Activate(single bool, identifier, action string, query string, args string, format uint8, conn net.Conn) {
+	if err := ActivateErr(single, identifier, action, query, args, format, conn); err != nil {
+		logger.Error(Name, "activate", err)
+	}
+}
+
+func ActivateErr(single bool, identifier, action string, query string, args string, format uint8, conn net.Conn) error {
 	var pkgcmd string
 
 	switch action {
 	case ActionVisitURL:
-		p := allPackages[identifier]
+		p, ok := allPackages[identifier]
+		if !ok {
+			return fmt.Errorf("unknown package: %s", identifier)
+		}
+
 		run := strings.TrimSpace(fmt.Sprintf("%s xdg-open '%s'", common.LaunchPrefix(), p.URL))
 		cmd := exec.Command("sh", "-c", run)
 
-		err := cmd.Start()
-		if err != nil {
-			slog.Error(Name, "activate", err, "action", action)
-		} else {
-			go func() {
-				_ = cmd.Wait()
-			}()
+		if err := cmd.Start(); err != nil {
+			return err
 		}
 
-		return
+		go func() {
+			_ = cmd.Wait()
+		}()
+
+		return nil
 	case ActionShowAll:
 		installedOnly = false
-		return
+		return nil
 	case ActionShowInstalled:
 		installedOnly = true
-		return
+		return nil
 	case ActionRefresh:
 		refresh()
-		return
+		return nil
 	case ActionInstall:
-		slog.Info(Name, "activate", fmt.Sprintf("Installing package %s", identifier))
+		logger.Info(Name, "activate", fmt.Sprintf("Installing package %s", identifier))
 		pkgcmd = "install"
 	case ActionRemove:
-		slog.Info(Name, "activate", fmt.Sprintf("Removing package %s", identifier))
+		logger.Info(Name, "activate", fmt.Sprintf("Removing package %s", identifier))
 		pkgcmd = "remove"
 	default:
-		slog.Error(Name, "activate", fmt.Sprintf("unknown action: %s", action))
-		return
+		return fmt.Errorf("unknown action: %s", action)
 	}
 
 	toRun := common.WrapWithTerminal(fmt.Sprintf("sudo /usr/bin/dnf %s %s", pkgcmd, identifier))
 	cmd := exec.Command("sh", "-c", toRun)
-	err := cmd.Start()
-	if err != nil {
-		slog.Error(Name, "activate", fmt.Sprintf("could not install package %s", err.Error()))
-	} else {
-		go func() {
-			_ = cmd.Wait()
-		}()
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("could not %s package: %w", pkgcmd, err)
 	}
+
+	go func() {
+		_ = cmd.Wait()
+	}()
+
+	return nil
 }
 
 func refresh() {
@@ -139,12 +173,12 @@ func refreshPackages(refreshInstalledOnly bool) (map[string]PackageDetail, error
 
 	output, err := cmd.StdoutPipe()
 	if err != nil {
-		slog.Error(Name, "could not fetch packages", err.Error())
+		logger.Error(Name, "could not fetch packages", err.Error())
 		return nil, err
 	}
 
 	if err := cmd.Start(); err != nil {
-		slog.Error(Name, "could not fetch packages", err.Error())
+		logger.Error(Name, "could not fetch packages", err.Error())
 		return nil, err
 	}
 
@@ -175,7 +209,7 @@ func refreshPackages(refreshInstalledOnly bool) (map[string]PackageDetail, error
 		}
 	}
 
-	slog.Debug(Name, "query", time.Since(startTime))
+	logger.Debug(Name, "query", time.Since(startTime))
 	return packages, nil
 }
 
@@ -246,17 +280,12 @@ func Query(conn net.Conn, query string, single bool, exact bool, _ uint8) []*pb.
 		}
 	}
 
-	slog.Debug(Name, "query", time.Since(startTime))
+	logger.Debug(Name, "query", time.Since(startTime))
 	return entries
 }
 
 func Available() bool {
-	if _, err := os.Stat("/usr/bin/dnf"); err != nil {
-		slog.Info(Name, "available", "dnf command not found, disabling provider.")
-		return false
-	}
-
-	return true
+	return common.RequirementsMet(Name, Requirements)
 }
 
 func PrintDoc(write bool) {
@@ -0,0 +1,591 @@
+package providers
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/abenz1267/elephant/v2/pkg/common"
+	"github.com/abenz1267/elephant/v2/pkg/pb/pb"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// Updated notifies the subscribers of a provider. It's set by the handlers,
+// which can't be imported here.
+var Updated = func(provider string) {}
+
+const (
+	// externalTimeout limits calls that can't be canceled by the client, as
+	// well as writing to the process.
+	externalTimeout = 10 * time.Second
+	// externalShutdown is the time a provider gets to exit after its stdin
+	// was closed before it's killed.
+	externalShutdown = 3 * time.Second
+)
+
+var errExited = errors.New("provider exited")
+
+// external is a provider running as a separate process. It speaks JSON-RPC
+// 2.0 over stdin and stdout, one message per line. The process is started on
+// first use and restarted by the next call after it exited.
+type external struct {
+	name string
+	// namePretty is the display name, the name unless configured.
+	namePretty string
+	log        *slog.Logger
+	mut        sync.Mutex
+	cfg        common.ExternalProvider
+	id         uint64
+	proc       *process
+}
+
+type process struct {
+	cmd   *exec.Cmd
+	stdin *os.File
+	// writeMut serializes writes to stdin, which may block while the process
+	// isn't reading.
+	writeMut sync.Mutex
+	pending  map[uint64]chan rpcResponse
+	exited   chan struct{}
+}
+
+type rpcRequest struct {
+	JSONRPC string  `json:"jsonrpc"`
+	ID      *uint64 `json:"id,omitempty"`
+	Method  string  `json:"method"`
+	Params  any     `json:"params,omitempty"`
+}
+
+type rpcResponse struct {
+	ID     *uint64         `json:"id"`
+	Method string          `json:"method"`
+	Result json.RawMessage `json:"result"`
+	Error  *rpcError       `json:"error"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string {
+	return e.Message
+}
+
+// externalManifest describes the external provider cfg.
+func externalManifest(cfg common.ExternalProvider) *common.Manifest {
+	e := &external{
+		name:       cfg.Name,
+		namePretty: cmp.Or(cfg.NamePretty, cfg.Name),
+		log:        slog.Default(),
+		cfg:        cfg,
+	}
+
+	return &common.Manifest{
+		ABI:                  common.ABIVersion,
+		Name:                 e.name,
+		NamePretty:           &e.namePretty,
+		Available:            e.available,
+		Setup:                e.setup,
+		LoadConfig:           e.loadConfig,
+		PrintDoc:             e.printDoc,
+		Icon:                 e.icon,
+		HideFromProviderlist: e.hideFromProviderlist,
+		State:                e.state,
+		Activate:             e.activate,
+		Query:                e.query,
+		Requirements:         e.requirements(),
+		Shutdown:             e.shutdown,
+		ActivateErr:          e.activateErr,
+		QueryContext:         e.queryContext,
+		Logger:               &e.log,
+	}
+}
+
+// requirements returns the executable the command runs, if it can be told
+// without running a shell.
+func (e *external) requirements() []common.Requirement {
+	exe := commandExecutable(e.cfg.Command)
+	if exe == "" {
+		return nil
+	}
+
+	return []common.Requirement{{Name: exe, Executables: []string{exe}}}
+}
+
+// commandExecutable returns the program a shell command starts with,
+// skipping variable assignments and removing quotes. It returns "" for
+// commands starting with expansions or other shell syntax.
+func commandExecutable(command string) string {
+	for _, v := range shellWords(command) {
+		if !isAssignment(v) {
+			if strings.ContainsAny(v, "$`(){}|&;<>*?") {
+				return ""
+			}
+
+			return common.ExpandValue(v)
+		}
+	}
+
+	return ""
+}
+
+// shellWords splits a command into words like sh does, without expanding
+// anything.
+func shellWords(command string) []string {
+	res := []string{}
+
+	var word strings.Builder
+
+	inWord := false
+	quote := rune(0)
+	escaped := false
+
+	for _, c := range command {
+		switch {
+		case escaped:
+			word.WriteRune(c)
+			escaped = false
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else if c == '\\' && quote == '"' {
+				escaped = true
+			} else {
+				word.WriteRune(c)
+			}
+		case c == '\'' || c == '"':
+			quote = c
+			inWord = true
+		case c == '\\':
+			escaped = true
+			inWord = true
+		case c == ' ' || c == '\t' || c == '\n':
+			if inWord {
+				res = append(res, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(c)
+			inWord = true
+		}
+	}
+
+	if inWord {
+		res = append(res, word.String())
+	}
+
+	return res
+}
+
+// isAssignment reports whether word is a variable assignment, f.e. FOO=1.
+func isAssignment(word string) bool {
+	name, _, ok := strings.Cut(word, "=")
+	if !ok || name == "" {
+		return false
+	}
+
+	for i, c := range name {
+		if c != '_' && !(c >= 'a' && c <= 'z') && !(c >= 'A' && c <= 'Z') && (i == 0 || c < '0' || c > '9') {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (e *external) available() bool {
+	return common.RequirementsMet(e.name, e.requirements())
+}
+
+func (e *external) setup() {
+	e.mut.Lock()
+	defer e.mut.Unlock()
+
+	if e.proc != nil {
+		return
+	}
+
+	if err := e.start(); err != nil {
+		e.log.Error(e.name, "start", err)
+	}
+}
+
+// loadConfig applies the config of the provider. A changed command restarts
+// the process on the next call. It runs while the config lock of the
+// provider is held, which guards namePretty.
+func (e *external) loadConfig() {
+	idx := slices.IndexFunc(common.GetElephantConfig().ExternalProviders, func(v common.ExternalProvider) bool {
+		return v.Name == e.name
+	})
+
+	if idx == -1 {
+		return
+	}
+
+	cfg := common.GetElephantConfig().ExternalProviders[idx]
+
+	e.namePretty = cmp.Or(cfg.NamePretty, cfg.Name)
+
+	e.mut.Lock()
+	defer e.mut.Unlock()
+
+	restart := cfg.Command != e.cfg.Command
+	e.cfg = cfg
+
+	if restart && e.proc != nil {
+		go e.stop(e.proc)
+		e.proc = nil
+	}
+}
+
+func (e *external) printDoc(write bool) {
+	if write {
+		return
+	}
+
+	e.mut.Lock()
+	defer e.mut.Unlock()
+
+	fmt.Printf("%s is an external provider, running: %s\n", e.name, e.cfg.Command)
+}
+
+func (e *external) icon() string {
+	e.mut.Lock()
+	defer e.mut.Unlock()
+
+	return e.cfg.Icon
+}
+
+func (e *external) hideFromProviderlist() bool {
+	e.mut.Lock()
+	defer e.mut.Unlock()
+
+	return e.cfg.HideFromProviderlist
+}
+
+func (e *external) state(provider string) *pb.ProviderStateResponse {
+	res := &pb.ProviderStateResponse{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), externalTimeout)
+	defer cancel()
+
+	var raw json.RawMessage
+
+	if err := e.call(ctx, "state", map[string]any{"provider": provider}, &raw); err != nil {
+		e.log.Error(e.name, "state", err)
+		return res
+	}
+
+	if len(raw) == 0 || string(raw) == "null" {
+		return res
+	}
+
+	if err := unmarshalResult.Unmarshal(raw, res); err != nil {
+		e.log.Error(e.name, "state", err)
+		return &pb.ProviderStateResponse{}
+	}
+
+	return res
+}
+
+func (e *external) activate(single bool, identifier, action, query, args string, format uint8, conn net.Conn) {
+	if err := e.activateErr(single, identifier, action, query, args, format, conn); err != nil {
+		e.log.Error(e.name, "activate", err)
+	}
+}
+
+func (e *external) activateErr(single bool, identifier, action, query, args string, format uint8, conn net.Conn) error {
+	ctx, cancel := context.WithTimeout(context.Background(), externalTimeout)
+	defer cancel()
+
+	return e.call(ctx, "activate", map[string]any{
+		"identifier": identifier,
+		"action":     action,
+		"query":      query,
+		"arguments":  args,
+		"single":     single,
+	}, nil)
+}
+
+func (e *external) query(conn net.Conn, query string, single bool, exact bool, format uint8) []*pb.QueryResponse_Item {
+	return e.queryContext(context.Background(), conn, query, single, exact, format)
+}
+
+func (e *external) queryContext(ctx context.Context, conn net.Conn, query string, single bool, exact bool, format uint8) []*pb.QueryResponse_Item {
+	raw := []json.RawMessage{}
+
+	err := e.call(ctx, "query", map[string]any{
+		"query":  query,
+		"single": single,
+		"exact":  exact,
+	}, &raw)
+	if err != nil {
+		if ctx.Err() == nil {
+			e.log.Error(e.name, "query", err)
+		}
+
+		return nil
+	}
+
+	entries := make([]*pb.QueryResponse_Item, 0, len(raw))
+
+	for _, v := range raw {
+		item := &pb.QueryResponse_Item{}
+
+		if err := unmarshalResult.Unmarshal(v, item); err != nil {
+			e.log.Error(e.name, "query", err)
+			continue
+		}
+
+		if item.Provider == "" {
+			item.Provider = e.name
+		}
+
+		entries = append(entries, item)
+	}
+
+	return entries
+}
+
+// unmarshalResult reads items and states, accepting both the proto and the
+// JSON field names.
+var unmarshalResult = protojson.UnmarshalOptions{DiscardUnknown: true}
+
+func (e *external) shutdown() {
+	e.mut.Lock()
+	proc := e.proc
+	e.proc = nil
+	e.mut.Unlock()
+
+	if proc != nil {
+		e.stop(proc)
+	}
+}
+
+// stop closes stdin of the process, which should make it exit, and kills it
+// if it doesn't.
+func (e *external) stop(proc *process) {
+	proc.stdin.Close()
+
+	select {
+	case <-proc.exited:
+	case <-time.After(externalShutdown):
+		e.log.Error(e.name, "shutdown", "timeout, killing process")
+		proc.cmd.Process.Kill()
+		<-proc.exited
+	}
+}
+
+// start starts the process. The caller has to hold the lock.
+func (e *external) start() error {
+	cmd := exec.Command("sh", "-c", e.cfg.Command)
+
+	// a pipe of our own, unlike StdinPipe it supports write deadlines.
+	stdinR, stdin, err := os.Pipe()
+	if err != nil {
+		return err
+	}
+
+	cmd.Stdin = stdinR
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		stdinR.Close()
+		stdin.Close()
+		return err
+	}
+
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		stdinR.Close()
+		stdin.Close()
+		return err
+	}
+
+	err = cmd.Start()
+	stdinR.Close()
+
+	if err != nil {
+		stdin.Close()
+		return err
+	}
+
+	proc := &process{
+		cmd:     cmd,
+		stdin:   stdin,
+		pending: make(map[uint64]chan rpcResponse),
+		exited:  make(chan struct{}),
+	}
+
+	e.proc = proc
+
+	e.log.Info(e.name, "started", e.cfg.Command, "pid", cmd.Process.Pid)
+
+	go e.read(proc, stdout, stderr)
+
+	return nil
+}
+
+// read dispatches the messages of the process until it exits. Pending calls
+// fail afterwards.
+func (e *external) read(proc *process, stdout, stderr io.Reader) {
+	var wg sync.WaitGroup
+
+	wg.Go(func() {
+		scanner := bufio.NewScanner(stderr)
+
+		for scanner.Scan() {
+			e.log.Info(e.name, "stderr", scanner.Text())
+		}
+	})
+
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	for scanner.Scan() {
+		msg := rpcResponse{}
+
+		if err := json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			e.log.Error(e.name, "read", err)
+			continue
+		}
+
+		switch {
+		case msg.Method == "update":
+			Updated(e.name)
+		case msg.Method != "":
+			e.log.Error(e.name, "read", fmt.Sprintf("unknown method %q", msg.Method))
+		case msg.ID != nil:
+			e.mut.Lock()
+			ch, ok := proc.pending[*msg.ID]
+			delete(proc.pending, *msg.ID)
+			e.mut.Unlock()
+
+			if ok {
+				ch <- msg
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		// the process might be blocked writing to stdout.
+		e.log.Error(e.name, "read", err)
+		proc.cmd.Process.Kill()
+	}
+
+	wg.Wait()
+
+	if err := proc.cmd.Wait(); err != nil {
+		e.log.Error(e.name, "exited", err)
+	} else {
+		e.log.Info(e.name, "exited", proc.cmd.ProcessState.ExitCode())
+	}
+
+	e.mut.Lock()
+	if e.proc == proc {
+		e.proc = nil
+	}
+
+	for k, v := range proc.pending {
+		close(v)
+		delete(proc.pending, k)
+	}
+	e.mut.Unlock()
+
+	close(proc.exited)
+}
+
+// call sends a request and decodes its result into res, if not nil. If ctx
+// is done first the provider is told to cancel the request.
+func (e *external) call(ctx context.Context, method string, params any, res any) error {
+	e.mut.Lock()
+
+	if e.proc == nil {
+		if err := e.start(); err != nil {
+			e.mut.Unlock()
+			return err
+		}
+	}
+
+	proc := e.proc
+
+	e.id++
+	id := e.id
+
+	ch := make(chan rpcResponse, 1)
+	proc.pending[id] = ch
+
+	e.mut.Unlock()
+
+	if err := e.send(proc, rpcRequest{JSONRPC: "2.0", ID: &id, Method: method, Params: params}); err != nil {
+		e.mut.Lock()
+		delete(proc.pending, id)
+		e.mut.Unlock()
+
+		return err
+	}
+
+	select {
+	case msg, ok := <-ch:
+		if !ok {
+			return errExited
+		}
+
+		if msg.Error != nil {
+			return msg.Error
+		}
+
+		if res == nil || len(msg.Result) == 0 {
+			return nil
+		}
+
+		return json.Unmarshal(msg.Result, res)
+	case <-ctx.Done():
+		e.mut.Lock()
+		delete(proc.pending, id)
+		e.mut.Unlock()
+
+		if err := e.send(proc, rpcRequest{JSONRPC: "2.0", Method: "cancel", Params: map[string]any{"id": id}}); err != nil {
+			e.log.Error(e.name, "cancel", err)
+		}
+
+		return ctx.Err()
+	}
+}
+
+// send writes a message to the process without holding the lock, so a
+// process not reading its stdin doesn't block other calls. It's killed if
+// the write doesn't finish within externalTimeout.
+func (e *external) send(proc *process, msg rpcRequest) error {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	proc.writeMut.Lock()
+	defer proc.writeMut.Unlock()
+
+	proc.stdin.SetWriteDeadline(time.Now().Add(externalTimeout))
+
+	_, err = proc.stdin.Write(append(b, '\n'))
+	if errors.Is(err, os.ErrDeadlineExceeded) {
+		e.log.Error(e.name, "write", "process isn't reading, killing it")
+		proc.cmd.Process.Kill()
+	}
+
+	return err
+}
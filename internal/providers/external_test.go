@@ -0,0 +1,254 @@
+package providers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/abenz1267/elephant/v2/pkg/common"
+)
+
+// TestExternalHelper isn't a test, it's the external provider started by the
+// other tests.
+//
+//   - query "pid" returns the pid of the process.
+//   - query "slow" never gets a result.
+//   - query "canceled" returns the ids of canceled requests.
+//   - query "notify" sends an update notification first.
+//   - query "exit" exits the process.
+//   - activating "fail" returns an error.
+//
+// Other queries return a single item with the query as identifier.
+func TestExternalHelper(t *testing.T) {
+	if os.Getenv("ELEPHANT_EXTERNAL_HELPER") != "1" {
+		return
+	}
+
+	var canceled []string
+
+	write := func(msg map[string]any) {
+		msg["jsonrpc"] = "2.0"
+		b, _ := json.Marshal(msg)
+		fmt.Printf("%s\n", b)
+	}
+
+	scanner := bufio.NewScanner(os.Stdin)
+
+	for scanner.Scan() {
+		var req struct {
+			ID     *uint64        `json:"id"`
+			Method string         `json:"method"`
+			Params map[string]any `json:"params"`
+		}
+
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+
+		switch req.Method {
+		case "cancel":
+			canceled = append(canceled, fmt.Sprint(req.Params["id"]))
+		case "activate":
+			if req.Params["identifier"] == "fail" {
+				write(map[string]any{"id": req.ID, "error": map[string]any{"code": 1, "message": "failed"}})
+			} else {
+				write(map[string]any{"id": req.ID, "result": nil})
+			}
+		case "query":
+			items := []map[string]any{}
+
+			switch q := req.Params["query"].(string); q {
+			case "slow":
+				continue
+			case "exit":
+				os.Exit(0)
+			case "pid":
+				items = append(items, map[string]any{"identifier": strconv.Itoa(os.Getpid())})
+			case "canceled":
+				items = append(items, map[string]any{"identifier": strings.Join(canceled, ",")})
+			case "notify":
+				write(map[string]any{"method": "update"})
+				items = append(items, map[string]any{"identifier": q})
+			default:
+				items = append(items, map[string]any{"identifier": q, "text": q})
+			}
+
+			write(map[string]any{"id": req.ID, "result": items})
+		}
+	}
+
+	os.Exit(0)
+}
+
+func newTestExternal(t *testing.T) *external {
+	t.Setenv("ELEPHANT_EXTERNAL_HELPER", "1")
+
+	e := &external{
+		name: "test",
+		log:  common.ProviderLogger("test"),
+		cfg: common.ExternalProvider{
+			Name:    "test",
+			Command: fmt.Sprintf("'%s' -test.run='^TestExternalHelper$'", os.Args[0]),
+		},
+	}
+
+	t.Cleanup(e.shutdown)
+
+	return e
+}
+
+func queryIdentifier(t *testing.T, e *external, query string) string {
+	t.Helper()
+
+	res := e.query(nil, query, false, false, 0)
+	if len(res) != 1 {
+		t.Fatalf("query %q returned %d items, expected 1", query, len(res))
+	}
+
+	if res[0].Provider != "test" {
+		t.Errorf("provider = %q, expected %q", res[0].Provider, "test")
+	}
+
+	return res[0].Identifier
+}
+
+func TestExternalCalls(t *testing.T) {
+	e := newTestExternal(t)
+
+	if v := queryIdentifier(t, e, "hello"); v != "hello" {
+		t.Errorf("identifier = %q, expected %q", v, "hello")
+	}
+
+	if err := e.activateErr(false, "ok", "", "", "", 0, nil); err != nil {
+		t.Errorf("activate: %v", err)
+	}
+
+	var rpcErr *rpcError
+
+	if err := e.activateErr(false, "fail", "", "", "", 0, nil); !errors.As(err, &rpcErr) || rpcErr.Message != "failed" {
+		t.Errorf("activate returned %v, expected the error of the provider", err)
+	}
+}
+
+func TestExternalConcurrentCalls(t *testing.T) {
+	e := newTestExternal(t)
+
+	var wg sync.WaitGroup
+
+	for i := range 20 {
+		wg.Go(func() {
+			query := fmt.Sprintf("q%d", i)
+
+			if v := queryIdentifier(t, e, query); v != query {
+				t.Errorf("identifier = %q, expected %q", v, query)
+			}
+		})
+	}
+
+	wg.Wait()
+}
+
+func TestExternalCancel(t *testing.T) {
+	e := newTestExternal(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	if res := e.queryContext(ctx, nil, "slow", false, false, 0); res != nil {
+		t.Errorf("canceled query returned %v", res)
+	}
+
+	// the first call started the process with id 1.
+	if v := queryIdentifier(t, e, "canceled"); v != "1" {
+		t.Errorf("canceled = %q, expected %q", v, "1")
+	}
+
+	e.mut.Lock()
+	pending := len(e.proc.pending)
+	e.mut.Unlock()
+
+	if pending != 0 {
+		t.Errorf("%d calls still pending", pending)
+	}
+}
+
+func TestExternalRestart(t *testing.T) {
+	e := newTestExternal(t)
+
+	pid := queryIdentifier(t, e, "pid")
+
+	if res := e.query(nil, "exit", false, false, 0); res != nil {
+		t.Errorf("query exiting the process returned %v", res)
+	}
+
+	e.mut.Lock()
+	proc := e.proc
+	e.mut.Unlock()
+
+	if proc != nil {
+		<-proc.exited
+	}
+
+	restarted := queryIdentifier(t, e, "pid")
+
+	if restarted == pid {
+		t.Errorf("process wasn't restarted, pid is still %s", pid)
+	}
+}
+
+func TestExternalUpdate(t *testing.T) {
+	e := newTestExternal(t)
+
+	updated := make(chan string, 1)
+
+	old := Updated
+	Updated = func(provider string) { updated <- provider }
+	defer func() { Updated = old }()
+
+	queryIdentifier(t, e, "notify")
+
+	select {
+	case v := <-updated:
+		if v != "test" {
+			t.Errorf("updated %q, expected %q", v, "test")
+		}
+	case <-time.After(time.Second):
+		t.Error("no update")
+	}
+}
+
+func TestCommandExecutable(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	tests := map[string]string{
+		"provider --flag":                    "provider",
+		"  provider":                         "provider",
+		"FOO=1 BAR='a b' provider":           "provider",
+		"'/opt/my tools/provider' --flag":    "/opt/my tools/provider",
+		`"/opt/my tools/provider"`:           "/opt/my tools/provider",
+		`/opt/my\ tools/provider`:            "/opt/my tools/provider",
+		"~/bin/provider":                     filepath.Join(home, "bin/provider"),
+		"$HOME/bin/provider":                 "",
+		"FOO=1":                              "",
+		"":                                   "",
+		"provider=1 other":                   "other",
+		"/usr/bin/env FOO=1 provider --flag": "/usr/bin/env",
+	}
+
+	for command, expected := range tests {
+		if v := commandExecutable(command); v != expected {
+			t.Errorf("commandExecutable(%q) = %q, expected %q", command, v, expected)
+		}
+	}
+}
@@ -1,8 +1,7 @@
-package main
+package files
 
 import (
 	"fmt"
-	"log/slog"
 	"net"
 	"os/exec"
 	"path/filepath"
@@ -22,13 +21,18 @@ const (
 )
 
 func Activate(single bool, identifier, action string, query string, args string, format uint8, conn net.Conn) {
+	if err := ActivateErr(single, identifier, action, query, args, format, conn); err != nil {
+		logger.Error(Name, "activate", err)
+	}
+}
+
+func ActivateErr(single bool, identifier, action string, query string, args string, format uint8, conn net.Conn) error {
 	f := getFile(identifier)
 
 	var path string
 
 	if f == nil && action != ActionReindex {
-		slog.Error(Name, "activate", "file not found")
-		return
+		return fmt.Errorf("file not found: %s", identifier)
 	}
 
 	if f != nil {
@@ -49,14 +53,13 @@ func Activate(single bool, identifier, action string, query string, args string,
 			Setsid: true,
 		}
 
-		err := cmd.Start()
-		if err != nil {
-			slog.Error(Name, "actionlocalsend", err)
-		} else {
-			go func() {
-				cmd.Wait()
-			}()
+		if err := cmd.Start(); err != nil {
+			return fmt.Errorf("localsend: %w", err)
 		}
+
+		go func() {
+			cmd.Wait()
+		}()
 	case ActionOpen, ActionOpenDir:
 		if action == ActionOpenDir {
 			path = filepath.Dir(path)
@@ -73,39 +76,36 @@ func Activate(single bool, identifier, action string, query string, args string,
 			Setsid: true,
 		}
 
-		err := cmd.Start()
-		if err != nil {
-			slog.Error(Name, "actionopen", err)
-		} else {
-			go func() {
-				cmd.Wait()
-			}()
+		if err := cmd.Start(); err != nil {
+			return fmt.Errorf("open: %w", err)
 		}
+
+		go func() {
+			cmd.Wait()
+		}()
 	case ActionCopyPath:
 		cmd := exec.Command("wl-copy", path)
 
-		err := cmd.Start()
-		if err != nil {
-			slog.Error(Name, "actioncopypath", err)
-		} else {
-			go func() {
-				cmd.Wait()
-			}()
+		if err := cmd.Start(); err != nil {
+			return fmt.Errorf("copy path: %w", err)
 		}
 
+		go func() {
+			cmd.Wait()
+		}()
 	case ActionCopyFile:
 		cmd := exec.Command("wl-copy", "-t", "text/uri-list", fmt.Sprintf("file://%s", path))
 
-		err := cmd.Start()
-		if err != nil {
-			slog.Error(Name, "actioncopyfile", err)
-		} else {
-			go func() {
-				cmd.Wait()
-			}()
+		if err := cmd.Start(); err != nil {
+			return fmt.Errorf("copy file: %w", err)
 		}
+
+		go func() {
+			cmd.Wait()
+		}()
 	default:
-		slog.Error(Name, "activate", fmt.Sprintf("unknown action: %s", action))
-		return
+		return fmt.Errorf("unknown action: %s", action)
 	}
+
+	return nil
 }
@@ -1,9 +1,8 @@
-package main
+package files
 
 import (
 	"database/sql"
 	"fmt"
-	"log/slog"
 	"os"
 	"path/filepath"
 	"time"
@@ -14,6 +13,16 @@ import (
 
 var db *sql.DB
 
+func closeDB() {
+	if db == nil {
+		return
+	}
+
+	if err := db.Close(); err != nil {
+		logger.Error(Name, "db close", err)
+	}
+}
+
 func openDB() error {
 	path := common.CacheFile("files.db")
 	os.Remove(path)
@@ -94,7 +103,7 @@ func putFileBatch(files []File) error {
 func dropAll() {
 	_, err := db.Exec("DELETE FROM files")
 	if err != nil {
-		slog.Error(Name, "delete", err)
+		logger.Error(Name, "delete", err)
 	}
 }
 
@@ -107,7 +116,7 @@ func putFile(f File) {
 	_, err := db.Exec("INSERT OR REPLACE INTO files (identifier, path, changed) VALUES (?, ?, ?)",
 		f.Identifier, f.Path, changedUnix)
 	if err != nil {
-		slog.Error(Name, "put", err)
+		logger.Error(Name, "put", err)
 	}
 }
 
@@ -134,7 +143,7 @@ func getFilesByQuery(query string, _ bool) []File {
 	path := common.CacheFile("files.db")
 	queryDB, err := sql.Open("sqlite3", path+"?_journal_mode=WAL&_synchronous=NORMAL&_cache_size=10000&_temp_store=memory&_busy_timeout=5000")
 	if err != nil {
-		slog.Error(Name, "open query db", err)
+		logger.Error(Name, "open query db", err)
 		return nil
 	}
 	defer queryDB.Close()
@@ -149,7 +158,7 @@ func getFilesByQuery(query string, _ bool) []File {
 	}
 
 	if err != nil {
-		slog.Error(Name, "read", err)
+		logger.Error(Name, "read", err)
 		return nil
 	}
 	defer rows.Close()
@@ -175,6 +184,20 @@ func getFilesByQuery(query string, _ bool) []File {
 func deleteFileByPath(path string) {
 	_, err := db.Exec("DELETE FROM files WHERE path LIKE ?", path+"%")
 	if err != nil {
-		slog.Error(Name, "delete", err)
+		logger.Error(Name, "delete", err)
+	}
+}
+
+func countFiles() int {
+	if db == nil {
+		return 0
+	}
+
+	var count int
+
+	if err := db.QueryRow("SELECT COUNT(*) FROM files").Scan(&count); err != nil {
+		logger.Error(Name, "count", err)
 	}
+
+	return count
 }
@@ -0,0 +1,6 @@
+// Package main builds the files provider as plugin.
+package main
+
+import "github.com/abenz1267/elephant/v2/internal/providers/files"
+
+var Manifest = files.Manifest
@@ -1,7 +1,6 @@
-package main
+package files
 
 import (
-	"log/slog"
 	"net"
 	"strings"
 	"time"
@@ -60,7 +59,7 @@ func Query(conn net.Conn, query string, _ bool, exact bool, _ uint8) []*pb.Query
 		entries = append(entries, entry)
 	}
 
-	slog.Debug(Name, "query", time.Since(start))
+	logger.Debug(Name, "query", time.Since(start))
 
 	return entries
 }
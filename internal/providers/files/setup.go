@@ -1,4 +1,4 @@
-package main
+package files
 
 import (
 	"bufio"
@@ -28,12 +28,38 @@ var readme string
 var (
 	Name         = "files"
 	NamePretty   = "Files"
+	logger       = slog.Default()
 	config       *Config
 	watcher      *fsnotify.Watcher
 	ignoreRegexp []*regexp.Regexp
 	hasLocalsend bool
 )
 
+var Requirements = []common.Requirement{
+	{Name: "fd", Executables: []string{"fd", "fdfind"}},
+	{Name: "localsend", Executables: []string{"localsend"}, Optional: true},
+}
+
+var Manifest = common.Manifest{
+	ABI:                  common.ABIVersion,
+	Name:                 Name,
+	NamePretty:           &NamePretty,
+	Available:            Available,
+	Setup:                Setup,
+	LoadConfig:           LoadConfig,
+	PrintDoc:             PrintDoc,
+	Icon:                 Icon,
+	HideFromProviderlist: HideFromProviderlist,
+	State:                State,
+	Activate:             Activate,
+	Query:                Query,
+	Requirements:         Requirements,
+	Shutdown:             Shutdown,
+	Metrics:              Metrics,
+	ActivateErr:          ActivateErr,
+	Logger:               &logger,
+}
+
 type IgnoredPreview struct {
 	Path        string `koanf:"path" desc:"path to ignore preview for" default:""`
 	Placeholder string `koanf:"placeholder" desc:"text to display instead" default:""`
@@ -56,7 +82,7 @@ func Setup() {
 
 	err := openDB()
 	if err != nil {
-		slog.Error(Name, "setup", err)
+		logger.Error(Name, "setup", err)
 		return
 	}
 
@@ -67,10 +93,6 @@ func Setup() {
 
 	LoadConfig()
 
-	if config.NamePretty != "" {
-		NamePretty = config.NamePretty
-	}
-
 	watcher, err = fsnotify.NewWatcher()
 	if err != nil {
 		log.Fatal(err)
@@ -106,11 +128,11 @@ func Setup() {
 
 	go index()
 
-	slog.Info(Name, "time", time.Since(start))
+	logger.Info(Name, "time", time.Since(start))
 }
 
 func LoadConfig() {
-	config = &Config{
+	cfg := &Config{
 		Config: common.Config{
 			Icon:     "folder",
 			MinScore: 20,
@@ -121,7 +143,13 @@ func LoadConfig() {
 		FdFlags:     []string{"--ignore-vcs", "--type", "file", "--type", "directory"},
 	}
 
-	common.LoadConfig(Name, config)
+	common.LoadConfig(Name, cfg)
+
+	if cfg.NamePretty != "" {
+		NamePretty = cfg.NamePretty
+	}
+
+	config = cfg
 }
 
 func index() {
@@ -137,7 +165,7 @@ func index() {
 	for _, v := range config.IgnoredDirs {
 		r, err := regexp.Compile(v)
 		if err != nil {
-			slog.Error(Name, "ignoredirs regexp", err)
+			logger.Error(Name, "ignoredirs regexp", err)
 			continue
 		}
 
@@ -150,12 +178,12 @@ func index() {
 
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
-		slog.Error(Name, "files", err)
+		logger.Error(Name, "files", err)
 		return
 	}
 
 	if err := cmd.Start(); err != nil {
-		slog.Error(Name, "files", err)
+		logger.Error(Name, "files", err)
 		return
 	}
 
@@ -227,7 +255,7 @@ outer:
 
 				if len(batch) >= 5000 {
 					if err := putFileBatch(batch); err != nil {
-						slog.Error(Name, "batch insert", err)
+						logger.Error(Name, "batch insert", err)
 					}
 					batch = batch[:0]
 				}
@@ -237,20 +265,22 @@ outer:
 
 	if len(batch) > 0 {
 		if err := putFileBatch(batch); err != nil {
-			slog.Error(Name, "final batch insert", err)
+			logger.Error(Name, "final batch insert", err)
 		}
 	}
 
 	if err := cmd.Wait(); err != nil {
-		slog.Error(Name, "cmd wait", err)
+		logger.Error(Name, "cmd wait", err)
 	}
 }
 
-func Available() bool {
-	p1, _ := exec.LookPath("fd")
-	p2, _ := exec.LookPath("fdfind")
+// Shutdown closes the database, checkpointing the WAL.
+func Shutdown() {
+	closeDB()
+}
 
-	return p1 != "" || p2 != ""
+func Available() bool {
+	return common.RequirementsMet(Name, Requirements)
 }
 
 func handleDelete(deleteChan chan string) {
@@ -367,3 +397,13 @@ func shouldWatch(path string) bool {
 
 	return false
 }
+
+func Metrics() []common.Metric {
+	return []common.Metric{
+		{
+			Name:  "files_indexed",
+			Help:  "files in the index",
+			Value: float64(countFiles()),
+		},
+	}
+}
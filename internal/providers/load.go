@@ -2,6 +2,8 @@
 package providers
 
 import (
+	"context"
+	"fmt"
 	"io/fs"
 	"log/slog"
 	"net"
@@ -34,12 +36,253 @@ type Provider struct {
 	Icon                 func() string
 	Activate             func(single bool, identifier, action, query, args string, format uint8, conn net.Conn)
 	Query                func(conn net.Conn, query string, single bool, exact bool, format uint8) []*pb.QueryResponse_Item
+	// optional, see common.Manifest
+	Shutdown     func()
+	ActivateErr  func(single bool, identifier, action, query, args string, format uint8, conn net.Conn) error
+	QueryContext func(ctx context.Context, conn net.Conn, query string, single bool, exact bool, format uint8) []*pb.QueryResponse_Item
+	CheckConfig  func() []common.ConfigIssue
+	Metrics      func() []common.Metric
+
+	setup *setupState
+	// cfgMut is held by LoadConfig, calls reading the config of the provider
+	// share it.
+	cfgMut *sync.RWMutex
+}
+
+// Get returns a loaded provider, unless it's ignored by the active config.
+func Get(name string) (Provider, bool) {
+	p, ok := Providers[name]
+	if !ok {
+		return p, false
+	}
+
+	if cfg := common.GetElephantConfig(); cfg != nil && slices.Contains(cfg.IgnoredProviders, name) {
+		return Provider{}, false
+	}
+
+	return p, true
+}
+
+// Shutdown calls the Shutdown function of all providers that are set up and
+// waits for them until ctx is done.
+func Shutdown(ctx context.Context) error {
+	var wg sync.WaitGroup
+
+	for _, v := range Providers {
+		if v.Shutdown == nil || !v.ready() {
+			continue
+		}
+
+		wg.Go(func() {
+			v.Recover(v.Shutdown)
+			slog.Info("providers", "shutdown", *v.Name)
+		})
+	}
+
+	done := make(chan struct{})
+
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// RunActivate activates an item. Only providers implementing ActivateErr can
+// report failures, crashed and disabled providers always do.
+func (p Provider) RunActivate(single bool, identifier, action, query, args string, format uint8, conn net.Conn) error {
+	if err := p.suspended(); err != nil {
+		return err
+	}
+
+	p.awaitSetup(context.Background())
+
+	var err error
+
+	defer p.rlock()()
+
+	crash := p.Recover(func() {
+		if p.ActivateErr != nil {
+			err = p.ActivateErr(single, identifier, action, query, args, format, conn)
+			return
+		}
+
+		p.Activate(single, identifier, action, query, args, format, conn)
+	})
+	if crash != nil {
+		return crash
+	}
+
+	return err
+}
+
+// RunQuery queries the provider until ctx is done. Lazy and idle providers
+// are set up first, which counts towards ctx. The returned error is the
+// context's error, or the error of a crashed or disabled provider.
+//
+// Providers implementing QueryContext stop on their own and may return
+// partial results along with the context's error. All other providers can't
+// be interrupted: they keep running in the background and nothing is
+// returned for them on timeout.
+func (p Provider) RunQuery(ctx context.Context, conn net.Conn, query string, single bool, exact bool, format uint8) ([]*pb.QueryResponse_Item, error) {
+	if err := p.suspended(); err != nil {
+		return nil, err
+	}
+
+	if err := p.awaitSetup(ctx); err != nil {
+		return nil, err
+	}
+
+	var res []*pb.QueryResponse_Item
+
+	if p.QueryContext != nil {
+		defer p.rlock()()
+
+		if err := p.Recover(func() {
+			res = p.QueryContext(ctx, conn, query, single, exact, format)
+		}); err != nil {
+			return nil, err
+		}
+
+		return res, ctx.Err()
+	}
+
+	type result struct {
+		items []*pb.QueryResponse_Item
+		err   error
+	}
+
+	done := make(chan result, 1)
+
+	go func() {
+		var items []*pb.QueryResponse_Item
+
+		defer p.rlock()()
+
+		err := p.Recover(func() {
+			items = p.Query(conn, query, single, exact, format)
+		})
+
+		done <- result{items, err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.items, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// RunState returns the state of the provider.
+func (p Provider) RunState(provider string) (*pb.ProviderStateResponse, error) {
+	if err := p.suspended(); err != nil {
+		return nil, err
+	}
+
+	p.awaitSetup(context.Background())
+
+	var res *pb.ProviderStateResponse
+
+	defer p.rlock()()
+
+	if err := p.Recover(func() {
+		res = p.State(provider)
+	}); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// RunLoadConfig (re)loads the config of the provider. Calls of the provider
+// made through the Run functions wait until it's done.
+func (p Provider) RunLoadConfig() error {
+	if p.cfgMut != nil {
+		p.cfgMut.Lock()
+		defer p.cfgMut.Unlock()
+	}
+
+	return p.Recover(p.LoadConfig)
+}
+
+// PrettyName returns the display name of the provider.
+func (p Provider) PrettyName() string {
+	defer p.rlock()()
+
+	return *p.NamePretty
+}
+
+// RunIcon returns the icon of the provider, "" if it crashed.
+func (p Provider) RunIcon() string {
+	defer p.rlock()()
+
+	icon := ""
+	p.Recover(func() { icon = p.Icon() })
+
+	return icon
+}
+
+// RunHideFromProviderlist reports whether the provider hides itself from the
+// providerlist. Providers crashing when asked are hidden.
+func (p Provider) RunHideFromProviderlist() bool {
+	defer p.rlock()()
+
+	hide := true
+	p.Recover(func() { hide = p.HideFromProviderlist() })
+
+	return hide
+}
+
+// rlock holds the config of the provider until the returned func is called.
+func (p Provider) rlock() func() {
+	if p.cfgMut == nil {
+		return func() {}
+	}
+
+	p.cfgMut.RLock()
+
+	return p.cfgMut.RUnlock
+}
+
+// Capabilities lists the functionality a provider exposes to clients.
+func (p Provider) Capabilities() []string {
+	res := []string{}
+
+	if p.Query != nil {
+		res = append(res, "query")
+	}
+
+	if p.Activate != nil {
+		res = append(res, "activate")
+	}
+
+	if p.State != nil {
+		res = append(res, "state")
+	}
+
+	return res
 }
 
 var (
 	Providers      map[string]Provider
 	QueryProviders map[uint32][]string
-	libDirs        = []string{
+	// Disabled holds the providers that were found but not loaded, with the
+	// reason.
+	Disabled map[string]string
+	// Requirements holds the requirements of all found providers.
+	Requirements map[string][]common.Requirement
+	// Plugins holds all found plugin files, with their status.
+	Plugins []Plugin
+	// builtins holds the providers compiled into elephant.
+	builtins []*common.Manifest
+	libDirs  = []string{
 		"/usr/lib/elephant",
 		"/usr/lib64/elephant",
 		"/usr/local/lib/elephant",
@@ -49,15 +292,24 @@ var (
 	}
 )
 
+// Register adds a provider compiled into elephant. Providers call it from
+// init when built with the "builtin" or "builtin_<provider>" tag. Built-in
+// providers take precedence over plugins of the same name.
+func Register(m *common.Manifest) {
+	builtins = append(builtins, m)
+}
+
 func Load(setup bool) {
-	go common.LoadMenus()
+	go common.RecoverMenu(common.LoadMenus)
 
 	cfg := common.GetElephantConfig()
 	ignored := cfg.IgnoredProviders
 	host, _ := os.Hostname()
 
 	var mut sync.Mutex
-	have := make(map[string]struct{})
+	// have holds the names of the plugin files already handled, with the
+	// status of later plugins of the same name.
+	have := make(map[string]string)
 	dirs := libDirs
 	env := os.Getenv("ELEPHANT_PROVIDER_DIR")
 
@@ -69,11 +321,124 @@ func Load(setup bool) {
 
 	Providers = make(map[string]Provider)
 	QueryProviders = make(map[uint32][]string)
+	Disabled = make(map[string]string)
+	Requirements = make(map[string][]common.Requirement)
+	Plugins = []Plugin{}
+	idleSetups = nil
 
 	if os.Getenv("ELEPHANT_DEV") == "true" {
 		dirs = []string{"/tmp/elephant/providers"}
 	}
 
+	record := func(info Plugin) {
+		mut.Lock()
+		Plugins = append(Plugins, info)
+		mut.Unlock()
+	}
+
+	// add loads the provider described by a valid manifest. fn is the name of
+	// the plugin file or the external provider.
+	add := func(fn string, m *common.Manifest, info Plugin) bool {
+		info.Provider = m.Name
+		info.Optional = m.Optional()
+
+		if m.Logger != nil {
+			*m.Logger = common.ProviderLogger(m.Name)
+		}
+
+		mut.Lock()
+		Requirements[fn] = m.Requirements
+		mut.Unlock()
+
+		if !m.Available() {
+			mut.Lock()
+			if _, ok := Providers[fn]; !ok {
+				Disabled[fn] = "not available"
+			}
+			mut.Unlock()
+
+			info.Status = "not available"
+			record(info)
+
+			return false
+		}
+
+		if val, ok := cfg.ProviderHosts[m.Name]; ok && len(val) > 0 {
+			if !slices.Contains(val, host) {
+				slog.Info("providers", "ignored", m.Name, "hosts", val, "host", host)
+
+				mut.Lock()
+				Disabled[m.Name] = "not enabled on this host"
+				mut.Unlock()
+
+				info.Status = "not enabled on this host"
+				record(info)
+
+				return false
+			}
+		}
+
+		provider := newProvider(m)
+		provider.setup.mode = cfg.ProviderSetup(m.Name)
+
+		if setup {
+			switch provider.setup.mode {
+			case common.SetupEager:
+				eagerSetups.Go(provider.runSetup)
+			case common.SetupIdle:
+				mut.Lock()
+				idleSetups = append(idleSetups, provider)
+				mut.Unlock()
+			}
+
+			// Icon and HideFromProviderlist depend on the config, which is
+			// otherwise loaded by Setup.
+			if provider.setup.mode != common.SetupEager {
+				provider.RunLoadConfig()
+			}
+		}
+
+		mut.Lock()
+		Providers[*provider.Name] = provider
+		delete(Disabled, *provider.Name)
+		mut.Unlock()
+
+		info.Status = "loaded"
+		record(info)
+
+		slog.Info("providers", "loaded", *provider.Name)
+
+		return true
+	}
+
+	for _, m := range builtins {
+		fn := m.Name
+		have[fn+".so"] = "shadowed by the built-in provider"
+
+		info := Plugin{Path: "builtin", Provider: fn, ABI: m.ABI, Builtin: true}
+
+		if slices.Contains(ignored, fn) {
+			Disabled[fn] = "ignored by config"
+
+			info.Status = "ignored by config"
+			record(info)
+
+			continue
+		}
+
+		if missing := m.Missing(); m.ABI != common.ABIVersion || len(missing) > 0 {
+			slog.Error("providers", "builtin", fn, "missing", missing, "abi", m.ABI)
+
+			info.Status = "rejected: invalid manifest"
+			Disabled[fn] = info.Status
+			record(info)
+
+			continue
+		}
+
+		add(fn, m, info)
+	}
+
 	for _, v := range dirs {
 		if !common.FileExists(v) {
 			continue
@@ -89,119 +454,72 @@ func Load(setup bool) {
 				os.Exit(1)
 			}
 
+			if filepath.Ext(path) != ".so" {
+				return nil
+			}
+
 			base := filepath.Base(path)
 
 			mut.Lock()
-			_, done := have[base]
+			shadowed, done := have[base]
 			mut.Unlock()
 
 			fn := strings.TrimSuffix(base, ".so")
 
+			if done {
+				record(Plugin{Path: path, Provider: fn, ABI: -1, Status: shadowed})
+				return nil
+			}
+
 			if slices.Contains(ignored, fn) {
 				mut.Lock()
-				have[base] = struct{}{}
+				have[base] = "shadowed by a plugin of the same name"
+				Disabled[fn] = "ignored by config"
 				mut.Unlock()
 
+				record(Plugin{Path: path, Provider: fn, ABI: -1, Status: "ignored by config"})
+
 				return nil
 			}
 
-			if !done && filepath.Ext(path) == ".so" {
-				p, err := plugin.Open(path)
-				if err != nil {
-					slog.Error("providers", "load", path, "err", err)
-					return nil
-				}
+			p, err := plugin.Open(path)
+			if err != nil {
+				slog.Error("providers", "load", path, "err", err)
 
-				availableFunc, err := p.Lookup("Available")
-				if err != nil {
-					slog.Error("providers", "load", err, "provider", path)
-				}
+				info := Plugin{Path: path, Provider: fn, ABI: -1, Status: fmt.Sprintf("rejected: %s", err)}
+
+				mut.Lock()
+				Disabled[fn] = info.Status
+				mut.Unlock()
+
+				record(info)
+
+				return nil
+			}
+
+			m, err := readManifest(p)
+			if err != nil {
+				slog.Error("providers", "load", path, "rejected", err)
 
-				if availableFunc.(func() bool)() {
-					name, err := p.Lookup("Name")
-					if err != nil {
-						slog.Error("providers", "load", err, "provider", path)
-					}
-
-					n := name.(*string)
-
-					if val, ok := cfg.ProviderHosts[*n]; ok && len(val) > 0 {
-						if !slices.Contains(val, host) {
-							slog.Info("providers", "ignored", *n, "hosts", val, "host", host)
-							return nil
-						}
-					}
-
-					namePretty, err := p.Lookup("NamePretty")
-					if err != nil {
-						slog.Error("providers", "load", err, "provider", path)
-					}
-
-					activateFunc, err := p.Lookup("Activate")
-					if err != nil {
-						slog.Error("providers", "load", err, "provider", path)
-					}
-
-					hideFromProviderlistFunc, err := p.Lookup("HideFromProviderlist")
-					if err != nil {
-						slog.Error("providers", "load", err, "provider", path)
-					}
-
-					queryFunc, err := p.Lookup("Query")
-					if err != nil {
-						slog.Error("providers", "load", err, "provider", path)
-					}
-
-					iconFunc, err := p.Lookup("Icon")
-					if err != nil {
-						slog.Error("providers", "load", err, "provider", path)
-					}
-
-					printDocFunc, err := p.Lookup("PrintDoc")
-					if err != nil {
-						slog.Error("providers", "load", err, "provider", path)
-					}
-
-					setupFunc, err := p.Lookup("Setup")
-					if err != nil {
-						slog.Error("providers", "load", err, "provider", path)
-					}
-
-					loadConfigFunc, err := p.Lookup("LoadConfig")
-					if err != nil {
-						slog.Error("providers", "load", err, "provider", path)
-					}
-
-					stateFunc, err := p.Lookup("State")
-					if err != nil {
-						slog.Error("providers", "load", err, "provider", path)
-					}
-
-					provider := Provider{
-						Icon:                 iconFunc.(func() string),
-						Setup:                setupFunc.(func()),
-						LoadConfig:           loadConfigFunc.(func()),
-						Name:                 name.(*string),
-						Activate:             activateFunc.(func(bool, string, string, string, string, uint8, net.Conn)),
-						Query:                queryFunc.(func(net.Conn, string, bool, bool, uint8) []*pb.QueryResponse_Item),
-						NamePretty:           namePretty.(*string),
-						HideFromProviderlist: hideFromProviderlistFunc.(func() bool),
-						PrintDoc:             printDocFunc.(func(bool)),
-						Available:            availableFunc.(func() bool),
-						State:                stateFunc.(func(string) *pb.ProviderStateResponse),
-					}
-
-					if setup {
-						go provider.Setup()
-					}
-
-					mut.Lock()
-					Providers[*provider.Name] = provider
-					have[base] = struct{}{}
-					mut.Unlock()
-
-					slog.Info("providers", "loaded", *provider.Name)
+				info := Plugin{Path: path, Provider: fn, ABI: -1, Status: fmt.Sprintf("rejected: %s", err)}
+
+				if m != nil {
+					info.ABI = m.ABI
 				}
+
+				mut.Lock()
+				Disabled[fn] = info.Status
+				mut.Unlock()
+
+				record(info)
+
+				return nil
+			}
+
+			if add(fn, m, Plugin{Path: path, ABI: m.ABI}) {
+				mut.Lock()
+				have[base] = "shadowed by a plugin of the same name"
+				mut.Unlock()
 			}
 
 			return err
@@ -212,4 +530,32 @@ func Load(setup bool) {
 			os.Exit(1)
 		}
 	}
+
+	for _, v := range cfg.ExternalProviders {
+		info := Plugin{Path: v.Command, Provider: v.Name, ABI: -1, External: true}
+
+		_, taken := Providers[v.Name]
+
+		switch {
+		case v.Name == "" || v.Command == "":
+			info.Status = "rejected: name and command are required"
+		case taken:
+			info.Status = "rejected: name is taken by another provider"
+		case slices.Contains(ignored, v.Name):
+			info.Status = "ignored by config"
+			Disabled[v.Name] = info.Status
+		}
+
+		if info.Status != "" {
+			slog.Info("providers", "external", v.Name, "status", info.Status)
+			record(info)
+
+			continue
+		}
+
+		m := externalManifest(v)
+		info.ABI = m.ABI
+
+		add(v.Name, m, info)
+	}
 }
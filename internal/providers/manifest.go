@@ -0,0 +1,140 @@
+package providers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"plugin"
+	"strings"
+	"sync"
+
+	"github.com/abenz1267/elephant/v2/pkg/common"
+	"github.com/abenz1267/elephant/v2/pkg/pb/pb"
+)
+
+// Plugin is a plugin file found while loading the providers.
+type Plugin struct {
+	Path     string
+	Provider string
+	// ABI is the plugin ABI version, 0 for plugins without manifest and -1 if
+	// the plugin wasn't opened.
+	ABI int
+	// Status is "loaded" or the reason the plugin wasn't loaded.
+	Status string
+	// Optional lists the optional manifest fields the plugin sets.
+	Optional []string
+	// External is set for providers running as separate processes, Path being
+	// their command.
+	External bool
+	// Builtin is set for providers compiled into elephant.
+	Builtin bool
+}
+
+// readManifest reads the manifest of a plugin. Plugins without one are read
+// symbol by symbol. The returned manifest might be set even if it's invalid.
+func readManifest(p *plugin.Plugin) (*common.Manifest, error) {
+	sym, err := p.Lookup("Manifest")
+	if err != nil {
+		return legacyManifest(p)
+	}
+
+	m, ok := sym.(*common.Manifest)
+	if !ok {
+		return nil, fmt.Errorf("Manifest has type %T, expected *common.Manifest", sym)
+	}
+
+	if m.ABI != common.ABIVersion {
+		return m, fmt.Errorf("built for plugin ABI %d, elephant supports %d", m.ABI, common.ABIVersion)
+	}
+
+	if missing := m.Missing(); len(missing) > 0 {
+		return m, fmt.Errorf("manifest is missing %s", strings.Join(missing, ", "))
+	}
+
+	return m, nil
+}
+
+// legacyManifest builds a manifest from the symbols exported by plugins
+// predating the manifest.
+func legacyManifest(p *plugin.Plugin) (*common.Manifest, error) {
+	errs := []error{}
+
+	m := &common.Manifest{
+		Available:            symbol[func() bool](p, "Available", true, &errs),
+		Setup:                symbol[func()](p, "Setup", true, &errs),
+		LoadConfig:           symbol[func()](p, "LoadConfig", true, &errs),
+		PrintDoc:             symbol[func(bool)](p, "PrintDoc", true, &errs),
+		Icon:                 symbol[func() string](p, "Icon", true, &errs),
+		HideFromProviderlist: symbol[func() bool](p, "HideFromProviderlist", true, &errs),
+		State:                symbol[func(string) *pb.ProviderStateResponse](p, "State", true, &errs),
+		Activate:             symbol[func(bool, string, string, string, string, uint8, net.Conn)](p, "Activate", true, &errs),
+		Query:                symbol[func(net.Conn, string, bool, bool, uint8) []*pb.QueryResponse_Item](p, "Query", true, &errs),
+		Shutdown:             symbol[func()](p, "Shutdown", false, &errs),
+		ActivateErr:          symbol[func(bool, string, string, string, string, uint8, net.Conn) error](p, "ActivateErr", false, &errs),
+		QueryContext:         symbol[func(context.Context, net.Conn, string, bool, bool, uint8) []*pb.QueryResponse_Item](p, "QueryContext", false, &errs),
+		CheckConfig:          symbol[func() []common.ConfigIssue](p, "CheckConfig", false, &errs),
+		Metrics:              symbol[func() []common.Metric](p, "Metrics", false, &errs),
+	}
+
+	if v := symbol[*string](p, "Name", true, &errs); v != nil {
+		m.Name = *v
+	}
+
+	m.NamePretty = symbol[*string](p, "NamePretty", true, &errs)
+
+	if v := symbol[*[]common.Requirement](p, "Requirements", false, &errs); v != nil {
+		m.Requirements = *v
+	}
+
+	return m, errors.Join(errs...)
+}
+
+// symbol looks up an exported function or variable of the expected type.
+// Variables are looked up as pointers. Errors are appended to errs.
+func symbol[T any](p *plugin.Plugin, name string, required bool, errs *[]error) T {
+	var res T
+
+	sym, err := p.Lookup(name)
+	if err != nil {
+		if required {
+			*errs = append(*errs, fmt.Errorf("missing %s", name))
+		}
+
+		return res
+	}
+
+	res, ok := sym.(T)
+	if !ok {
+		*errs = append(*errs, fmt.Errorf("%s has type %T, expected %T", name, sym, res))
+	}
+
+	return res
+}
+
+// newProvider creates the provider described by a valid manifest.
+func newProvider(m *common.Manifest) Provider {
+	return Provider{
+		Name:                 &m.Name,
+		NamePretty:           m.NamePretty,
+		Available:            m.Available,
+		Setup:                m.Setup,
+		LoadConfig:           m.LoadConfig,
+		PrintDoc:             m.PrintDoc,
+		Icon:                 m.Icon,
+		HideFromProviderlist: m.HideFromProviderlist,
+		State:                m.State,
+		Activate:             m.Activate,
+		Query:                m.Query,
+		Shutdown:             m.Shutdown,
+		ActivateErr:          m.ActivateErr,
+		QueryContext:         m.QueryContext,
+		CheckConfig:          m.CheckConfig,
+		Metrics:              m.Metrics,
+		setup: &setupState{
+			mode: common.SetupEager,
+			done: make(chan struct{}),
+		},
+		cfgMut: &sync.RWMutex{},
+	}
+}
@@ -0,0 +1,33 @@
+package providers
+
+import (
+	"testing"
+
+	"github.com/abenz1267/elephant/v2/pkg/common"
+)
+
+func TestNamePrettyFollowsLoadConfig(t *testing.T) {
+	namePretty := "Default"
+
+	m := &common.Manifest{
+		Name:       "test",
+		NamePretty: &namePretty,
+		LoadConfig: func() {
+			namePretty = "Configured"
+		},
+	}
+
+	p := newProvider(m)
+
+	if v := p.PrettyName(); v != "Default" {
+		t.Fatalf("NamePretty = %q, expected %q", v, "Default")
+	}
+
+	if err := p.RunLoadConfig(); err != nil {
+		t.Fatal(err)
+	}
+
+	if v := p.PrettyName(); v != "Configured" {
+		t.Errorf("NamePretty = %q after LoadConfig, expected %q", v, "Configured")
+	}
+}
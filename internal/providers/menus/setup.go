@@ -1,7 +1,8 @@
-package main
+package menus
 
 import (
 	_ "embed"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net"
@@ -23,10 +24,28 @@ import (
 var (
 	Name       = "menus"
 	NamePretty = "Menus"
+	logger     = slog.Default()
 	h          = history.Load(Name)
 	host       = ""
 )
 
+var Manifest = common.Manifest{
+	ABI:                  common.ABIVersion,
+	Name:                 Name,
+	NamePretty:           &NamePretty,
+	Available:            Available,
+	Setup:                Setup,
+	LoadConfig:           LoadConfig,
+	PrintDoc:             PrintDoc,
+	Icon:                 Icon,
+	HideFromProviderlist: HideFromProviderlist,
+	State:                State,
+	Activate:             Activate,
+	Query:                Query,
+	ActivateErr:          ActivateErr,
+	Logger:               &logger,
+}
+
 //go:embed README.md
 var readme string
 
@@ -56,6 +75,12 @@ const (
 )
 
 func Activate(single bool, identifier, action string, query string, args string, format uint8, conn net.Conn) {
+	if err := ActivateErr(single, identifier, action, query, args, format, conn); err != nil {
+		logger.Error(Name, "activate", err)
+	}
+}
+
+func ActivateErr(single bool, identifier, action string, query string, args string, format uint8, conn net.Conn) error {
 	switch action {
 	case ActionGoParent:
 		identifier = strings.TrimPrefix(identifier, "menus:")
@@ -68,7 +93,7 @@ func Activate(single bool, identifier, action string, query string, args string,
 		}
 	case history.ActionDelete:
 		h.Remove(identifier)
-		return
+		return nil
 	default:
 		var e common.Entry
 		var menu *common.Menu
@@ -86,6 +111,10 @@ func Activate(single bool, identifier, action string, query string, args string,
 
 		if strings.HasPrefix(identifier, "menus:") {
 			splits := strings.Split(identifier, ":")
+			if len(splits) < 3 {
+				return fmt.Errorf("invalid identifier: %s", identifier)
+			}
+
 			submenu = splits[1]
 			m = splits[2]
 		} else {
@@ -109,7 +138,7 @@ func Activate(single bool, identifier, action string, query string, args string,
 
 		if submenu != "" && action == "menus:open" {
 			handlers.ProviderUpdated <- fmt.Sprintf("%s:%s", Name, submenu)
-			return
+			return nil
 		}
 
 		run := ""
@@ -129,6 +158,10 @@ func Activate(single bool, identifier, action string, query string, args string,
 			}
 		}
 
+		if run == "" && menu == nil {
+			return fmt.Errorf("unknown item: %s", identifier)
+		}
+
 		if run == "" {
 			if len(menu.Actions) != 0 {
 				if val, ok := menu.Actions[action]; ok {
@@ -142,34 +175,37 @@ func Activate(single bool, identifier, action string, query string, args string,
 		}
 
 		if run == "" {
-			return
+			return nil
 		}
 
 		if after, ok := strings.CutPrefix(run, "lua:"); ok {
 			if menu == nil {
-				return
+				return nil
 			}
 
 			state := menu.NewLuaState()
 
-			if state != nil {
-				functionName := after
+			if state == nil {
+				return fmt.Errorf("no lua state available for menu %s", menu.Name)
+			}
+
+			functionName := after
 
-				if err := state.CallByParam(lua.P{
-					Fn:      state.GetGlobal(functionName),
-					NRet:    0,
-					Protect: true,
-				}, lua.LString(e.Value), lua.LString(args), lua.LString(query)); err != nil {
-					slog.Error(Name, "lua function call", err, "function", functionName)
-				}
+			err := state.CallByParam(lua.P{
+				Fn:      state.GetGlobal(functionName),
+				NRet:    0,
+				Protect: true,
+			}, lua.LString(e.Value), lua.LString(args), lua.LString(query))
 
-				if menu.History {
-					h.Save(query, identifier)
-				}
-			} else {
-				slog.Error(Name, "no lua state available for menu", menu.Name)
+			if menu.History {
+				h.Save(query, identifier)
+			}
+
+			if err != nil {
+				return fmt.Errorf("lua function %s: %w", functionName, err)
 			}
-			return
+
+			return nil
 		}
 
 		pipe := false
@@ -178,8 +214,7 @@ func Activate(single bool, identifier, action string, query string, args string,
 			clipboard := common.ClipboardText()
 
 			if clipboard == "" {
-				slog.Error(Name, "activate", "empty clipboard")
-				return
+				return errors.New("empty clipboard")
 			}
 
 			run = strings.ReplaceAll(run, "%CLIPBOARD%", clipboard)
@@ -209,7 +244,7 @@ func Activate(single bool, identifier, action string, query string, args string,
 
 		out, err := cmd.CombinedOutput()
 		if err != nil {
-			slog.Error(Name, "activate", err, "msg", out)
+			err = fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
 		} else {
 			go func() {
 				cmd.Wait()
@@ -220,12 +255,15 @@ func Activate(single bool, identifier, action string, query string, args string,
 			h.Save(query, identifier)
 		}
 
-		if slices.Contains(menu.AsyncActions, action) {
+		if menu != nil && slices.Contains(menu.AsyncActions, action) {
 			updated := itemToEntry(format, query, conn, menu.Actions, menu.NamePretty, single, menu.Icon, &e)
 			handlers.UpdateItem(format, query, conn, updated)
-
 		}
+
+		return err
 	}
+
+	return nil
 }
 
 func getHaystack(entry common.Entry, menu *common.Menu) []string {
@@ -312,7 +350,7 @@ func Query(conn net.Conn, query string, single bool, exact bool, format uint8) [
 		}
 	}
 
-	slog.Debug(Name, "query", time.Since(start))
+	logger.Debug(Name, "query", time.Since(start))
 
 	return entries
 }
@@ -0,0 +1,6 @@
+// Package main builds the niriactions provider as plugin.
+package main
+
+import "github.com/abenz1267/elephant/v2/internal/providers/niriactions"
+
+var Manifest = niriactions.Manifest
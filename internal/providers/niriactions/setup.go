@@ -1,4 +1,4 @@
-package main
+package niriactions
 
 import (
 	"fmt"
@@ -20,11 +20,29 @@ import (
 var (
 	Name       = "niriactions"
 	NamePretty = "Niri Actions"
+	logger     = slog.Default()
 	config     *Config
 	actions    = make(map[string]string)
 	h          = history.Load(Name)
 )
 
+var Manifest = common.Manifest{
+	ABI:                  common.ABIVersion,
+	Name:                 Name,
+	NamePretty:           &NamePretty,
+	Available:            Available,
+	Setup:                Setup,
+	LoadConfig:           LoadConfig,
+	PrintDoc:             PrintDoc,
+	Icon:                 Icon,
+	HideFromProviderlist: HideFromProviderlist,
+	State:                State,
+	Activate:             Activate,
+	Query:                Query,
+	ActivateErr:          ActivateErr,
+	Logger:               &logger,
+}
+
 //go:embed README.md
 var readme string
 
@@ -43,14 +61,10 @@ func Setup() {
 	LoadConfig()
 
 	parseActions()
-
-	if config.NamePretty != "" {
-		NamePretty = config.NamePretty
-	}
 }
 
 func LoadConfig() {
-	config = &Config{
+	cfg := &Config{
 		Config: common.Config{
 			Icon:     "view-grid",
 			MinScore: 20,
@@ -60,7 +74,13 @@ func LoadConfig() {
 		HistoryWhenEmpty: true,
 	}
 
-	common.LoadConfig(Name, config)
+	common.LoadConfig(Name, cfg)
+
+	if cfg.NamePretty != "" {
+		NamePretty = cfg.NamePretty
+	}
+
+	config = cfg
 }
 
 func Available() bool {
@@ -68,7 +88,7 @@ func Available() bool {
 		return true
 	}
 
-	slog.Info(Name, "available", "not a niri session. disabling")
+	logger.Info(Name, "available", "not a niri session. disabling")
 	return false
 }
 
@@ -81,10 +101,16 @@ func PrintDoc(write bool) {
 }
 
 func Activate(single bool, identifier, action string, query string, args string, format uint8, conn net.Conn) {
+	if err := ActivateErr(single, identifier, action, query, args, format, conn); err != nil {
+		logger.Error(Name, "activate", err)
+	}
+}
+
+func ActivateErr(single bool, identifier, action string, query string, args string, format uint8, conn net.Conn) error {
 	switch action {
 	case history.ActionDelete:
 		h.Remove(identifier)
-		return
+		return nil
 	case ActionExecute:
 		time.Sleep(time.Duration(config.ActionDelay) * time.Millisecond)
 
@@ -93,19 +119,22 @@ func Activate(single bool, identifier, action string, query string, args string,
 
 		cmd := exec.Command("niri", run...)
 
-		err := cmd.Start()
-		if err != nil {
-			slog.Error(Name, "activate", err)
-		} else {
-			go func() {
-				cmd.Wait()
-			}()
+		if err := cmd.Start(); err != nil {
+			return err
 		}
 
+		go func() {
+			cmd.Wait()
+		}()
+
 		if config.History {
 			h.Save(query, identifier)
 		}
+	default:
+		return fmt.Errorf("unknown action: %s", action)
 	}
+
+	return nil
 }
 
 func Query(conn net.Conn, query string, single bool, exact bool, _ uint8) []*pb.QueryResponse_Item {
@@ -159,7 +188,7 @@ func Query(conn net.Conn, query string, single bool, exact bool, _ uint8) []*pb.
 		}
 	}
 
-	slog.Debug(Name, "query", time.Since(start))
+	logger.Debug(Name, "query", time.Since(start))
 
 	return entries
 }
@@ -1,4 +1,4 @@
-package main
+package nirisessions
 
 import (
 	"bufio"
@@ -22,9 +22,27 @@ import (
 var (
 	Name       = "nirisessions"
 	NamePretty = "Niri Sessions"
+	logger     = slog.Default()
 	config     *Config
 )
 
+var Manifest = common.Manifest{
+	ABI:                  common.ABIVersion,
+	Name:                 Name,
+	NamePretty:           &NamePretty,
+	Available:            Available,
+	Setup:                Setup,
+	LoadConfig:           LoadConfig,
+	PrintDoc:             PrintDoc,
+	Icon:                 Icon,
+	HideFromProviderlist: HideFromProviderlist,
+	State:                State,
+	Activate:             Activate,
+	Query:                Query,
+	ActivateErr:          ActivateErr,
+	Logger:               &logger,
+}
+
 //go:embed README.md
 var readme string
 
@@ -56,21 +74,23 @@ type Window struct {
 
 func Setup() {
 	LoadConfig()
-
-	if config.NamePretty != "" {
-		NamePretty = config.NamePretty
-	}
 }
 
 func LoadConfig() {
-	config = &Config{
+	cfg := &Config{
 		Config: common.Config{
 			Icon:     "view-grid",
 			MinScore: 20,
 		},
 	}
 
-	common.LoadConfig(Name, config)
+	common.LoadConfig(Name, cfg)
+
+	if cfg.NamePretty != "" {
+		NamePretty = cfg.NamePretty
+	}
+
+	config = cfg
 }
 
 func Available() bool {
@@ -78,7 +98,7 @@ func Available() bool {
 		return true
 	}
 
-	slog.Info(Name, "available", "not a niri session. disabling")
+	logger.Info(Name, "available", "not a niri session. disabling")
 	return false
 }
 
@@ -107,12 +127,12 @@ func monitor(appid string, res chan int) {
 
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
-		slog.Error(Name, "monitor", err)
+		logger.Error(Name, "monitor", err)
 		return
 	}
 
 	if err := cmd.Start(); err != nil {
-		slog.Error(Name, "monitor", err)
+		logger.Error(Name, "monitor", err)
 		return
 	}
 
@@ -122,7 +142,7 @@ func monitor(appid string, res chan int) {
 		var e OpenedOrChangedEvent
 		err := json.Unmarshal(scanner.Bytes(), &e)
 		if err != nil {
-			slog.Error(Name, "event unmarshal", err)
+			logger.Error(Name, "event unmarshal", err)
 			continue
 		}
 
@@ -133,18 +153,27 @@ func monitor(appid string, res chan int) {
 	}
 
 	if err := scanner.Err(); err != nil {
-		slog.Error(Name, "monitor", err)
+		logger.Error(Name, "monitor", err)
 		return
 	}
 
 	if err := cmd.Wait(); err != nil {
-		slog.Error(Name, "monitor", err)
+		logger.Error(Name, "monitor", err)
 		return
 	}
 }
 
 func Activate(single bool, identifier, action string, query string, args string, format uint8, conn net.Conn) {
-	i, _ := strconv.Atoi(identifier)
+	if err := ActivateErr(single, identifier, action, query, args, format, conn); err != nil {
+		logger.Error(Name, "activate", err)
+	}
+}
+
+func ActivateErr(single bool, identifier, action string, query string, args string, format uint8, conn net.Conn) error {
+	i, err := strconv.Atoi(identifier)
+	if err != nil || i < 0 || i >= len(config.Sessions) {
+		return fmt.Errorf("unknown session: %s", identifier)
+	}
 
 	s := config.Sessions[i]
 
@@ -159,16 +188,14 @@ func Activate(single bool, identifier, action string, query string, args string,
 			go monitor(w.AppID, res)
 
 			cmd := exec.Command("sh", "-c", w.Command)
-			err := cmd.Start()
-			if err != nil {
-				slog.Error(Name, "activate", err)
-				return
-			} else {
-				go func() {
-					cmd.Wait()
-				}()
+			if err := cmd.Start(); err != nil {
+				return err
 			}
 
+			go func() {
+				cmd.Wait()
+			}()
+
 			id := <-res
 			idStr := strconv.Itoa(id)
 
@@ -177,10 +204,8 @@ func Activate(single bool, identifier, action string, query string, args string,
 
 				cmd := exec.Command("sh", "-c", toRun)
 
-				err := cmd.Run()
-				if err != nil {
-					slog.Error(Name, "activate after", err)
-					return
+				if err := cmd.Run(); err != nil {
+					return fmt.Errorf("after: %w", err)
 				}
 			}
 		}
@@ -188,10 +213,8 @@ func Activate(single bool, identifier, action string, query string, args string,
 		for _, c := range v.After {
 			cmd := exec.Command("sh", "-c", c)
 
-			err := cmd.Run()
-			if err != nil {
-				slog.Error(Name, "activate after", err)
-				return
+			if err := cmd.Run(); err != nil {
+				return fmt.Errorf("after: %w", err)
 			}
 		}
 
@@ -199,13 +222,15 @@ func Activate(single bool, identifier, action string, query string, args string,
 			goWorkspaceDown()
 		}
 	}
+
+	return nil
 }
 
 func goWorkspaceDown() {
 	cmd := exec.Command("niri", "msg", "action", "focus-workspace-down")
 	err := cmd.Start()
 	if err != nil {
-		slog.Error(Name, "activate", err)
+		logger.Error(Name, "activate", err)
 		return
 	} else {
 		go func() {
@@ -244,7 +269,7 @@ func Query(conn net.Conn, query string, single bool, exact bool, _ uint8) []*pb.
 		}
 	}
 
-	slog.Debug(Name, "query", time.Since(start))
+	logger.Debug(Name, "query", time.Since(start))
 
 	return entries
 }
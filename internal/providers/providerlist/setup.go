@@ -1,4 +1,4 @@
-package main
+package providerlist
 
 import (
 	_ "embed"
@@ -18,9 +18,29 @@ import (
 var (
 	Name       = "providerlist"
 	NamePretty = "Providerlist"
+	logger     = slog.Default()
 	config     *Config
 )
 
+var Manifest = common.Manifest{
+	ABI:                  common.ABIVersion,
+	Name:                 Name,
+	NamePretty:           &NamePretty,
+	Available:            Available,
+	Setup:                Setup,
+	LoadConfig:           LoadConfig,
+	PrintDoc:             PrintDoc,
+	Icon:                 Icon,
+	HideFromProviderlist: HideFromProviderlist,
+	State:                State,
+	Activate:             Activate,
+	Query:                Query,
+	Logger:               &logger,
+}
+
+// StateDisabled is set on providers disabled after repeated crashes.
+const StateDisabled = "disabled"
+
 //go:embed README.md
 var readme string
 
@@ -31,14 +51,10 @@ type Config struct {
 
 func Setup() {
 	LoadConfig()
-
-	if config.NamePretty != "" {
-		NamePretty = config.NamePretty
-	}
 }
 
 func LoadConfig() {
-	config = &Config{
+	cfg := &Config{
 		Config: common.Config{
 			Icon:     "applications-other",
 			MinScore: 10,
@@ -46,7 +62,13 @@ func LoadConfig() {
 		Hidden: []string{},
 	}
 
-	common.LoadConfig(Name, config)
+	common.LoadConfig(Name, cfg)
+
+	if cfg.NamePretty != "" {
+		NamePretty = cfg.NamePretty
+	}
+
+	config = cfg
 }
 
 func Available() bool {
@@ -68,8 +90,10 @@ func Query(conn net.Conn, query string, single bool, exact bool, _ uint8) []*pb.
 	start := time.Now()
 	entries := []*pb.QueryResponse_Item{}
 
-	for _, v := range providers.Providers {
-		if *v.Name == Name || v.HideFromProviderlist() {
+	for k := range providers.Providers {
+		// skips providers ignored by the active profile.
+		v, ok := providers.Get(k)
+		if !ok || *v.Name == Name || v.RunHideFromProviderlist() {
 			continue
 		}
 
@@ -91,6 +115,8 @@ func Query(conn net.Conn, query string, single bool, exact bool, _ uint8) []*pb.
 					Icon:       v.Icon,
 				}
 
+				markSuspended(e, "menus")
+
 				if query != "" {
 					e.Fuzzyinfo = &pb.QueryResponse_Item_FuzzyInfo{
 						Field: "text",
@@ -120,13 +146,15 @@ func Query(conn net.Conn, query string, single bool, exact bool, _ uint8) []*pb.
 
 			e := &pb.QueryResponse_Item{
 				Identifier: *v.Name,
-				Text:       *v.NamePretty,
-				Icon:       v.Icon(),
+				Text:       v.PrettyName(),
+				Icon:       v.RunIcon(),
 				Provider:   Name,
 				Actions:    []string{"activate"},
 				Type:       pb.QueryResponse_REGULAR,
 			}
 
+			markSuspended(e, *v.Name)
+
 			if query != "" {
 				e.Fuzzyinfo = &pb.QueryResponse_Item_FuzzyInfo{
 					Field: "text",
@@ -153,11 +181,23 @@ func Query(conn net.Conn, query string, single bool, exact bool, _ uint8) []*pb.
 		return strings.Compare(a.Text, b.Text)
 	})
 
-	slog.Debug(Name, "query", time.Since(start))
+	logger.Debug(Name, "query", time.Since(start))
 
 	return entries
 }
 
+// markSuspended marks the entries of providers disabled after repeated
+// crashes.
+func markSuspended(e *pb.QueryResponse_Item, provider string) {
+	until, ok := providers.Suspended(provider)
+	if !ok {
+		return
+	}
+
+	e.Subtext = fmt.Sprintf("disabled after repeated crashes until %s", until.Format(time.TimeOnly))
+	e.State = append(e.State, StateDisabled)
+}
+
 func Icon() string {
 	return ""
 }
@@ -1,5 +1,5 @@
 // Package runner provides access to binaries in $PATH.
-package main
+package runner
 
 import (
 	"crypto/md5"
@@ -27,8 +27,26 @@ import (
 var (
 	Name       = "runner"
 	NamePretty = "Runner"
+	logger     = slog.Default()
 )
 
+var Manifest = common.Manifest{
+	ABI:                  common.ABIVersion,
+	Name:                 Name,
+	NamePretty:           &NamePretty,
+	Available:            Available,
+	Setup:                Setup,
+	LoadConfig:           LoadConfig,
+	PrintDoc:             PrintDoc,
+	Icon:                 Icon,
+	HideFromProviderlist: HideFromProviderlist,
+	State:                State,
+	Activate:             Activate,
+	Query:                Query,
+	ActivateErr:          ActivateErr,
+	Logger:               &logger,
+}
+
 //go:embed README.md
 var readme string
 
@@ -62,10 +80,6 @@ func Setup() {
 
 	LoadConfig()
 
-	if config.NamePretty != "" {
-		NamePretty = config.NamePretty
-	}
-
 	if len(config.Explicits) == 0 {
 		bins := []string{}
 
@@ -88,7 +102,7 @@ func Setup() {
 			}
 
 			if err := fastwalk.Walk(&conf, p, walkFn); err != nil {
-				slog.Error("runner", "load", err)
+				logger.Error("runner", "load", err)
 			}
 		}
 
@@ -117,11 +131,11 @@ func Setup() {
 		}
 	}
 
-	slog.Info(Name, "executables", len(items), "time", time.Since(start))
+	logger.Info(Name, "executables", len(items), "time", time.Since(start))
 }
 
 func LoadConfig() {
-	config = &Config{
+	cfg := &Config{
 		Config: common.Config{
 			Icon:     "utilities-terminal",
 			MinScore: 50,
@@ -131,7 +145,13 @@ func LoadConfig() {
 		GenericText:      "run: ",
 	}
 
-	common.LoadConfig(Name, config)
+	common.LoadConfig(Name, cfg)
+
+	if cfg.NamePretty != "" {
+		NamePretty = cfg.NamePretty
+	}
+
+	config = cfg
 }
 
 func Available() bool {
@@ -152,10 +172,16 @@ const (
 )
 
 func Activate(single bool, identifier, action string, query string, args string, format uint8, conn net.Conn) {
+	if err := ActivateErr(single, identifier, action, query, args, format, conn); err != nil {
+		logger.Error(Name, "activate", err)
+	}
+}
+
+func ActivateErr(single bool, identifier, action string, query string, args string, format uint8, conn net.Conn) error {
 	switch action {
 	case history.ActionDelete:
 		h.Remove(identifier)
-		return
+		return nil
 	case ActionRunInTerminal, ActionRun:
 		bin := ""
 
@@ -183,8 +209,7 @@ func Activate(single bool, identifier, action string, query string, args string,
 
 		err := cmd.Start()
 		if err != nil {
-			slog.Error(Name, "activate", err)
-			return
+			return err
 		} else {
 			go func() {
 				cmd.Wait()
@@ -195,9 +220,10 @@ func Activate(single bool, identifier, action string, query string, args string,
 			h.Save(query, identifier)
 		}
 	default:
-		slog.Error(Name, "activate", fmt.Sprintf("unknown action: %s", action))
-		return
+		return fmt.Errorf("unknown action: %s", action)
 	}
+
+	return nil
 }
 
 func Query(conn net.Conn, query string, single bool, exact bool, _ uint8) []*pb.QueryResponse_Item {
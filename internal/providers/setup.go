@@ -0,0 +1,82 @@
+package providers
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/abenz1267/elephant/v2/pkg/common"
+)
+
+// setupState tracks the setup of a provider, which runs at most once.
+type setupState struct {
+	mode common.SetupMode
+	once sync.Once
+	done chan struct{}
+}
+
+var (
+	// eagerSetups waits for the setup of the eager providers.
+	eagerSetups sync.WaitGroup
+	// idleSetups holds the providers set up by SetupIdle.
+	idleSetups []Provider
+)
+
+// runSetup sets the provider up, unless that already happened.
+func (p Provider) runSetup() {
+	p.setup.once.Do(func() {
+		start := time.Now()
+
+		p.Recover(p.Setup)
+		close(p.setup.done)
+
+		slog.Info("providers", "setup", *p.Name, "mode", p.setup.mode, "time", time.Since(start))
+	})
+}
+
+// awaitSetup sets up lazy and idle providers on first use and waits until
+// they are ready or ctx is done. Eager providers are used right away, they
+// handle queries while setting up.
+func (p Provider) awaitSetup(ctx context.Context) error {
+	if p.setup == nil || p.setup.mode == common.SetupEager {
+		return nil
+	}
+
+	if p.ready() {
+		return nil
+	}
+
+	go p.runSetup()
+
+	select {
+	case <-p.setup.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ready reports whether the setup of the provider is done.
+func (p Provider) ready() bool {
+	if p.setup == nil {
+		return true
+	}
+
+	select {
+	case <-p.setup.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// SetupIdle sets up the idle providers one after another, once the eager
+// ones are done. Providers used in the meantime are set up right away.
+func SetupIdle() {
+	eagerSetups.Wait()
+
+	for _, v := range idleSetups {
+		v.runSetup()
+	}
+}
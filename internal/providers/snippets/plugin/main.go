@@ -0,0 +1,6 @@
+// Package main builds the snippets provider as plugin.
+package main
+
+import "github.com/abenz1267/elephant/v2/internal/providers/snippets"
+
+var Manifest = snippets.Manifest
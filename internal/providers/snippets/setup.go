@@ -1,4 +1,4 @@
-package main
+package snippets
 
 import (
 	"fmt"
@@ -20,9 +20,27 @@ import (
 var (
 	Name       = "snippets"
 	NamePretty = "Snippets"
+	logger     = slog.Default()
 	config     *Config
 )
 
+var Manifest = common.Manifest{
+	ABI:                  common.ABIVersion,
+	Name:                 Name,
+	NamePretty:           &NamePretty,
+	Available:            Available,
+	Setup:                Setup,
+	LoadConfig:           LoadConfig,
+	PrintDoc:             PrintDoc,
+	Icon:                 Icon,
+	HideFromProviderlist: HideFromProviderlist,
+	State:                State,
+	Activate:             Activate,
+	Query:                Query,
+	ActivateErr:          ActivateErr,
+	Logger:               &logger,
+}
+
 //go:embed README.md
 var readme string
 
@@ -47,14 +65,10 @@ type Snippet struct {
 
 func Setup() {
 	LoadConfig()
-
-	if config.NamePretty != "" {
-		NamePretty = config.NamePretty
-	}
 }
 
 func LoadConfig() {
-	config = &Config{
+	cfg := &Config{
 		Config: common.Config{
 			Icon:     "insert-text",
 			MinScore: 50,
@@ -65,7 +79,13 @@ func LoadConfig() {
 		PreviewAsSubtext: true,
 	}
 
-	common.LoadConfig(Name, config)
+	common.LoadConfig(Name, cfg)
+
+	if cfg.NamePretty != "" {
+		NamePretty = cfg.NamePretty
+	}
+
+	config = cfg
 }
 
 func Available() bool {
@@ -81,22 +101,33 @@ func PrintDoc(write bool) {
 }
 
 func Activate(single bool, identifier, action string, query string, args string, format uint8, conn net.Conn) {
+	if err := ActivateErr(single, identifier, action, query, args, format, conn); err != nil {
+		logger.Error(Name, "activate", err)
+	}
+}
+
+func ActivateErr(single bool, identifier, action string, query string, args string, format uint8, conn net.Conn) error {
 	time.Sleep(time.Duration(config.Delay) * time.Millisecond)
 
-	i, _ := strconv.Atoi(identifier)
+	i, err := strconv.Atoi(identifier)
+	if err != nil || i < 0 || i >= len(config.Snippets) {
+		return fmt.Errorf("unknown snippet: %s", identifier)
+	}
+
 	s := config.Snippets[i]
 
 	toRun := strings.ReplaceAll(config.Command, "%CONTENT%", shellescape.Quote(s.Content))
 	cmd := exec.Command("sh", "-c", toRun)
 
-	err := cmd.Start()
-	if err != nil {
-		slog.Error(Name, "activate", err)
-	} else {
-		go func() {
-			cmd.Wait()
-		}()
+	if err := cmd.Start(); err != nil {
+		return err
 	}
+
+	go func() {
+		cmd.Wait()
+	}()
+
+	return nil
 }
 
 func Query(conn net.Conn, query string, single bool, exact bool, _ uint8) []*pb.QueryResponse_Item {
@@ -144,7 +175,7 @@ func Query(conn net.Conn, query string, single bool, exact bool, _ uint8) []*pb.
 		}
 	}
 
-	slog.Debug(Name, "query", time.Since(start))
+	logger.Debug(Name, "query", time.Since(start))
 
 	return entries
 }
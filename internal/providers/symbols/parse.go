@@ -1,4 +1,4 @@
-package main
+package symbols
 
 import (
 	"crypto/md5"
@@ -6,7 +6,6 @@ import (
 	"encoding/hex"
 	"encoding/xml"
 	"fmt"
-	"log/slog"
 	"strconv"
 	"strings"
 )
@@ -56,7 +55,7 @@ var (
 func parseVariations() {
 	file, err := files.ReadFile("data/variations.txt")
 	if err != nil {
-		slog.Error(Name, "parsing", err)
+		logger.Error(Name, "parsing", err)
 		return
 	}
 
@@ -79,7 +78,7 @@ func parseVariations() {
 func parse() {
 	file, err := files.ReadFile(fmt.Sprintf("data/%s.xml", config.Locale))
 	if err != nil {
-		slog.Error(Name, "parsing", err)
+		logger.Error(Name, "parsing", err)
 		return
 	}
 
@@ -1,5 +1,5 @@
 // Package symbols provides symbols/emojis.
-package main
+package symbols
 
 import (
 	_ "embed"
@@ -21,9 +21,27 @@ import (
 var (
 	Name       = "symbols"
 	NamePretty = "Symbols/Emojis"
+	logger     = slog.Default()
 	h          = history.Load(Name)
 )
 
+var Manifest = common.Manifest{
+	ABI:                  common.ABIVersion,
+	Name:                 Name,
+	NamePretty:           &NamePretty,
+	Available:            Available,
+	Setup:                Setup,
+	LoadConfig:           LoadConfig,
+	PrintDoc:             PrintDoc,
+	Icon:                 Icon,
+	HideFromProviderlist: HideFromProviderlist,
+	State:                State,
+	Activate:             Activate,
+	Query:                Query,
+	ActivateErr:          ActivateErr,
+	Logger:               &logger,
+}
+
 //go:embed README.md
 var readme string
 
@@ -42,18 +60,14 @@ func Setup() {
 
 	LoadConfig()
 
-	if config.NamePretty != "" {
-		NamePretty = config.NamePretty
-	}
-
 	parseVariations()
 	parse()
 
-	slog.Info(Name, "symbols/emojis", len(symbols), "time", time.Since(start))
+	logger.Info(Name, "symbols/emojis", len(symbols), "time", time.Since(start))
 }
 
 func LoadConfig() {
-	config = &Config{
+	cfg := &Config{
 		Config: common.Config{
 			Icon:     "face-smile",
 			MinScore: 50,
@@ -64,7 +78,13 @@ func LoadConfig() {
 		Command:          "wl-copy",
 	}
 
-	common.LoadConfig(Name, config)
+	common.LoadConfig(Name, cfg)
+
+	if cfg.NamePretty != "" {
+		NamePretty = cfg.NamePretty
+	}
+
+	config = cfg
 }
 
 func Available() bool {
@@ -95,12 +115,23 @@ func PrintDoc(write bool) {
 const ActionRunCmd = "run_cmd"
 
 func Activate(single bool, identifier, action string, query string, args string, format uint8, conn net.Conn) {
+	if err := ActivateErr(single, identifier, action, query, args, format, conn); err != nil {
+		logger.Error(Name, "activate", err)
+	}
+}
+
+func ActivateErr(single bool, identifier, action string, query string, args string, format uint8, conn net.Conn) error {
 	switch action {
 	case history.ActionDelete:
 		h.Remove(identifier)
-		return
+		return nil
 	case ActionRunCmd:
-		val := symbols[identifier].CP
+		symbol, ok := symbols[identifier]
+		if !ok {
+			return fmt.Errorf("unknown symbol: %s", identifier)
+		}
+
+		val := symbol.CP
 
 		count, err := strconv.Atoi(args)
 
@@ -110,24 +141,22 @@ func Activate(single bool, identifier, action string, query string, args string,
 
 		cmd := common.ReplaceResultOrStdinCmd(config.Command, val)
 
-		err = cmd.Start()
-
-		if err != nil {
-			slog.Error(Name, "activate", err)
-			return
-		} else {
-			go func() {
-				cmd.Wait()
-			}()
+		if err := cmd.Start(); err != nil {
+			return err
 		}
 
+		go func() {
+			cmd.Wait()
+		}()
+
 		if config.History {
 			h.Save(query, identifier)
 		}
 	default:
-		slog.Error(Name, "activate", fmt.Sprintf("unknown action: %s", action))
-		return
+		return fmt.Errorf("unknown action: %s", action)
 	}
+
+	return nil
 }
 
 func Query(conn net.Conn, query string, _ bool, exact bool, _ uint8) []*pb.QueryResponse_Item {
@@ -194,7 +223,7 @@ func Query(conn net.Conn, query string, _ bool, exact bool, _ uint8) []*pb.Query
 		}
 	}
 
-	slog.Debug(Name, "query", time.Since(start))
+	logger.Debug(Name, "query", time.Since(start))
 	return entries
 }
 
@@ -1,4 +1,4 @@
-package main
+package todo
 
 import (
 	"bytes"
@@ -27,6 +27,7 @@ import (
 var (
 	Name       = "todo"
 	NamePretty = "Todo List"
+	logger     = slog.Default()
 	config     *Config
 	items      = []Item{}
 	parser     *naturaltime.Parser
@@ -34,6 +35,23 @@ var (
 	creating   bool
 )
 
+var Manifest = common.Manifest{
+	ABI:                  common.ABIVersion,
+	Name:                 Name,
+	NamePretty:           &NamePretty,
+	Available:            Available,
+	Setup:                Setup,
+	LoadConfig:           LoadConfig,
+	PrintDoc:             PrintDoc,
+	Icon:                 Icon,
+	HideFromProviderlist: HideFromProviderlist,
+	State:                State,
+	Activate:             Activate,
+	Query:                Query,
+	ActivateErr:          ActivateErr,
+	Logger:               &logger,
+}
+
 //go:embed README.md
 var readme string
 
@@ -132,7 +150,7 @@ func saveItems() {
 
 	err := os.MkdirAll(filepath.Dir(f), 0o755)
 	if err != nil {
-		slog.Error(Name, "mkdirall", err)
+		logger.Error(Name, "mkdirall", err)
 		return
 	}
 
@@ -140,7 +158,7 @@ func saveItems() {
 
 	file, err := os.Create(f)
 	if err != nil {
-		slog.Error(Name, "createfile", err)
+		logger.Error(Name, "createfile", err)
 	}
 	defer file.Close()
 
@@ -153,7 +171,7 @@ func saveItems() {
 	content := strings.Join(c, "\n")
 	_, err = file.WriteString(content)
 	if err != nil {
-		slog.Error(Name, "writefile", err)
+		logger.Error(Name, "writefile", err)
 	}
 
 	if config.w != nil {
@@ -211,10 +229,6 @@ func Setup() {
 
 	LoadConfig()
 
-	if config.NamePretty != "" {
-		NamePretty = config.NamePretty
-	}
-
 	if strings.HasPrefix(config.Location, "https://") {
 		isGit = true
 	}
@@ -236,7 +250,7 @@ func Setup() {
 }
 
 func LoadConfig() {
-	config = &Config{
+	cfg := &Config{
 		Config: common.Config{
 			Icon:     "checkbox-checked",
 			MinScore: 20,
@@ -252,7 +266,13 @@ func LoadConfig() {
 		},
 	}
 
-	common.LoadConfig(Name, config)
+	common.LoadConfig(Name, cfg)
+
+	if cfg.NamePretty != "" {
+		NamePretty = cfg.NamePretty
+	}
+
+	config = cfg
 }
 
 func Available() bool {
@@ -279,7 +299,7 @@ func notify() {
 
 				err := cmd.Start()
 				if err != nil {
-					slog.Error(Name, "notify", err)
+					logger.Error(Name, "notify", err)
 				} else {
 					if config.DuckPlayerVolumes {
 						duckPlayers()
@@ -324,15 +344,28 @@ func PrintDoc(write bool) {
 }
 
 func Activate(single bool, identifier, action string, query string, args string, format uint8, conn net.Conn) {
-	i, _ := strconv.Atoi(identifier)
+	if err := ActivateErr(single, identifier, action, query, args, format, conn); err != nil {
+		logger.Error(Name, "activate", err)
+	}
+}
+
+func ActivateErr(single bool, identifier, action string, query string, args string, format uint8, conn net.Conn) error {
+	i, err := strconv.Atoi(identifier)
+
+	switch action {
+	case ActionChangeCategory, ActionDelete, ActionMarkActive, ActionMarkInactive, ActionMarkDone:
+		if err != nil || i < 0 || i >= len(items) {
+			return fmt.Errorf("unknown item: %s", identifier)
+		}
+	}
 
 	switch action {
 	case ActionSearch:
 		creating = false
-		return
+		return nil
 	case ActionCreate:
 		creating = true
-		return
+		return nil
 	case ActionChangeCategory:
 		currentCategory := items[i].Category
 		nextCategory := ""
@@ -396,13 +429,14 @@ func Activate(single bool, identifier, action string, query string, args string,
 		}
 
 		createNew(identifier)
-		return
+		return nil
 	default:
-		slog.Error(Name, "activate", fmt.Sprintf("unknown action: %s", action))
-		return
+		return fmt.Errorf("unknown action: %s", action)
 	}
 
 	saveItems()
+
+	return nil
 }
 
 func createNew(identifier string) {
@@ -430,13 +464,13 @@ func migrateGOBtoCSV() bool {
 	if common.FileExists(file) {
 		f, err := os.ReadFile(file)
 		if err != nil {
-			slog.Error(Name, "itemsread", err)
+			logger.Error(Name, "itemsread", err)
 		} else {
 			decoder := gob.NewDecoder(bytes.NewReader(f))
 
 			err = decoder.Decode(&items)
 			if err != nil {
-				slog.Error(Name, "decoding", err)
+				logger.Error(Name, "decoding", err)
 			}
 		}
 
@@ -471,7 +505,7 @@ func loadItems() {
 	if common.FileExists(file) {
 		f, err := os.ReadFile(file)
 		if err != nil {
-			slog.Error(Name, "itemsread", err)
+			logger.Error(Name, "itemsread", err)
 		} else {
 			first := false
 
@@ -492,21 +526,21 @@ func loadItems() {
 
 				t, err := time.Parse(time.RFC1123Z, d[5])
 				if err != nil {
-					slog.Error(Name, "timeparse", err, "field", "scheduled")
+					logger.Error(Name, "timeparse", err, "field", "scheduled")
 				} else {
 					i.Scheduled = t
 				}
 
 				t, err = time.Parse(time.RFC1123Z, d[6])
 				if err != nil {
-					slog.Error(Name, "timeparse", err, "field", "started")
+					logger.Error(Name, "timeparse", err, "field", "started")
 				} else {
 					i.Started = t
 				}
 
 				t, err = time.Parse(time.RFC1123Z, d[7])
 				if err != nil {
-					slog.Error(Name, "timeparse", err, "field", "finished")
+					logger.Error(Name, "timeparse", err, "field", "finished")
 				} else {
 					i.Finished = t
 				}
@@ -514,7 +548,7 @@ func loadItems() {
 				if len(d) == 9 {
 					t, err = time.Parse(time.RFC1123Z, strings.TrimSpace(d[8]))
 					if err != nil {
-						slog.Error(Name, "timeparse", err, "field", "created")
+						logger.Error(Name, "timeparse", err, "field", "created")
 					} else {
 						i.Created = t
 					}
@@ -0,0 +1,6 @@
+// Package main builds the unicode provider as plugin.
+package main
+
+import "github.com/abenz1267/elephant/v2/internal/providers/unicode"
+
+var Manifest = unicode.Manifest
@@ -1,5 +1,5 @@
-// Package symbols provides symbols/emojis.
-package main
+// Package unicode provides unicode characters.
+package unicode
 
 import (
 	"fmt"
@@ -20,9 +20,27 @@ import (
 var (
 	Name       = "unicode"
 	NamePretty = "Unicode"
+	logger     = slog.Default()
 	h          = history.Load(Name)
 )
 
+var Manifest = common.Manifest{
+	ABI:                  common.ABIVersion,
+	Name:                 Name,
+	NamePretty:           &NamePretty,
+	Available:            Available,
+	Setup:                Setup,
+	LoadConfig:           LoadConfig,
+	PrintDoc:             PrintDoc,
+	Icon:                 Icon,
+	HideFromProviderlist: HideFromProviderlist,
+	State:                State,
+	Activate:             Activate,
+	Query:                Query,
+	ActivateErr:          ActivateErr,
+	Logger:               &logger,
+}
+
 //go:embed README.md
 var readme string
 
@@ -47,10 +65,6 @@ func Setup() {
 
 	LoadConfig()
 
-	if config.NamePretty != "" {
-		NamePretty = config.NamePretty
-	}
-
 	for v := range strings.Lines(data) {
 		if v == "" {
 			continue
@@ -60,11 +74,11 @@ func Setup() {
 		symbols[fields[1]] = fields[0]
 	}
 
-	slog.Info(Name, "loaded", time.Since(start))
+	logger.Info(Name, "loaded", time.Since(start))
 }
 
 func LoadConfig() {
-	config = &Config{
+	cfg := &Config{
 		Config: common.Config{
 			Icon:     "accessories-character-map-symbolic",
 			MinScore: 50,
@@ -75,7 +89,13 @@ func LoadConfig() {
 		Command:          "wl-copy",
 	}
 
-	common.LoadConfig(Name, config)
+	common.LoadConfig(Name, cfg)
+
+	if cfg.NamePretty != "" {
+		NamePretty = cfg.NamePretty
+	}
+
+	config = cfg
 }
 
 func Available() bool {
@@ -93,37 +113,41 @@ func PrintDoc(write bool) {
 const ActionRunCmd = "run_cmd"
 
 func Activate(single bool, identifier, action string, query string, args string, format uint8, conn net.Conn) {
+	if err := ActivateErr(single, identifier, action, query, args, format, conn); err != nil {
+		logger.Error(Name, "activate", err)
+	}
+}
+
+func ActivateErr(single bool, identifier, action string, query string, args string, format uint8, conn net.Conn) error {
 	switch action {
 	case history.ActionDelete:
 		h.Remove(identifier)
-		return
+		return nil
 	case ActionRunCmd:
 		codePoint, err := strconv.ParseInt(symbols[identifier], 16, 32)
 		if err != nil {
-			slog.Error(Name, "activate parse unicode", err)
-			return
+			return fmt.Errorf("parse unicode: %w", err)
 		}
 		toUse := string(rune(codePoint))
 
 		cmd := common.ReplaceResultOrStdinCmd(config.Command, toUse)
 
-		err = cmd.Start()
-		if err != nil {
-			slog.Error(Name, "activate run cmd", err)
-			return
-		} else {
-			go func() {
-				cmd.Wait()
-			}()
+		if err := cmd.Start(); err != nil {
+			return fmt.Errorf("run cmd: %w", err)
 		}
 
+		go func() {
+			cmd.Wait()
+		}()
+
 		if config.History {
 			h.Save(query, identifier)
 		}
 	default:
-		slog.Error(Name, "activate", fmt.Sprintf("unknown action: %s", action))
-		return
+		return fmt.Errorf("unknown action: %s", action)
 	}
+
+	return nil
 }
 
 func Query(conn net.Conn, query string, _ bool, exact bool, _ uint8) []*pb.QueryResponse_Item {
@@ -166,7 +190,7 @@ func Query(conn net.Conn, query string, _ bool, exact bool, _ uint8) []*pb.Query
 		}
 	}
 
-	slog.Debug(Name, "query", time.Since(start))
+	logger.Debug(Name, "query", time.Since(start))
 	return entries
 }
 
@@ -0,0 +1,6 @@
+// Package main builds the websearch provider as plugin.
+package main
+
+import "github.com/abenz1267/elephant/v2/internal/providers/websearch"
+
+var Manifest = websearch.Manifest
@@ -1,7 +1,8 @@
-package main
+package websearch
 
 import (
 	_ "embed"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net"
@@ -24,11 +25,31 @@ import (
 var (
 	Name       = "websearch"
 	NamePretty = "Websearch"
+	logger     = slog.Default()
 	config     *Config
 	prefixes   = make(map[string]int)
 	h          = history.Load(Name)
+	issues     []common.ConfigIssue
 )
 
+var Manifest = common.Manifest{
+	ABI:                  common.ABIVersion,
+	Name:                 Name,
+	NamePretty:           &NamePretty,
+	Available:            Available,
+	Setup:                Setup,
+	LoadConfig:           LoadConfig,
+	PrintDoc:             PrintDoc,
+	Icon:                 Icon,
+	HideFromProviderlist: HideFromProviderlist,
+	State:                State,
+	Activate:             Activate,
+	Query:                Query,
+	ActivateErr:          ActivateErr,
+	CheckConfig:          CheckConfig,
+	Logger:               &logger,
+}
+
 //go:embed README.md
 var readme string
 
@@ -53,64 +74,108 @@ type Engine struct {
 
 func Setup() {
 	LoadConfig()
+}
+
+func LoadConfig() {
+	cfg := &Config{
+		Config: common.Config{
+			Icon:     "applications-internet",
+			MinScore: 20,
+		},
+		History:           true,
+		HistoryWhenEmpty:  false,
+		EnginesAsActions:  false,
+		TextPrefix:        "Search: ",
+		Command:           "xdg-open",
+		AlwaysShowDefault: true,
+	}
+
+	common.LoadConfig(Name, cfg)
+
+	engineIssues := checkEngines(cfg.Engines)
 
-	if config.NamePretty != "" {
-		NamePretty = config.NamePretty
+	for _, v := range engineIssues {
+		logger.Warn(Name, "config", v.String())
 	}
 
-	if len(config.Engines) == 0 {
-		config.Engines = append(config.Engines, Engine{
+	if len(cfg.Engines) == 0 {
+		cfg.Engines = append(cfg.Engines, Engine{
 			Name:    "Google",
 			Default: true,
 			URL:     "https://www.google.com/search?q=%TERM%",
 		})
 	}
 
-	if len(config.Engines) == 1 {
-		config.Engines[0].Default = true
+	if len(cfg.Engines) == 1 {
+		cfg.Engines[0].Default = true
 	}
 
-	handlers.WebsearchAlwaysShow = config.AlwaysShowDefault
+	slices.SortFunc(cfg.Engines, func(a, b Engine) int {
+		if a.Default {
+			return -1
+		}
+
+		if b.Default {
+			return 1
+		}
+
+		return 0
+	})
+
+	defaults := 0
+	names := make(map[string]string)
+	enginePrefixes := make(map[string]int)
 
-	for k, v := range config.Engines {
+	for k, v := range cfg.Engines {
 		if v.Default {
-			handlers.MaxGlobalItemsToDisplayWebsearch++
+			defaults++
 		}
 
 		if v.Prefix != "" {
-			prefixes[v.Prefix] = k
-			handlers.WebsearchPrefixes[v.Prefix] = v.Name
+			enginePrefixes[v.Prefix] = k
+			names[v.Prefix] = v.Name
 		}
 	}
 
-	slices.SortFunc(config.Engines, func(a, b Engine) int {
-		if a.Default {
-			return -1
+	if cfg.NamePretty != "" {
+		NamePretty = cfg.NamePretty
+	}
+
+	config = cfg
+	prefixes = enginePrefixes
+	issues = engineIssues
+
+	handlers.SetWebsearch(defaults, cfg.AlwaysShowDefault, names)
+}
+
+// checkEngines reports engines without url and duplicate prefixes, which
+// would shadow each other.
+func checkEngines(engines []Engine) []common.ConfigIssue {
+	res := []common.ConfigIssue{}
+	seen := make(map[string]string)
+
+	for k, v := range engines {
+		if strings.TrimSpace(v.URL) == "" {
+			res = append(res, common.ConfigIssueAt(Name, fmt.Sprintf("entries[%d].url", k), fmt.Sprintf("engine %q has no url", v.Name)))
 		}
 
-		if b.Default {
-			return 1
+		if v.Prefix == "" {
+			continue
 		}
 
-		return 0
-	})
-}
+		if other, ok := seen[v.Prefix]; ok {
+			res = append(res, common.ConfigIssueAt(Name, fmt.Sprintf("entries[%d].prefix", k), fmt.Sprintf("prefix %q of engine %q is already used by %q", v.Prefix, v.Name, other)))
+			continue
+		}
 
-func LoadConfig() {
-	config = &Config{
-		Config: common.Config{
-			Icon:     "applications-internet",
-			MinScore: 20,
-		},
-		History:           true,
-		HistoryWhenEmpty:  false,
-		EnginesAsActions:  false,
-		TextPrefix:        "Search: ",
-		Command:           "xdg-open",
-		AlwaysShowDefault: true,
+		seen[v.Prefix] = v.Name
 	}
 
-	common.LoadConfig(Name, config)
+	return res
+}
+
+func CheckConfig() []common.ConfigIssue {
+	return issues
 }
 
 func Available() bool {
@@ -131,6 +196,12 @@ const (
 )
 
 func Activate(single bool, identifier, action string, query string, args string, format uint8, conn net.Conn) {
+	if err := ActivateErr(single, identifier, action, query, args, format, conn); err != nil {
+		logger.Error(Name, "activate", err)
+	}
+}
+
+func ActivateErr(single bool, identifier, action string, query string, args string, format uint8, conn net.Conn) error {
 	switch action {
 	case ActionOpenURL:
 		cmd := exec.Command("sh", "-c", strings.TrimSpace(fmt.Sprintf("%s %s %s", common.LaunchPrefix(), config.Command, shellescape.Quote(identifier))))
@@ -141,15 +212,15 @@ func Activate(single bool, identifier, action string, query string, args string,
 
 		err := cmd.Start()
 		if err != nil {
-			slog.Error(Name, "activate", err)
-		} else {
-			go func() {
-				cmd.Wait()
-			}()
+			return err
 		}
+
+		go func() {
+			cmd.Wait()
+		}()
 	case history.ActionDelete:
 		h.Remove(identifier)
-		return
+		return nil
 	case ActionSearch:
 		i, _ := strconv.Atoi(identifier)
 
@@ -170,8 +241,7 @@ func Activate(single bool, identifier, action string, query string, args string,
 			clipboard := common.ClipboardText()
 
 			if clipboard == "" {
-				slog.Error(Name, "activate", "empty clipbpoard")
-				return
+				return errEmptyClipboard
 			}
 
 			q = strings.ReplaceAll(os.ExpandEnv(config.Engines[i].URL), "%CLIPBOARD%", url.QueryEscape(clipboard))
@@ -179,13 +249,12 @@ func Activate(single bool, identifier, action string, query string, args string,
 			q = strings.ReplaceAll(os.ExpandEnv(config.Engines[i].URL), "%TERM%", url.QueryEscape(strings.TrimSpace(args)))
 		}
 
-		run(query, identifier, q)
+		return run(query, identifier, q)
 	default:
 		q := ""
 
 		if !config.EnginesAsActions {
-			slog.Error(Name, "activate", fmt.Sprintf("unknown action: %s", action))
-			return
+			return fmt.Errorf("unknown action: %s", action)
 		}
 
 		for _, v := range config.Engines {
@@ -199,8 +268,7 @@ func Activate(single bool, identifier, action string, query string, args string,
 			clipboard := common.ClipboardText()
 
 			if clipboard == "" {
-				slog.Error(Name, "activate", "empty clipbpoard")
-				return
+				return errEmptyClipboard
 			}
 
 			q = strings.ReplaceAll(q, "%CLIPBOARD%", url.QueryEscape(clipboard))
@@ -208,11 +276,15 @@ func Activate(single bool, identifier, action string, query string, args string,
 			q = strings.ReplaceAll(q, "%TERM%", url.QueryEscape(strings.TrimSpace(query)))
 		}
 
-		run(query, identifier, q)
+		return run(query, identifier, q)
 	}
+
+	return nil
 }
 
-func run(query, identifier, q string) {
+var errEmptyClipboard = errors.New("empty clipboard")
+
+func run(query, identifier, q string) error {
 	cmd := exec.Command("sh", "-c", strings.TrimSpace(fmt.Sprintf("%s %s %s", common.LaunchPrefix(), config.Command, shellescape.Quote(q))))
 
 	cmd.SysProcAttr = &syscall.SysProcAttr{
@@ -221,16 +293,18 @@ func run(query, identifier, q string) {
 
 	err := cmd.Start()
 	if err != nil {
-		slog.Error(Name, "activate", err)
-	} else {
-		go func() {
-			cmd.Wait()
-		}()
+		return err
 	}
 
+	go func() {
+		cmd.Wait()
+	}()
+
 	if config.History {
 		h.Save(query, identifier)
 	}
+
+	return nil
 }
 
 func Query(conn net.Conn, query string, single bool, exact bool, _ uint8) []*pb.QueryResponse_Item {
@@ -1,9 +1,8 @@
-package main
+package windows
 
 import (
 	"encoding/json"
 	"fmt"
-	"log/slog"
 	"os/exec"
 	"slices"
 
@@ -35,7 +34,7 @@ func (n NiriWorkspaceHandler) Focus(workspace string) {
 
 	err := cmd.Start()
 	if err != nil {
-		slog.Error(Name, "activate focus workspace", err)
+		logger.Error(Name, "activate focus workspace", err)
 	} else {
 		go func() {
 			cmd.Wait()
@@ -49,7 +48,7 @@ func (n NiriWorkspaceHandler) GetWorkspaces(query string, exact bool) []*pb.Quer
 	cmd := exec.Command("niri", "msg", "-j", "windows")
 	out, err := cmd.CombinedOutput()
 	if err != nil {
-		slog.Error(Name, "getNiriWorkspaces cmd", string(out))
+		logger.Error(Name, "getNiriWorkspaces cmd", string(out))
 		return entries
 	}
 
@@ -57,14 +56,14 @@ func (n NiriWorkspaceHandler) GetWorkspaces(query string, exact bool) []*pb.Quer
 
 	err = json.Unmarshal(out, &windows)
 	if err != nil {
-		slog.Error(Name, "getNiriWorkspaces unmarshal windows", err)
+		logger.Error(Name, "getNiriWorkspaces unmarshal windows", err)
 		return entries
 	}
 
 	cmd = exec.Command("niri", "msg", "-j", "workspaces")
 	out, err = cmd.CombinedOutput()
 	if err != nil {
-		slog.Error(Name, "getNiriWorkspaces cmd", string(out))
+		logger.Error(Name, "getNiriWorkspaces cmd", string(out))
 		return entries
 	}
 
@@ -82,7 +81,7 @@ func (n NiriWorkspaceHandler) GetWorkspaces(query string, exact bool) []*pb.Quer
 
 	err = json.Unmarshal(out, &workspaces)
 	if err != nil {
-		slog.Error(Name, "getNiriWorkspaces unmarshal", err)
+		logger.Error(Name, "getNiriWorkspaces unmarshal", err)
 		return entries
 	}
 
@@ -1,7 +1,8 @@
 // Package windows provides window focusing.
-package main
+package windows
 
 import (
+	"errors"
 	"fmt"
 	"io/fs"
 	"log/slog"
@@ -28,8 +29,26 @@ import (
 var (
 	Name       = "windows"
 	NamePretty = "Windows"
+	logger     = slog.Default()
 )
 
+var Manifest = common.Manifest{
+	ABI:                  common.ABIVersion,
+	Name:                 Name,
+	NamePretty:           &NamePretty,
+	Available:            Available,
+	Setup:                Setup,
+	LoadConfig:           LoadConfig,
+	PrintDoc:             PrintDoc,
+	Icon:                 Icon,
+	HideFromProviderlist: HideFromProviderlist,
+	State:                State,
+	Activate:             Activate,
+	Query:                Query,
+	ActivateErr:          ActivateErr,
+	Logger:               &logger,
+}
+
 var (
 	icons = make(map[string]string)
 	mu    sync.RWMutex
@@ -64,10 +83,6 @@ func Setup() {
 
 	LoadConfig()
 
-	if config.NamePretty != "" {
-		NamePretty = config.NamePretty
-	}
-
 	findIcons()
 
 	if config.ShowWorkspaces {
@@ -79,11 +94,11 @@ func Setup() {
 		}
 	}
 
-	slog.Info(Name, "loaded", time.Since(start))
+	logger.Info(Name, "loaded", time.Since(start))
 }
 
 func LoadConfig() {
-	config = &Config{
+	cfg := &Config{
 		Config: common.Config{
 			Icon:     "view-restore",
 			MinScore: 20,
@@ -93,7 +108,13 @@ func LoadConfig() {
 		ShowEmptyWorkspaces: false,
 	}
 
-	common.LoadConfig(Name, config)
+	common.LoadConfig(Name, cfg)
+
+	if cfg.NamePretty != "" {
+		NamePretty = cfg.NamePretty
+	}
+
+	config = cfg
 }
 
 func Available() bool {
@@ -114,16 +135,33 @@ const (
 )
 
 func Activate(single bool, identifier, action string, query string, args string, format uint8, conn net.Conn) {
+	if err := ActivateErr(single, identifier, action, query, args, format, conn); err != nil {
+		logger.Error(Name, "activate", err)
+	}
+}
+
+func ActivateErr(single bool, identifier, action string, query string, args string, format uint8, conn net.Conn) error {
 	time.Sleep(time.Duration(config.Delay) * time.Millisecond)
 
 	switch action {
 	case ActionFocus:
-		i, _ := strconv.Atoi(identifier)
+		i, err := strconv.Atoi(identifier)
+		if err != nil {
+			return fmt.Errorf("invalid window: %s", identifier)
+		}
 
-		wlr.Activate(wl.ProxyId(i))
+		return wlr.Activate(wl.ProxyId(i))
 	case ActionFocusWorkspace:
+		if workspaceHandler == nil {
+			return errors.New("focusing workspaces isn't supported by this compositor")
+		}
+
 		workspaceHandler.Focus(identifier)
+	default:
+		return fmt.Errorf("unknown action: %s", action)
 	}
+
+	return nil
 }
 
 func Query(conn net.Conn, query string, _ bool, exact bool, _ uint8) []*pb.QueryResponse_Item {
@@ -174,14 +212,14 @@ func Query(conn net.Conn, query string, _ bool, exact bool, _ uint8) []*pb.Query
 	}
 
 	if workspaceHandler == nil {
-		slog.Debug(Name, "query", time.Since(start))
+		logger.Debug(Name, "query", time.Since(start))
 
 		return entries
 	}
 
 	entries = append(entries, workspaceHandler.GetWorkspaces(query, exact)...)
 
-	slog.Debug(Name, "query", time.Since(start))
+	logger.Debug(Name, "query", time.Since(start))
 
 	return entries
 }
@@ -265,7 +303,7 @@ func findIcons() {
 		}
 
 		if err := fastwalk.Walk(&conf, root, walkFunction); err != nil {
-			slog.Error(Name, "walk", err)
+			logger.Error(Name, "walk", err)
 			continue
 		}
 	}
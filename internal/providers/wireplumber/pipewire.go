@@ -1,9 +1,8 @@
-package main
+package wireplumber
 
 import (
 	"encoding/json"
 	"fmt"
-	"log/slog"
 	"os/exec"
 	"strconv"
 	"strings"
@@ -227,7 +226,7 @@ func setDefaultDevice(deviceId int) bool {
 
 	out, err := runCommand(cmd)
 	if err != nil {
-		slog.Error(Name, "set volume", string(out))
+		logger.Error(Name, "set volume", string(out))
 		return false
 	}
 
@@ -239,7 +238,7 @@ func toggleMute(deviceId int) bool {
 
 	out, err := runCommand(cmd)
 	if err != nil {
-		slog.Error(Name, "set volume", string(out))
+		logger.Error(Name, "set volume", string(out))
 		return false
 	}
 
@@ -259,7 +258,7 @@ func setVolume(deviceId int, decrease bool) bool {
 
 	out, err := runCommand(cmd)
 	if err != nil {
-		slog.Error(Name, "set volume", string(out))
+		logger.Error(Name, "set volume", string(out))
 		return false
 	}
 
@@ -1,11 +1,10 @@
 // Package wireplumber allows to configure audio devices.
-package main
+package wireplumber
 
 import (
 	"fmt"
 	"log/slog"
 	"net"
-	"os/exec"
 	"sort"
 	"strconv"
 	"time"
@@ -21,8 +20,32 @@ import (
 var (
 	Name       = "wireplumber"
 	NamePretty = "Wireplumber"
+	logger     = slog.Default()
 )
 
+var Requirements = []common.Requirement{
+	{Name: "pipewire", Executables: []string{"pw-dump"}},
+	{Name: "wireplumber", Executables: []string{"wpctl"}},
+}
+
+var Manifest = common.Manifest{
+	ABI:                  common.ABIVersion,
+	Name:                 Name,
+	NamePretty:           &NamePretty,
+	Available:            Available,
+	Setup:                Setup,
+	LoadConfig:           LoadConfig,
+	PrintDoc:             PrintDoc,
+	Icon:                 Icon,
+	HideFromProviderlist: HideFromProviderlist,
+	State:                State,
+	Activate:             Activate,
+	Query:                Query,
+	Requirements:         Requirements,
+	ActivateErr:          ActivateErr,
+	Logger:               &logger,
+}
+
 //go:embed README.md
 var readme string
 
@@ -39,7 +62,7 @@ type Config struct {
 var config *Config
 
 func LoadConfig() {
-	config = &Config{
+	cfg := &Config{
 		Config: common.Config{
 			Icon:     "multimedia-volume-control",
 			MinScore: 50,
@@ -51,7 +74,13 @@ func LoadConfig() {
 		IconInput:       "audio-input-microphone-high",
 	}
 
-	common.LoadConfig(Name, config)
+	common.LoadConfig(Name, cfg)
+
+	if cfg.NamePretty != "" {
+		NamePretty = cfg.NamePretty
+	}
+
+	config = cfg
 }
 
 func Setup() {
@@ -59,30 +88,15 @@ func Setup() {
 
 	LoadConfig()
 
-	if config.NamePretty != "" {
-		NamePretty = config.NamePretty
-	}
-
 	if config.VolumeStepSize >= 100 {
-		slog.Error(Name, "volume-step-size", config.VolumeStepSize)
+		logger.Error(Name, "volume-step-size", config.VolumeStepSize)
 	}
 
-	slog.Info(Name, "loaded", time.Since(start))
-}
-
-func executableExists(command string) bool {
-	p, err := exec.LookPath(command)
-
-	if p == "" || err != nil {
-		slog.Info(Name, "available", fmt.Sprintf("%s not found. disabling.", command))
-		return false
-	}
-
-	return true
+	logger.Info(Name, "loaded", time.Since(start))
 }
 
 func Available() bool {
-	return executableExists("pw-dump") && executableExists("wpctl")
+	return common.RequirementsMet(Name, Requirements)
 }
 
 func PrintDoc(write bool) {
@@ -100,54 +114,56 @@ const (
 )
 
 func Activate(single bool, identifier, action string, query string, args string, format uint8, conn net.Conn) {
+	if err := ActivateErr(single, identifier, action, query, args, format, conn); err != nil {
+		logger.Error(Name, "activate", err)
+	}
+}
+
+func ActivateErr(single bool, identifier, action string, query string, args string, format uint8, conn net.Conn) error {
 	switch action {
 	case ActionIncreaseVolume:
 		deviceId, err := strconv.Atoi(identifier)
 		if err != nil {
-			slog.Error(Name, "invalid deviceId", err)
-			return
+			return fmt.Errorf("invalid deviceId: %w", err)
 		}
 
 		if ok := setVolume(deviceId, false); !ok {
-			return
+			return fmt.Errorf("increasing volume of device %d failed", deviceId)
 		}
 	case ActionDecreaseVolume:
 		deviceId, err := strconv.Atoi(identifier)
 		if err != nil {
-			slog.Error(Name, "invalid deviceId", err)
-			return
+			return fmt.Errorf("invalid deviceId: %w", err)
 		}
 
 		if ok := setVolume(deviceId, true); !ok {
-			return
+			return fmt.Errorf("decreasing volume of device %d failed", deviceId)
 		}
 	case ActionMute, ActionUnmute:
 		deviceId, err := strconv.Atoi(identifier)
 		if err != nil {
-			slog.Error(Name, "invalid deviceId", err)
-			return
+			return fmt.Errorf("invalid deviceId: %w", err)
 		}
 
 		if ok := toggleMute(deviceId); !ok {
-			return
+			return fmt.Errorf("toggling mute of device %d failed", deviceId)
 		}
 	case ActionSetDefaultDevice:
 		deviceId, err := strconv.Atoi(identifier)
 		if err != nil {
-			slog.Error(Name, "invalid deviceId", err)
-			return
+			return fmt.Errorf("invalid deviceId: %w", err)
 		}
 
 		if ok := setDefaultDevice(deviceId); !ok {
-			return
+			return fmt.Errorf("setting default device of device %d failed", deviceId)
 		}
 	default:
-		slog.Error(Name, "activate", fmt.Sprintf("unknown action: %s", action))
+		return fmt.Errorf("unknown action: %s", action)
 	}
 
 	devices, err := devices()
 	if err != nil {
-		slog.Error(Name, "activate update", err)
+		logger.Error(Name, "activate update", err)
 	}
 
 	for _, v := range devices {
@@ -156,6 +172,8 @@ func Activate(single bool, identifier, action string, query string, args string,
 			break
 		}
 	}
+
+	return nil
 }
 
 func Query(conn net.Conn, query string, _ bool, exact bool, _ uint8) []*pb.QueryResponse_Item {
@@ -164,7 +182,7 @@ func Query(conn net.Conn, query string, _ bool, exact bool, _ uint8) []*pb.Query
 
 	devices, err := devices()
 	if err != nil {
-		slog.Error(Name, "query", err)
+		logger.Error(Name, "query", err)
 		return entries
 	}
 
@@ -205,7 +223,7 @@ func Query(conn net.Conn, query string, _ bool, exact bool, _ uint8) []*pb.Query
 		}
 	}
 
-	slog.Debug(Name, "query", time.Since(start))
+	logger.Debug(Name, "query", time.Since(start))
 
 	return entries
 }
@@ -0,0 +1,249 @@
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/abenz1267/elephant/v2/internal/providers"
+	"github.com/abenz1267/elephant/v2/pkg/common"
+)
+
+// Schema is a JSON Schema (draft-07) document.
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Default              any                `json:"default,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties any                `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Definitions          map[string]*Schema `json:"definitions,omitempty"`
+}
+
+// GenerateSchema writes JSON Schemas for the configs of elephant, the menus
+// and the given provider or all providers, if none is specified, into dir.
+// Providers are expected to be loaded.
+func GenerateSchema(provider, dir string) error {
+	provider = strings.ToLower(provider)
+
+	names := []string{}
+
+	if provider == "" || provider == "elephant" {
+		names = append(names, "elephant")
+	}
+
+	for _, v := range slices.Sorted(maps.Keys(providers.Providers)) {
+		if provider == "" || provider == strings.ToLower(v) {
+			providers.Providers[v].LoadConfig()
+			names = append(names, v)
+		}
+	}
+
+	if provider == "" || provider == "menus" {
+		if !slices.Contains(names, "menus") {
+			names = append(names, "menus")
+		}
+	}
+
+	if len(names) == 0 {
+		return fmt.Errorf("unknown provider: %s", provider)
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	fmt.Println("Written:")
+	fmt.Println("--------")
+
+	for _, v := range names {
+		t := common.ConfigType(v)
+		if t == nil {
+			slog.Info("schema", "skipped", v, "reason", "no config")
+			continue
+		}
+
+		s := NewSchema(t)
+		s.Title = fmt.Sprintf("elephant %s config", v)
+		s.Properties["profiles"] = &Schema{
+			Type:                 "object",
+			Description:          "overlays merged on top of this config when their profile is active",
+			AdditionalProperties: &Schema{Ref: "#"},
+		}
+
+		if err := writeSchema(dir, v, s); err != nil {
+			return err
+		}
+
+		// menus are configured by the menu definitions as well.
+		if v == "menus" {
+			s := NewSchema(reflect.TypeFor[common.Menu]())
+			s.Title = "elephant menu definition"
+
+			if err := writeSchema(dir, "menu", s); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func writeSchema(dir, name string, s *Schema) error {
+	b, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	file := filepath.Join(dir, fmt.Sprintf("%s.schema.json", name))
+
+	if err := os.WriteFile(file, append(b, '\n'), 0o644); err != nil {
+		return err
+	}
+
+	fmt.Println(file)
+
+	return nil
+}
+
+// NewSchema creates a JSON Schema for a config struct. Fields are named by
+// their koanf or toml tag and described by their desc and default tags. Nested
+// structs are added as definitions.
+func NewSchema(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	defs := make(map[string]*Schema)
+
+	s := &Schema{
+		Schema:               "http://json-schema.org/draft-07/schema#",
+		Type:                 "object",
+		Properties:           make(map[string]*Schema),
+		AdditionalProperties: false,
+	}
+
+	addFields(s, t, defs)
+
+	if len(defs) > 0 {
+		s.Definitions = defs
+	}
+
+	return s
+}
+
+func typeSchema(t reflect.Type, defs map[string]*Schema) *Schema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t == reflect.TypeFor[time.Duration]() {
+		return &Schema{Type: "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: typeSchema(t.Elem(), defs)}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: typeSchema(t.Elem(), defs)}
+	case reflect.Struct:
+		if _, ok := defs[t.Name()]; !ok {
+			// placeholder, so recursive types terminate.
+			defs[t.Name()] = &Schema{}
+
+			s := &Schema{
+				Type:                 "object",
+				Properties:           make(map[string]*Schema),
+				AdditionalProperties: false,
+			}
+
+			addFields(s, t, defs)
+
+			defs[t.Name()] = s
+		}
+
+		return &Schema{Ref: fmt.Sprintf("#/definitions/%s", t.Name())}
+	}
+
+	return &Schema{}
+}
+
+func addFields(s *Schema, t reflect.Type, defs map[string]*Schema) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		if field.PkgPath != "" {
+			continue
+		}
+
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			addFields(s, field.Type, defs)
+			continue
+		}
+
+		name, _, _ := strings.Cut(field.Tag.Get("koanf"), ",")
+
+		if name == "" {
+			name, _, _ = strings.Cut(field.Tag.Get("toml"), ",")
+		}
+
+		// untagged fields are internal.
+		if name == "" || name == "-" {
+			continue
+		}
+
+		p := typeSchema(field.Type, defs)
+		p.Description = field.Tag.Get("desc")
+		p.Default = parseDefault(field.Type, field.Tag.Get("default"))
+
+		s.Properties[name] = p
+	}
+}
+
+// parseDefault converts the default tag to a value of the field's type. It
+// returns nil for placeholders like "<empty>" or "depends on provider".
+func parseDefault(t reflect.Type, val string) any {
+	if val == "" || strings.HasPrefix(val, "<") || strings.HasPrefix(val, "depends on") {
+		return nil
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		if b, err := strconv.ParseBool(val); err == nil {
+			return b
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if i, err := strconv.ParseInt(val, 10, 64); err == nil {
+			return i
+		}
+	case reflect.Float32, reflect.Float64:
+		if f, err := strconv.ParseFloat(val, 64); err == nil {
+			return f
+		}
+	case reflect.String:
+		return val
+	}
+
+	return nil
+}
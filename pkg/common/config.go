@@ -2,13 +2,20 @@
 package common
 
 import (
+	"errors"
+	"fmt"
 	"log/slog"
+	"maps"
 	"os"
 	"path/filepath"
+	"reflect"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/knadh/koanf/parsers/toml/v2"
-	"github.com/knadh/koanf/providers/file"
 	"github.com/knadh/koanf/providers/structs"
 	"github.com/knadh/koanf/v2"
 )
@@ -26,26 +33,144 @@ type Command struct {
 }
 
 type ElephantConfig struct {
-	ProviderHosts          map[string][]string `koanf:"provider_hosts" desc:"providers will only be loaded on the specified hosts. If empty, all." default:""`
-	AutoDetectLaunchPrefix bool                `koanf:"auto_detect_launch_prefix" desc:"automatically detects uwsm, app2unit or systemd-run" default:"true"`
-	LaunchPrefix           string              `koanf:"launch_prefix" desc:"overrides the default app2unit or uwsm prefix, if set." default:""`
-	TerminalCmd            string              `koanf:"terminal_cmd" desc:"command used to open cmds with terminal" default:"<autodetect>"`
-	OverloadLocalEnv       bool                `koanf:"overload_local_env" desc:"overloads the local env" default:"false"`
-	IgnoredProviders       []string            `koanf:"ignored_providers" desc:"providers to ignore" default:"<empty>"`
-	GitOnDemand            bool                `koanf:"git_on_demand" desc:"sets up git repositories on first query instead of on start" default:"true"`
-	BeforeLoad             []Command           `koanf:"before_load" desc:"commands to run before starting to load the providers" default:""`
+	ProviderHosts          map[string][]string  `koanf:"provider_hosts" desc:"providers will only be loaded on the specified hosts. If empty, all." default:""`
+	AutoDetectLaunchPrefix bool                 `koanf:"auto_detect_launch_prefix" desc:"automatically detects uwsm, app2unit or systemd-run" default:"true"`
+	LaunchPrefix           string               `koanf:"launch_prefix" desc:"overrides the default app2unit or uwsm prefix, if set." default:""`
+	TerminalCmd            string               `koanf:"terminal_cmd" desc:"command used to open cmds with terminal" default:"<autodetect>"`
+	OverloadLocalEnv       bool                 `koanf:"overload_local_env" desc:"overloads the local env" default:"false"`
+	IgnoredProviders       []string             `koanf:"ignored_providers" desc:"providers to ignore" default:"<empty>"`
+	GitOnDemand            bool                 `koanf:"git_on_demand" desc:"sets up git repositories on first query instead of on start" default:"true"`
+	BeforeLoad             []Command            `koanf:"before_load" desc:"commands to run before starting to load the providers" default:""`
+	QueryTimeout           int                  `koanf:"query_timeout" desc:"time in ms a provider has to answer a query. 0 disables the timeout" default:"0"`
+	QueryTimeouts          map[string]int       `koanf:"query_timeouts" desc:"per provider query timeouts in ms, overriding query_timeout" default:""`
+	TCPListen              string               `koanf:"tcp_listen" desc:"address for an additional tcp listener, f.e. 127.0.0.1:7373. clients authenticate with the token in <configdir>/token" default:""`
+	WebsocketListen        string               `koanf:"websocket_listen" desc:"address for an additional websocket listener serving /ws. clients authenticate with the token in <configdir>/token" default:""`
+	RemoteNetwork          bool                 `koanf:"remote_network" desc:"allows tcp_listen and websocket_listen on addresses other than loopback. the token and all traffic are sent unencrypted" default:"false"`
+	WebsocketOrigins       []string             `koanf:"websocket_origins" desc:"origins besides the listener's own host browsers may open websocket connections from, f.e. http://localhost:8080" default:"<empty>"`
+	ShutdownTimeout        int                  `koanf:"shutdown_timeout" desc:"time in ms providers get to persist pending data when shutting down" default:"5000"`
+	ActivateAllowlist      []string             `koanf:"activate_allowlist" desc:"executables allowed to activate items via the socket, others can only query. if empty, all processes of the user can" default:"<empty>"`
+	WatchConfigs           bool                 `koanf:"watch_configs" desc:"reloads provider configs and menus when they change" default:"true"`
+	MetricsFile            string               `koanf:"metrics_file" desc:"file the metrics are written to in the Prometheus text format, f.e. for node_exporter's textfile collector. disabled if empty" default:""`
+	MetricsInterval        int                  `koanf:"metrics_interval" desc:"time in ms between writes of the metrics file" default:"15000"`
+	Logging                LoggingConfig        `koanf:"logging" desc:"log output and levels" default:""`
+	ExternalProviders      []ExternalProvider   `koanf:"external_providers" desc:"providers running as separate executables, speaking JSON-RPC over stdin and stdout" default:""`
+	CrashLimit             int                  `koanf:"crash_limit" desc:"crashes within crash_window after which a provider is disabled for crash_cooldown. 0 never disables providers" default:"3"`
+	CrashWindow            int                  `koanf:"crash_window" desc:"time in ms in which crashes are counted towards crash_limit" default:"60000"`
+	CrashCooldown          int                  `koanf:"crash_cooldown" desc:"time in ms a crashing provider stays disabled. reloading enables it again" default:"300000"`
+	Setup                  map[string]SetupMode `koanf:"setup" desc:"when providers are set up: eager on start, idle once the eager ones are done or lazy on first use" default:"eager"`
 }
 
-var elephantConfig *ElephantConfig
+type ExternalProvider struct {
+	Name                 string `koanf:"name" desc:"name of the provider" default:""`
+	NamePretty           string `koanf:"name_pretty" desc:"displayed name for the provider" default:"<name>"`
+	Command              string `koanf:"command" desc:"command starting the provider, run with sh -c" default:""`
+	Icon                 string `koanf:"icon" desc:"icon for provider" default:""`
+	HideFromProviderlist bool   `koanf:"hide_from_providerlist" desc:"hides a provider from the providerlist provider" default:"false"`
+}
+
+// SetupMode is when a provider is set up, "eager", "idle" or "lazy".
+type SetupMode string
+
+const (
+	SetupEager SetupMode = "eager"
+	SetupIdle  SetupMode = "idle"
+	SetupLazy  SetupMode = "lazy"
+)
+
+func (m *SetupMode) UnmarshalText(b []byte) error {
+	switch v := SetupMode(b); v {
+	case SetupEager, SetupIdle, SetupLazy:
+		*m = v
+	default:
+		return fmt.Errorf("invalid setup mode %q, use eager, idle or lazy", v)
+	}
+
+	return nil
+}
+
+// ProviderSetup returns the setup mode of the given provider, eager if not
+// configured.
+func (c *ElephantConfig) ProviderSetup(provider string) SetupMode {
+	if c == nil {
+		return SetupEager
+	}
+
+	if val, ok := c.Setup[provider]; ok && val != "" {
+		return val
+	}
+
+	return SetupEager
+}
+
+// ShutdownTimeoutDuration returns the shutdown timeout as duration.
+func (c *ElephantConfig) ShutdownTimeoutDuration() time.Duration {
+	if c == nil {
+		return 5 * time.Second
+	}
+
+	return time.Duration(c.ShutdownTimeout) * time.Millisecond
+}
+
+// MetricsIntervalDuration returns the metrics interval as duration.
+func (c *ElephantConfig) MetricsIntervalDuration() time.Duration {
+	if c.MetricsInterval <= 0 {
+		return 15 * time.Second
+	}
+
+	return time.Duration(c.MetricsInterval) * time.Millisecond
+}
+
+// ProviderQueryTimeout returns the query timeout for the given provider. A
+// zero duration means no timeout.
+func (c *ElephantConfig) ProviderQueryTimeout(provider string) time.Duration {
+	if c == nil {
+		return 0
+	}
+
+	if val, ok := c.QueryTimeouts[provider]; ok {
+		return time.Duration(val) * time.Millisecond
+	}
+
+	return time.Duration(c.QueryTimeout) * time.Millisecond
+}
+
+var (
+	// elephantConfig is replaced as a whole when reloading, as it's read
+	// concurrently by running requests.
+	elephantConfig atomic.Pointer[ElephantConfig]
+	// configTypes holds the config type of every loaded config, so it can be
+	// validated before reloading.
+	configTypes = map[string]reflect.Type{
+		"elephant": reflect.TypeFor[*ElephantConfig](),
+		menuname:   reflect.TypeFor[*MenuConfig](),
+	}
+	configTypesMut sync.Mutex
+	// appliedConfigs holds a copy of the last config loaded successfully for
+	// every provider, it's restored if a reloaded config is invalid.
+	appliedConfigs = map[string]reflect.Value{}
+)
 
 func LoadGlobalConfig() {
-	elephantConfig = &ElephantConfig{
+	cfg := &ElephantConfig{
 		AutoDetectLaunchPrefix: true,
 		OverloadLocalEnv:       false,
 		GitOnDemand:            true,
+		ShutdownTimeout:        5000,
+		WatchConfigs:           true,
+		MetricsInterval:        15000,
+		CrashLimit:             3,
+		CrashWindow:            60000,
+		CrashCooldown:          300000,
+		Logging: LoggingConfig{
+			Format:     "text",
+			Level:      "info",
+			MaxSize:    10,
+			MaxBackups: 3,
+		},
 	}
 
-	LoadConfig("elephant", elephantConfig)
+	LoadConfig("elephant", cfg)
+	elephantConfig.Store(cfg)
 
 	for _, v := range ConfigDirs() {
 		envFile := filepath.Join(v, ".env")
@@ -53,7 +178,7 @@ func LoadGlobalConfig() {
 		if FileExists(envFile) {
 			var err error
 
-			if elephantConfig.OverloadLocalEnv {
+			if cfg.OverloadLocalEnv {
 				err = godotenv.Overload(envFile)
 			} else {
 				err = godotenv.Load(envFile)
@@ -70,41 +195,172 @@ func LoadGlobalConfig() {
 }
 
 func GetElephantConfig() *ElephantConfig {
-	return elephantConfig
+	return elephantConfig.Load()
 }
 
+// LoadConfig merges the user config of the provider into config, which holds
+// the defaults. Invalid configs are fatal on start, unless checking configs.
+// When reloading, the previous config is copied into config instead.
 func LoadConfig(provider string, config any) {
+	configTypesMut.Lock()
+	configTypes[provider] = reflect.TypeOf(config)
+	configTypesMut.Unlock()
+
+	if err := loadConfig(provider, config); err != nil {
+		slog.Error(provider, "config", err)
+
+		if restoreConfig(provider, config) {
+			slog.Warn(provider, "config", "keeping previous config")
+			return
+		}
+
+		if !configCheck {
+			os.Exit(1)
+		}
+
+		return
+	}
+
+	storeConfig(provider, config)
+}
+
+// storeConfig keeps a copy of the loaded config of the provider.
+func storeConfig(provider string, config any) {
+	v := reflect.ValueOf(config)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		return
+	}
+
+	cp := reflect.New(v.Elem().Type())
+	cp.Elem().Set(v.Elem())
+
+	configTypesMut.Lock()
+	appliedConfigs[provider] = cp
+	configTypesMut.Unlock()
+}
+
+// restoreConfig copies the last config loaded successfully into config and
+// reports whether there was one.
+func restoreConfig(provider string, config any) bool {
+	configTypesMut.Lock()
+	prev, ok := appliedConfigs[provider]
+	configTypesMut.Unlock()
+
+	v := reflect.ValueOf(config)
+
+	if !ok || v.Type() != prev.Type() {
+		return false
+	}
+
+	v.Elem().Set(prev.Elem())
+
+	return true
+}
+
+func loadConfig(provider string, config any) error {
 	defaults := koanf.New(".")
 
 	err := defaults.Load(structs.Provider(config, "koanf"), nil)
 	if err != nil {
-		slog.Error(provider, "config", err)
-		os.Exit(1)
+		return err
 	}
 
 	userConfig, err := ProviderConfig(provider)
 	if err != nil {
 		slog.Info(provider, "config", "using default config")
-		return
+		return nil
+	}
+
+	b, err := os.ReadFile(userConfig)
+	if err != nil {
+		return err
+	}
+
+	m, err := toml.Parser().Unmarshal(b)
+	if err != nil {
+		return err
+	}
+
+	overlay, err := profileOverlay(m)
+	if err != nil {
+		return err
 	}
 
 	user := koanf.New("")
 
-	err = user.Load(file.Provider(userConfig), toml.Parser())
+	err = user.Load(rawMap(m), nil)
 	if err != nil {
-		slog.Error(provider, "config", err)
-		os.Exit(1)
+		return err
+	}
+
+	if overlay != nil {
+		err = user.Load(rawMap(overlay), nil)
+		if err != nil {
+			return err
+		}
 	}
 
 	err = defaults.Merge(user)
 	if err != nil {
-		slog.Error(provider, "config", err)
-		os.Exit(1)
+		return err
 	}
 
-	err = defaults.Unmarshal("", &config)
+	// expand environment variables and ~ in all values, defaults included.
+	expanded := koanf.New("")
+
+	err = expanded.Load(rawMap(expandConfig(defaults.Raw()).(map[string]any)), nil)
 	if err != nil {
-		slog.Error(provider, "config", err)
-		os.Exit(1)
+		return err
+	}
+
+	return expanded.Unmarshal("", &config)
+}
+
+// ConfigType returns the type of the config of a provider that has loaded its
+// config, or nil.
+func ConfigType(provider string) reflect.Type {
+	configTypesMut.Lock()
+	defer configTypesMut.Unlock()
+
+	return configTypes[provider]
+}
+
+// ValidateConfig checks if the user config of a provider that has already
+// loaded its config can be loaded again, without applying it.
+func ValidateConfig(provider string) error {
+	configTypesMut.Lock()
+	t, ok := configTypes[provider]
+	configTypesMut.Unlock()
+
+	if !ok || t.Kind() != reflect.Pointer || t.Elem().Kind() != reflect.Struct {
+		return nil
 	}
+
+	if _, err := ProviderConfig(provider); err != nil {
+		return nil
+	}
+
+	return loadConfig(provider, reflect.New(t.Elem()).Interface())
+}
+
+// ValidateConfigs validates the configs of elephant and all providers that
+// have loaded their config, as well as the menu definitions.
+func ValidateConfigs() error {
+	configTypesMut.Lock()
+	names := slices.Sorted(maps.Keys(configTypes))
+	configTypesMut.Unlock()
+
+	errs := []error{}
+
+	for _, v := range names {
+		if err := ValidateConfig(v); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", v, err))
+		}
+	}
+
+	for _, v := range CheckMenus() {
+		errs = append(errs, fmt.Errorf("%s: %s", menuname, v.String()))
+	}
+
+	return errors.Join(errs...)
 }
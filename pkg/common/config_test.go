@@ -0,0 +1,33 @@
+package common
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestValidateConfigLogging(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "elephant.toml")
+
+	SetExplicitDir(dir)
+	defer SetExplicitDir("")
+
+	if err := os.WriteFile(file, []byte("[logging]\nlevel = \"warn\"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	LoadGlobalConfig()
+
+	if err := ValidateConfig("elephant"); err != nil {
+		t.Errorf("valid config: %v", err)
+	}
+
+	if err := os.WriteFile(file, []byte("[logging]\nformat = \"xml\"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ValidateConfig("elephant"); err == nil {
+		t.Error("invalid log format accepted")
+	}
+}
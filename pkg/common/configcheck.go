@@ -0,0 +1,311 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/go-viper/mapstructure/v2"
+	"github.com/knadh/koanf/parsers/toml/v2"
+	"github.com/knadh/koanf/v2"
+)
+
+// ConfigIssue describes a problem in a config file. Line is 0 if unknown.
+type ConfigIssue struct {
+	File    string
+	Line    int
+	Key     string
+	Message string
+}
+
+func (i ConfigIssue) String() string {
+	res := i.File
+
+	if i.Line > 0 {
+		res = fmt.Sprintf("%s:%d", res, i.Line)
+	}
+
+	if i.Key != "" {
+		res = fmt.Sprintf("%s: %s", res, i.Key)
+	}
+
+	return fmt.Sprintf("%s: %s", res, i.Message)
+}
+
+// configCheck makes LoadConfig keep the defaults of invalid configs instead
+// of exiting.
+var configCheck bool
+
+// EnableConfigCheck makes LoadConfig keep going on invalid configs, so all of
+// them can be checked with CheckConfig.
+func EnableConfigCheck() {
+	configCheck = true
+}
+
+// CheckConfig checks the user config of a provider that has loaded its config,
+// including all profiles, for syntax errors, unknown keys and values not
+// matching the config type.
+func CheckConfig(provider string) []ConfigIssue {
+	file, err := ProviderConfig(provider)
+	if err != nil {
+		return nil
+	}
+
+	b, err := os.ReadFile(file)
+	if err != nil {
+		return []ConfigIssue{{File: file, Message: err.Error()}}
+	}
+
+	m, err := toml.Parser().Unmarshal(b)
+	if err != nil {
+		return []ConfigIssue{parseIssue(file, err)}
+	}
+
+	configTypesMut.Lock()
+	t, ok := configTypes[provider]
+	configTypesMut.Unlock()
+
+	if !ok || t.Kind() != reflect.Pointer || t.Elem().Kind() != reflect.Struct {
+		return nil
+	}
+
+	raw, hasProfiles := m["profiles"]
+	delete(m, "profiles")
+
+	res := decodeIssues(file, b, t.Elem().String(), "", decodeStrict(t, m))
+
+	if !hasProfiles {
+		return res
+	}
+
+	profiles, ok := raw.(map[string]any)
+	if !ok {
+		return append(res, ConfigIssue{File: file, Line: keyLine(b, "profiles"), Key: "profiles", Message: "expected a table"})
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(profiles)) {
+		prefix := fmt.Sprintf("profiles.%s", name)
+
+		overlay, ok := profiles[name].(map[string]any)
+		if !ok {
+			res = append(res, ConfigIssue{File: file, Line: keyLine(b, prefix), Key: prefix, Message: "expected a table"})
+			continue
+		}
+
+		res = append(res, decodeIssues(file, b, t.Elem().String(), prefix, decodeStrict(t, overlay))...)
+	}
+
+	return res
+}
+
+// decodeStrict decodes m into a new value of the config type t, failing on
+// unknown keys. Only the user config is decoded, so every unused key is
+// unknown.
+func decodeStrict(t reflect.Type, m map[string]any) error {
+	user := koanf.New("")
+
+	if err := user.Load(rawMap(m), nil); err != nil {
+		return err
+	}
+
+	return user.UnmarshalWithConf("", reflect.New(t.Elem()).Interface(), koanf.UnmarshalConf{
+		DecoderConfig: &mapstructure.DecoderConfig{
+			DecodeHook: mapstructure.ComposeDecodeHookFunc(
+				mapstructure.StringToTimeDurationHookFunc(),
+				mapstructure.TextUnmarshallerHookFunc()),
+			WeaklyTypedInput: true,
+			ErrorUnused:      true,
+		},
+	})
+}
+
+// ConfigIssueAt creates an issue for the given key of the user config of the
+// provider, f.e. "entries[1].url".
+func ConfigIssueAt(provider, key, message string) ConfigIssue {
+	file, _ := ProviderConfig(provider)
+	b, _ := os.ReadFile(file)
+
+	return ConfigIssue{
+		File:    file,
+		Line:    keyLine(b, key),
+		Key:     key,
+		Message: message,
+	}
+}
+
+// decodeIssues turns the, possibly joined, mapstructure errors into issues.
+// Errors of the config itself are named after its type, root. Keys are
+// prefixed with prefix, if set.
+func decodeIssues(file string, b []byte, root, prefix string, err error) []ConfigIssue {
+	if err == nil {
+		return nil
+	}
+
+	res := []ConfigIssue{}
+
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, v := range joined.Unwrap() {
+			res = append(res, decodeIssues(file, b, root, prefix, v)...)
+		}
+
+		return res
+	}
+
+	derr, ok := err.(*mapstructure.DecodeError)
+	if !ok {
+		if inner := errors.Unwrap(err); inner != nil {
+			return decodeIssues(file, b, root, prefix, inner)
+		}
+
+		return []ConfigIssue{{File: file, Message: err.Error()}}
+	}
+
+	name := derr.Name()
+
+	if name == root {
+		name = ""
+	}
+
+	if prefix != "" {
+		name = strings.TrimSuffix(prefix+"."+name, ".")
+	}
+
+	inner := derr.Unwrap()
+
+	if _, ok := inner.(interface{ Unwrap() []error }); ok {
+		return decodeIssues(file, b, root, prefix, inner)
+	}
+
+	if _, ok := inner.(*mapstructure.DecodeError); ok {
+		return decodeIssues(file, b, root, prefix, inner)
+	}
+
+	if keys, ok := strings.CutPrefix(inner.Error(), "has invalid keys: "); ok {
+		for v := range strings.SplitSeq(keys, ", ") {
+			key := v
+
+			if name != "" {
+				key = name + "." + v
+			}
+
+			res = append(res, ConfigIssue{
+				File:    file,
+				Line:    keyLine(b, key),
+				Key:     key,
+				Message: "unknown key",
+			})
+		}
+
+		return res
+	}
+
+	return []ConfigIssue{{
+		File:    file,
+		Line:    keyLine(b, name),
+		Key:     name,
+		Message: inner.Error(),
+	}}
+}
+
+var keyIndex = regexp.MustCompile(`^(.+)\[(\d+)\]$`)
+
+// keyLine finds the line of a key like "entries[1].url" in a toml file. It
+// returns 0 if the key can't be found.
+func keyLine(b []byte, key string) int {
+	if key == "" || len(b) == 0 {
+		return 0
+	}
+
+	lines := strings.Split(string(b), "\n")
+	start := 0
+
+	for part := range strings.SplitSeq(key, ".") {
+		name, idx := part, -1
+
+		if m := keyIndex.FindStringSubmatch(part); m != nil {
+			name = m[1]
+			idx, _ = strconv.Atoi(m[2])
+		}
+
+		quoted := regexp.QuoteMeta(name)
+		found := false
+
+		if idx >= 0 {
+			header := regexp.MustCompile(`^\s*\[\[\s*"?` + quoted + `"?\s*\]\]`)
+			count := 0
+
+			for i := start; i < len(lines); i++ {
+				if !header.MatchString(lines[i]) {
+					continue
+				}
+
+				if count == idx {
+					start, found = i, true
+					break
+				}
+
+				count++
+			}
+		}
+
+		if !found {
+			assign := regexp.MustCompile(`^\s*"?` + quoted + `"?\s*(=|\.)|^\s*\[\s*([\w"-]+\s*\.\s*)*"?` + quoted + `"?\s*(\.|\])`)
+
+			for i := start; i < len(lines); i++ {
+				if assign.MatchString(lines[i]) {
+					start, found = i, true
+					break
+				}
+			}
+		}
+
+		if !found {
+			return 0
+		}
+	}
+
+	return start + 1
+}
+
+// CheckMenus checks if all menu definitions in the default menu directories
+// can be parsed.
+func CheckMenus() []ConfigIssue {
+	res := []ConfigIssue{}
+
+	for _, root := range menuDirs() {
+		filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return nil
+			}
+
+			if err := ValidateMenu(path); err != nil {
+				res = append(res, parseIssue(path, err))
+			}
+
+			return nil
+		})
+	}
+
+	return res
+}
+
+// parseIssue creates an issue for a parse error, with the line if the parser
+// reports it.
+func parseIssue(file string, err error) ConfigIssue {
+	issue := ConfigIssue{File: file, Message: err.Error()}
+
+	var pos interface{ Position() (int, int) }
+	if errors.As(err, &pos) {
+		issue.Line, _ = pos.Position()
+	}
+
+	return issue
+}
@@ -0,0 +1,149 @@
+package common
+
+import (
+	"errors"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// WatchConfigs watches the provider configs in the config dirs and the menu
+// directories. Changes are debounced and validated, apply is only called for
+// providers whose new config is valid. Changes of menu definitions are
+// reported as "menus". Blocks until the watcher is closed.
+func WatchConfigs(apply func(provider string)) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		slog.Error("config", "watch", err)
+		return
+	}
+	defer watcher.Close()
+
+	dirs := ConfigDirs()
+
+	for _, v := range dirs {
+		if err := watcher.Add(v); err != nil {
+			slog.Error("config", "watch", err, "dir", v)
+		}
+	}
+
+	// the configured paths are read without applying the config, as the menus
+	// might still be loading.
+	menuCfg := MenuConfig{}
+	loadConfig(menuname, &menuCfg)
+
+	menus := append(menuCfg.Paths, menuDirs()...)
+
+	for _, v := range menus {
+		watchTree(watcher, v)
+	}
+
+	pending := make(map[string][]string)
+
+	timer := time.NewTimer(time.Millisecond * 500)
+	timer.Stop()
+
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+
+			if event.Op == fsnotify.Chmod {
+				continue
+			}
+
+			provider, ok := configOf(event.Name, dirs, menus)
+			if !ok {
+				continue
+			}
+
+			if event.Has(fsnotify.Create) && provider == menuname {
+				if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+					watchTree(watcher, event.Name)
+				}
+			}
+
+			pending[provider] = append(pending[provider], event.Name)
+			timer.Reset(time.Millisecond * 500)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+
+			slog.Error("config", "watch", err)
+		case <-timer.C:
+			for provider, files := range pending {
+				if err := validateChange(provider, files); err != nil {
+					slog.Error(provider, "config", err, "reload", "keeping current config")
+					continue
+				}
+
+				slog.Info(provider, "config", "changed")
+				apply(provider)
+			}
+
+			clear(pending)
+		}
+	}
+}
+
+// watchTree adds dir and all its subdirectories to the watcher.
+func watchTree(watcher *fsnotify.Watcher, dir string) {
+	filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+
+		if d.IsDir() {
+			if err := watcher.Add(path); err != nil {
+				slog.Error("config", "watch", err, "dir", path)
+			}
+		}
+
+		return nil
+	})
+}
+
+// configOf returns the provider whose config the file belongs to. Only
+// providers that have loaded their config are considered.
+func configOf(path string, dirs, menus []string) (string, bool) {
+	for _, v := range menus {
+		if path == v || strings.HasPrefix(path, v+string(filepath.Separator)) {
+			return menuname, true
+		}
+	}
+
+	if filepath.Ext(path) != ".toml" || !slices.Contains(dirs, filepath.Dir(path)) {
+		return "", false
+	}
+
+	provider := strings.TrimSuffix(filepath.Base(path), ".toml")
+
+	configTypesMut.Lock()
+	_, ok := configTypes[provider]
+	configTypesMut.Unlock()
+
+	return provider, ok
+}
+
+func validateChange(provider string, files []string) error {
+	errs := []error{ValidateConfig(provider)}
+
+	if provider == menuname {
+		for _, v := range slices.Compact(slices.Sorted(slices.Values(files))) {
+			if FileExists(v) {
+				errs = append(errs, ValidateMenu(v))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
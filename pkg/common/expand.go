@@ -0,0 +1,64 @@
+package common
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var envVar = regexp.MustCompile(`\$\$\{|\$\{([A-Za-z_][A-Za-z0-9_]*)(:-([^}]*))?\}`)
+
+// ExpandValue expands `${VAR}` and `${VAR:-default}` with environment
+// variables and a leading `~` with the home directory. `$${` is kept as
+// literal `${`.
+func ExpandValue(val string) string {
+	val = envVar.ReplaceAllStringFunc(val, func(match string) string {
+		if match == "$${" {
+			return "${"
+		}
+
+		m := envVar.FindStringSubmatch(match)
+
+		if res := os.Getenv(m[1]); res != "" || m[2] == "" {
+			return res
+		}
+
+		return m[3]
+	})
+
+	if val == "~" || strings.HasPrefix(val, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			val = filepath.Join(home, val[1:])
+		}
+	}
+
+	return val
+}
+
+// expandConfig applies ExpandValue to all strings and string lists of a
+// config tree.
+func expandConfig(val any) any {
+	switch v := val.(type) {
+	case string:
+		return ExpandValue(v)
+	case []string:
+		for k, e := range v {
+			v[k] = ExpandValue(e)
+		}
+	case map[string]any:
+		for k, e := range v {
+			v[k] = expandConfig(e)
+		}
+	case []any:
+		for k, e := range v {
+			v[k] = expandConfig(e)
+		}
+	case []map[string]any:
+		for _, e := range v {
+			expandConfig(e)
+		}
+	}
+
+	return val
+}
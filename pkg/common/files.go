@@ -25,6 +25,21 @@ func TmpDir() string {
 	return filepath.Join(os.TempDir())
 }
 
+// ConfigDir returns the directory elephant writes configuration to, the
+// explicit config dir if set.
+func ConfigDir() (string, error) {
+	if explicitDir != "" {
+		return explicitDir, nil
+	}
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, "elephant"), nil
+}
+
 func ConfigDirs() []string {
 	res := []string{}
 
@@ -1,8 +1,10 @@
 package common
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
+	"maps"
 	"path/filepath"
 	"strings"
 	"sync"
@@ -14,8 +16,26 @@ import (
 var (
 	gitMu      sync.Mutex
 	setupRepos = make(map[string]Repo)
+	// gitFailures counts failed setups and pushes per provider.
+	gitFailures    = make(map[string]uint64)
+	gitFailuresMut sync.Mutex
 )
 
+func gitFailed(provider string) {
+	gitFailuresMut.Lock()
+	gitFailures[provider]++
+	gitFailuresMut.Unlock()
+}
+
+// GitSyncFailures returns the amount of failed git setups and pushes per
+// provider.
+func GitSyncFailures() map[string]uint64 {
+	gitFailuresMut.Lock()
+	defer gitFailuresMut.Unlock()
+
+	return maps.Clone(gitFailures)
+}
+
 type Repo struct {
 	w *git.Worktree
 	r *git.Repository
@@ -100,6 +120,10 @@ func SetupGit(provider string, cfg Gittable) {
 
 			break
 		}
+
+		if _, ok := setupRepos[cfg.URL()]; !ok {
+			gitFailed(provider)
+		}
 	} else {
 		slog.Info(provider, "gitsetup", "repo already setup")
 
@@ -119,10 +143,14 @@ type PushData struct {
 	r        *git.Repository
 }
 
-var pushChan chan PushData
+var (
+	pushChan  chan PushData
+	flushChan chan chan struct{}
+)
 
 func init() {
 	pushChan = make(chan PushData)
+	flushChan = make(chan chan struct{})
 
 	go func() {
 		timer := time.NewTimer(time.Second * 5)
@@ -131,6 +159,37 @@ func init() {
 		var mu sync.Mutex
 		work := make(map[string]PushData)
 
+		push := func() {
+			mu.Lock()
+			defer mu.Unlock()
+
+			for k, v := range work {
+				_, err := v.w.Add(v.file)
+				if err != nil {
+					slog.Error(v.provider, "gitadd", err)
+					gitFailed(v.provider)
+					continue
+				}
+
+				_, err = v.w.Commit("elephant", &git.CommitOptions{})
+				if err != nil {
+					slog.Error(v.provider, "commit", err)
+					gitFailed(v.provider)
+					continue
+				}
+
+				err = v.r.Push(&git.PushOptions{})
+				if err != nil {
+					slog.Error(v.provider, "push", err)
+					gitFailed(v.provider)
+					continue
+				}
+
+				delete(work, k)
+				slog.Info(v.provider, "git", "pushed to repository")
+			}
+		}
+
 		for {
 			select {
 			case data := <-pushChan:
@@ -141,38 +200,45 @@ func init() {
 				do = true
 			case <-timer.C:
 				if do {
-					mu.Lock()
-					for k, v := range work {
-						_, err := v.w.Add(v.file)
-						if err != nil {
-							slog.Error(v.provider, "gitadd", err)
-							continue
-						}
-
-						_, err = v.w.Commit("elephant", &git.CommitOptions{})
-						if err != nil {
-							slog.Error(v.provider, "commit", err)
-							continue
-						}
-
-						err = v.r.Push(&git.PushOptions{})
-						if err != nil {
-							slog.Error(v.provider, "push", err)
-							continue
-						}
-
-						delete(work, k)
-						slog.Info(v.provider, "git", "pushed to repository")
-					}
-					mu.Unlock()
-
+					push()
+					do = false
+				}
+			case done := <-flushChan:
+				if do {
+					push()
 					do = false
 				}
+
+				close(done)
 			}
 		}
 	}()
 }
 
+// FlushGit pushes pending commits right away instead of waiting for the
+// debounce timer. It's used when shutting down.
+func FlushGit(ctx context.Context) error {
+	done := make(chan struct{})
+
+	go func() {
+		// waits for GitPush calls that are in flight
+		gitMu.Lock()
+		defer gitMu.Unlock()
+
+		select {
+		case flushChan <- done:
+		case <-ctx.Done():
+		}
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
 // TODO: this needs better commit messages somehow...
 func GitPush(provider, file string, w *git.Worktree, r *git.Repository) {
 	gitMu.Lock()
@@ -85,12 +85,28 @@ func (h *History) writeFile() {
 		return
 	}
 
-	err = os.WriteFile(common.CacheFile(fmt.Sprintf("%s_history.gob", h.Provider)), b.Bytes(), 0o600)
+	file := common.CacheFile(fmt.Sprintf("%s_history.gob", h.Provider))
+
+	// write to a temporary file first, so a write that gets interrupted
+	// doesn't leave a corrupted history behind.
+	err = os.WriteFile(file+".tmp", b.Bytes(), 0o600)
+	if err != nil {
+		slog.Error("history", "writefile", err)
+		return
+	}
+
+	err = os.Rename(file+".tmp", file)
 	if err != nil {
 		slog.Error("history", "writefile", err)
 	}
 }
 
+// Flush waits for history writes in progress.
+func Flush() {
+	mut.Lock()
+	defer mut.Unlock()
+}
+
 func (h *History) FindUsage(query, identifier string) (int, time.Time, int) {
 	mut.Lock()
 	defer mut.Unlock()
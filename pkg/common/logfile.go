@@ -0,0 +1,137 @@
+package common
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// logFile is a log file that is rotated once it would exceed maxSize. Rotated
+// files are kept as <file>.1 to <file>.<backups>, .1 being the newest. Writes
+// after Close go to stderr, so loggers still holding a replaced output don't
+// lose records.
+type logFile struct {
+	mut     sync.Mutex
+	path    string
+	maxSize int64
+	backups int
+	file    *os.File
+	size    int64
+}
+
+func openLogFile(path string, maxSize int64, backups int) (*logFile, error) {
+	l := &logFile{
+		path:    path,
+		maxSize: maxSize,
+		backups: backups,
+	}
+
+	if err := l.open(); err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+func (l *logFile) open() error {
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o755); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(l.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	l.file = f
+	l.size = info.Size()
+
+	return nil
+}
+
+func (l *logFile) Write(b []byte) (int, error) {
+	l.mut.Lock()
+	defer l.mut.Unlock()
+
+	if l.file == nil {
+		return os.Stderr.Write(b)
+	}
+
+	if l.maxSize > 0 && l.size > 0 && l.size+int64(len(b)) > l.maxSize {
+		if err := l.rotate(); err != nil {
+			// keep writing to the current file, rotation is retried once
+			// another maxSize was written.
+			fmt.Fprintf(os.Stderr, "log rotation of %s failed: %v\n", l.path, err)
+			l.size = 0
+		}
+	}
+
+	n, err := l.file.Write(b)
+	l.size += int64(n)
+
+	return n, err
+}
+
+// rotate moves the file to <file>.1 and opens a new one. The current file
+// stays open until that succeeded.
+func (l *logFile) rotate() error {
+	if l.backups == 0 {
+		if err := l.file.Truncate(0); err != nil {
+			return err
+		}
+
+		l.size = 0
+
+		return nil
+	}
+
+	backup := func(i int) string {
+		return fmt.Sprintf("%s.%d", l.path, i)
+	}
+
+	os.Remove(backup(l.backups))
+
+	for i := l.backups - 1; i > 0; i-- {
+		if FileExists(backup(i)) {
+			if err := os.Rename(backup(i), backup(i+1)); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := os.Rename(l.path, backup(1)); err != nil {
+		return err
+	}
+
+	prev := l.file
+
+	if err := l.open(); err != nil {
+		// the current file was renamed already, it's still written to.
+		return err
+	}
+
+	prev.Close()
+
+	return nil
+}
+
+func (l *logFile) Close() error {
+	l.mut.Lock()
+	defer l.mut.Unlock()
+
+	if l.file == nil {
+		return nil
+	}
+
+	err := l.file.Close()
+	l.file = nil
+
+	return err
+}
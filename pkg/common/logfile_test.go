@@ -0,0 +1,76 @@
+package common
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLogFileRotates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "elephant.log")
+
+	l, err := openLogFile(path, 100, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	// 10 lines of 20 bytes, 5 per file.
+	for i := range 10 {
+		if _, err := fmt.Fprintf(l, "line %02d............\n", i); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	expected := map[string]string{
+		path:        "line 05",
+		path + ".1": "line 00",
+	}
+
+	for file, first := range expected {
+		b, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(b) > 100 || !strings.HasPrefix(string(b), first) {
+			t.Errorf("%s has %d bytes starting with %q, expected at most 100 starting with %q", file, len(b), b[:min(len(b), 7)], first)
+		}
+	}
+
+	if FileExists(path + ".2") {
+		t.Error("unexpected second backup")
+	}
+}
+
+func TestLogFileKeepsWritingWhenRotationFails(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "elephant.log")
+
+	// a non-empty directory in place of the backup can't be replaced.
+	if err := os.MkdirAll(filepath.Join(path+".1", "blocked"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := openLogFile(path, 10, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	for i := range 3 {
+		if _, err := fmt.Fprintf(l, "line %d....\n", i); err != nil {
+			t.Fatalf("write %d: %v", i, err)
+		}
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Count(string(b), "line") != 3 {
+		t.Errorf("log file contains %q, expected all 3 lines", b)
+	}
+}
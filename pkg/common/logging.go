@@ -0,0 +1,248 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"sync"
+)
+
+type LoggingConfig struct {
+	Format     LogFormat           `koanf:"format" desc:"log format, text or json" default:"text"`
+	Level      LogLevel            `koanf:"level" desc:"log level, debug, info, warn or error" default:"info"`
+	File       string              `koanf:"file" desc:"file to log to instead of stderr" default:""`
+	MaxSize    int                 `koanf:"max_size" desc:"size in MB after which the log file is rotated. 0 disables rotation" default:"10"`
+	MaxBackups int                 `koanf:"max_backups" desc:"amount of rotated log files to keep" default:"3"`
+	Providers  map[string]LogLevel `koanf:"providers" desc:"log levels per provider, overriding level" default:""`
+}
+
+// LogFormat is the output format of the log, "text" or "json". Unset means
+// text.
+type LogFormat string
+
+func (f *LogFormat) UnmarshalText(b []byte) error {
+	if s := string(b); s != "" && s != "text" && s != "json" {
+		return fmt.Errorf("invalid log format %q, use text or json", s)
+	}
+
+	*f = LogFormat(b)
+
+	return nil
+}
+
+// LogLevel is the name of a slog level, f.e. "debug" or "warn". Unset means
+// the level it would override.
+type LogLevel string
+
+func (l *LogLevel) UnmarshalText(b []byte) error {
+	var level slog.Level
+
+	if len(b) == 0 {
+		*l = ""
+		return nil
+	}
+
+	if err := level.UnmarshalText(b); err != nil {
+		return err
+	}
+
+	*l = LogLevel(b)
+
+	return nil
+}
+
+// Level returns the slog level, fallback if unset or invalid.
+func (l LogLevel) Level(fallback slog.Level) slog.Level {
+	var level slog.Level
+
+	if l == "" || level.UnmarshalText([]byte(l)) != nil {
+		return fallback
+	}
+
+	return level
+}
+
+var (
+	logMut sync.Mutex
+	// logOutput is the current log file, nil when logging to stderr.
+	logOutput *logFile
+	// logTarget identifies the file, rotation and format currently in use.
+	logTarget string
+	logDebug  bool
+	// writeMut is held for reading while a record is written. SetupLogging
+	// holds it for writing after replacing the output, so records in flight
+	// are written before the old file is closed.
+	writeMut sync.RWMutex
+)
+
+// EnableDebugLogging lowers the log level to debug for all providers without
+// a level of their own.
+func EnableDebugLogging() {
+	logDebug = true
+}
+
+// SetupLogging applies the logging config of elephant.toml. Levels are read
+// on every record, the output is only replaced if the file, rotation or
+// format changed.
+func SetupLogging() error {
+	cfg := GetElephantConfig().Logging
+
+	target := fmt.Sprintf("%s\x00%d\x00%d\x00%s", cfg.File, cfg.MaxSize, cfg.MaxBackups, cfg.Format)
+
+	logMut.Lock()
+	defer logMut.Unlock()
+
+	if target == logTarget {
+		return nil
+	}
+
+	var out io.Writer = os.Stderr
+	var file *logFile
+
+	if cfg.File != "" {
+		var err error
+
+		file, err = openLogFile(cfg.File, int64(cfg.MaxSize)*1024*1024, cfg.MaxBackups)
+		if err != nil {
+			return err
+		}
+
+		out = file
+	}
+
+	opts := &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+	}
+
+	var next slog.Handler
+
+	switch cfg.Format {
+	case "json":
+		next = slog.NewJSONHandler(out, opts)
+	default:
+		next = slog.NewTextHandler(out, opts)
+	}
+
+	slog.SetDefault(slog.New(logHandler{next: next}))
+
+	writeMut.Lock()
+	prev := logOutput
+	logOutput = file
+	writeMut.Unlock()
+
+	logTarget = target
+
+	if prev != nil {
+		prev.Close()
+	}
+
+	return nil
+}
+
+// logHandler filters records by the global level.
+type logHandler struct {
+	next slog.Handler
+}
+
+func (h logHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= logLevel("")
+}
+
+func (h logHandler) Handle(ctx context.Context, r slog.Record) error {
+	writeMut.RLock()
+	defer writeMut.RUnlock()
+
+	return h.next.Handle(ctx, r)
+}
+
+func (h logHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return logHandler{next: h.next.WithAttrs(attrs)}
+}
+
+func (h logHandler) WithGroup(name string) slog.Handler {
+	return logHandler{next: h.next.WithGroup(name)}
+}
+
+// logLevel returns the level of the provider, or the global level for "".
+func logLevel(provider string) slog.Level {
+	level := slog.LevelInfo
+
+	cfg := GetElephantConfig()
+	if cfg != nil {
+		level = cfg.Logging.Level.Level(level)
+	}
+
+	if logDebug {
+		level = slog.LevelDebug
+	}
+
+	if cfg != nil && provider != "" {
+		if v, ok := cfg.Logging.Providers[provider]; ok {
+			level = v.Level(level)
+		}
+	}
+
+	return level
+}
+
+// ProviderLogger returns the logger of a provider. Its records carry the
+// provider as attribute and are filtered by the level of the provider.
+func ProviderLogger(provider string) *slog.Logger {
+	return slog.New(providerHandler{provider: provider})
+}
+
+// providerHandler writes to the output of the default logger. The output is
+// looked up for every record, so it follows changes of the logging config.
+type providerHandler struct {
+	provider string
+	wrap     []func(slog.Handler) slog.Handler
+}
+
+func (h providerHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	if _, ok := slog.Default().Handler().(logHandler); !ok {
+		return slog.Default().Handler().Enabled(ctx, level)
+	}
+
+	return level >= logLevel(h.provider)
+}
+
+func (h providerHandler) Handle(ctx context.Context, r slog.Record) error {
+	writeMut.RLock()
+	defer writeMut.RUnlock()
+
+	next := slog.Default().Handler()
+
+	if v, ok := next.(logHandler); ok {
+		next = v.next
+	}
+
+	next = next.WithAttrs([]slog.Attr{slog.String("provider", h.provider)})
+
+	for _, w := range h.wrap {
+		next = w(next)
+	}
+
+	return next.Handle(ctx, r)
+}
+
+func (h providerHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return h.with(func(next slog.Handler) slog.Handler {
+		return next.WithAttrs(attrs)
+	})
+}
+
+func (h providerHandler) WithGroup(name string) slog.Handler {
+	return h.with(func(next slog.Handler) slog.Handler {
+		return next.WithGroup(name)
+	})
+}
+
+func (h providerHandler) with(w func(slog.Handler) slog.Handler) providerHandler {
+	return providerHandler{
+		provider: h.provider,
+		wrap:     append(slices.Clip(h.wrap), w),
+	}
+}
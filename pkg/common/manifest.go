@@ -0,0 +1,114 @@
+package common
+
+import (
+	"context"
+	"log/slog"
+	"net"
+
+	"github.com/abenz1267/elephant/v2/pkg/pb/pb"
+)
+
+// ABIVersion is the version of the plugin ABI described by Manifest. It's
+// increased on incompatible changes, plugins built for another version are
+// rejected.
+const ABIVersion = 2
+
+// Manifest describes a provider plugin, plugins export it as Manifest. All
+// fields besides the optional ones are required.
+type Manifest struct {
+	// ABI has to be set to ABIVersion.
+	ABI  int
+	Name string
+	// NamePretty points at the variable holding the display name, so
+	// changes made by LoadConfig are picked up.
+	NamePretty           *string
+	Available            func() bool
+	Setup                func()
+	LoadConfig           func()
+	PrintDoc             func(bool)
+	Icon                 func() string
+	HideFromProviderlist func() bool
+	State                func(string) *pb.ProviderStateResponse
+	Activate             func(single bool, identifier, action, query, args string, format uint8, conn net.Conn)
+	Query                func(conn net.Conn, query string, single bool, exact bool, format uint8) []*pb.QueryResponse_Item
+
+	// Requirements is optional. It lists the external tools the provider
+	// depends on.
+	Requirements []Requirement
+	// Shutdown is optional. It's called when elephant exits and should persist
+	// pending writes.
+	Shutdown func()
+	// ActivateErr is optional. Providers setting it report failed activations
+	// to the client.
+	ActivateErr func(single bool, identifier, action, query, args string, format uint8, conn net.Conn) error
+	// QueryContext is optional. Providers setting it stop working once the
+	// context is done and may return the results found so far. Providers
+	// without it return nothing for queries they time out on.
+	QueryContext func(ctx context.Context, conn net.Conn, query string, single bool, exact bool, format uint8) []*pb.QueryResponse_Item
+	// CheckConfig is optional. It validates constraints of the loaded config
+	// that can't be expressed by its type.
+	CheckConfig func() []ConfigIssue
+	// Metrics is optional. It reports provider specific values, f.e. the size
+	// of an index, for the metrics export.
+	Metrics func() []Metric
+	// Logger is optional. It points at the logger the provider logs with,
+	// which is set to a logger adding the provider to every record before
+	// Available is called.
+	Logger **slog.Logger
+}
+
+// Missing returns the required fields that aren't set.
+func (m *Manifest) Missing() []string {
+	res := []string{}
+
+	required := []struct {
+		name string
+		set  bool
+	}{
+		{"Name", m.Name != ""},
+		{"NamePretty", m.NamePretty != nil && *m.NamePretty != ""},
+		{"Available", m.Available != nil},
+		{"Setup", m.Setup != nil},
+		{"LoadConfig", m.LoadConfig != nil},
+		{"PrintDoc", m.PrintDoc != nil},
+		{"Icon", m.Icon != nil},
+		{"HideFromProviderlist", m.HideFromProviderlist != nil},
+		{"State", m.State != nil},
+		{"Activate", m.Activate != nil},
+		{"Query", m.Query != nil},
+	}
+
+	for _, v := range required {
+		if !v.set {
+			res = append(res, v.name)
+		}
+	}
+
+	return res
+}
+
+// Optional returns the optional fields that are set.
+func (m *Manifest) Optional() []string {
+	res := []string{}
+
+	optional := []struct {
+		name string
+		set  bool
+	}{
+		{"Requirements", len(m.Requirements) > 0},
+		{"Shutdown", m.Shutdown != nil},
+		{"ActivateErr", m.ActivateErr != nil},
+		{"QueryContext", m.QueryContext != nil},
+		{"CheckConfig", m.CheckConfig != nil},
+		{"Metrics", m.Metrics != nil},
+		{"Logger", m.Logger != nil},
+	}
+
+	for _, v := range optional {
+		if v.set {
+			res = append(res, v.name)
+		}
+	}
+
+	return res
+}
@@ -4,9 +4,9 @@ import (
 	"crypto/md5"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/fs"
-	"log/slog"
 	"os"
 	"path/filepath"
 	"slices"
@@ -58,20 +58,33 @@ type Menu struct {
 	// internal
 	LuaString string
 	IsLua     bool `toml:"-"`
+	done      chan struct{}
+}
+
+// stop stops watching RefreshOnChange.
+func (m *Menu) stop() {
+	if m.done != nil {
+		close(m.done)
+	}
 }
 
 func (m *Menu) NewLuaState() *lua.LState {
+	l, err := m.newLuaState()
+	if err != nil {
+		menuLogger.Error(m.Name, "newLuaState", err)
+		return nil
+	}
+
+	return l
+}
+
+// newLuaState runs the Lua code of the menu.
+func (m *Menu) newLuaState() (*lua.LState, error) {
 	l := lua.NewState()
 
 	if err := l.DoString(m.LuaString); err != nil {
-		slog.Error(m.Name, "newLuaState", err)
 		l.Close()
-		return nil
-	}
-
-	if l == nil {
-		slog.Error(m.Name, "newLuaState", "lua state is nil")
-		return nil
+		return nil, err
 	}
 
 	l.SetGlobal("lastMenuValue", l.NewFunction(GetLastMenuValue))
@@ -80,13 +93,13 @@ func (m *Menu) NewLuaState() *lua.LState {
 	l.SetGlobal("jsonEncode", l.NewFunction(JSONEncode))
 	l.SetGlobal("jsonDecode", l.NewFunction(JSONDecode))
 
-	return l
+	return l, nil
 }
 
 func (m *Menu) watch() {
 	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
-		slog.Error(m.Name, "watch", err)
+		menuLogger.Error(m.Name, "watch", err)
 	}
 
 	for _, v := range m.RefreshOnChange {
@@ -100,10 +113,14 @@ func (m *Menu) watch() {
 			select {
 			case _, ok := <-watcher.Events:
 				if !ok {
-					continue
+					return
 				}
 
-				changeChan <- struct{}{}
+				select {
+				case changeChan <- struct{}{}:
+				case <-m.done:
+					return
+				}
 			case _, ok := <-watcher.Errors:
 				if !ok {
 					return
@@ -117,18 +134,26 @@ func (m *Menu) watch() {
 
 	for {
 		select {
+		case <-m.done:
+			watcher.Close()
+			return
 		case <-changeChan:
 			timer.Reset(time.Millisecond * 500)
 			do = true
 		case <-timer.C:
 			if do {
-				m.CreateLuaEntries("")
+				RecoverMenu(func() { m.CreateLuaEntries("") })
 				do = false
 			}
 		}
 	}
 }
 
+// RecoverMenu runs Lua of menus outside of provider calls, when watching
+// files or loading menus. It's replaced to count panics as crashes of the
+// menus provider.
+var RecoverMenu = func(f func()) { f() }
+
 var (
 	LastMenuValue    = make(map[string]string)
 	LastMenuValueMut sync.Mutex
@@ -278,7 +303,7 @@ func (m *Menu) CreateLuaEntries(query string) {
 	state := m.NewLuaState()
 
 	if state == nil {
-		slog.Error(m.Name, "CreateLuaEntries", "no lua state")
+		menuLogger.Error(m.Name, "CreateLuaEntries", "no lua state")
 		return
 	}
 
@@ -287,7 +312,7 @@ func (m *Menu) CreateLuaEntries(query string) {
 		NRet:    1,
 		Protect: true,
 	}, lua.LString(query)); err != nil {
-		slog.Error(m.Name, "GetLuaEntries", err)
+		menuLogger.Error(m.Name, "GetLuaEntries", err)
 		return
 	}
 
@@ -302,19 +327,19 @@ func (m *Menu) CreateLuaEntries(query string) {
 				entry := Entry{}
 
 				if text := item.RawGetString("Text"); text != lua.LNil {
-					entry.Text = string(text.(lua.LString))
+					entry.Text = lua.LVAsString(text)
 				}
 
 				if preview := item.RawGetString("Preview"); preview != lua.LNil {
-					entry.Preview = string(preview.(lua.LString))
+					entry.Preview = lua.LVAsString(preview)
 				}
 
 				if preview := item.RawGetString("PreviewType"); preview != lua.LNil {
-					entry.PreviewType = string(preview.(lua.LString))
+					entry.PreviewType = lua.LVAsString(preview)
 				}
 
 				if subtext := item.RawGetString("Subtext"); subtext != lua.LNil {
-					entry.Subtext = string(subtext.(lua.LString))
+					entry.Subtext = lua.LVAsString(subtext)
 				}
 
 				if state := item.RawGet(lua.LString("Hosts")); state != lua.LNil {
@@ -333,15 +358,15 @@ func (m *Menu) CreateLuaEntries(query string) {
 				}
 
 				if submenu := item.RawGetString("SubMenu"); submenu != lua.LNil {
-					entry.SubMenu = string(submenu.(lua.LString))
+					entry.SubMenu = lua.LVAsString(submenu)
 				}
 
 				if val := item.RawGetString("Value"); val != lua.LNil {
-					entry.Value = string(val.(lua.LString))
+					entry.Value = lua.LVAsString(val)
 				}
 
 				if icon := item.RawGetString("Icon"); icon != lua.LNil {
-					entry.Icon = string(icon.(lua.LString))
+					entry.Icon = lua.LVAsString(icon)
 				}
 
 				if actions := item.RawGet(lua.LString("Actions")); actions != lua.LNil {
@@ -430,10 +455,52 @@ func (e Entry) CreateIdentifier() string {
 var (
 	MenuConfigLoaded MenuConfig
 	menuname         = "menus"
+	menuLogger       = ProviderLogger(menuname)
 	Menus            = make(map[string]*Menu)
 	host             = ""
 )
 
+// menuDirs returns the default directories menus are loaded from.
+func menuDirs() []string {
+	res := []string{}
+
+	for _, v := range ConfigDirs() {
+		res = append(res, filepath.Join(v, "menus"))
+	}
+
+	return append(res, filepath.Join(xdg.DataHome, "elephant", "install"))
+}
+
+// ValidateMenu checks if a menu definition can be parsed. Lua menus are only
+// compiled, not run.
+func ValidateMenu(path string) error {
+	ext := filepath.Ext(path)
+
+	if ext != ".toml" && ext != ".lua" {
+		return nil
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	if ext == ".toml" {
+		return toml.Unmarshal(b, &Menu{})
+	}
+
+	m := Menu{LuaString: string(b), IsLua: true}
+
+	l, err := m.newLuaState()
+	if err != nil {
+		return err
+	}
+
+	l.Close()
+
+	return nil
+}
+
 func LoadMenus() {
 	host, _ = os.Hostname()
 
@@ -446,18 +513,21 @@ func LoadMenus() {
 
 	LoadConfig(menuname, &MenuConfigLoaded)
 
-	for _, v := range ConfigDirs() {
-		path := filepath.Join(v, "menus")
-		MenuConfigLoaded.Paths = append(MenuConfigLoaded.Paths, path)
-	}
-
-	installed := filepath.Join(xdg.DataHome, "elephant", "install")
-	MenuConfigLoaded.Paths = append(MenuConfigLoaded.Paths, installed)
+	MenuConfigLoaded.Paths = append(MenuConfigLoaded.Paths, menuDirs()...)
 
 	conf := fastwalk.Config{
 		Follow: true,
 	}
 
+	var mut sync.Mutex
+	menus := make(map[string]*Menu)
+
+	add := func(m *Menu) {
+		mut.Lock()
+		menus[m.Name] = m
+		mut.Unlock()
+	}
+
 	for _, root := range MenuConfigLoaded.Paths {
 		if _, err := os.Stat(root); err != nil {
 			continue
@@ -474,55 +544,70 @@ func LoadMenus() {
 
 			switch filepath.Ext(path) {
 			case ".toml":
-				createTomlMenu(path)
+				createTomlMenu(path, add)
 			case ".lua":
-				createLuaMenu(path)
+				if err := createLuaMenu(path, add); err != nil {
+					menuLogger.Error(menuname, "path", path, "error", err)
+				}
 			}
 
 			return nil
 		}); err != nil {
-			slog.Error(menuname, "walk", err)
+			menuLogger.Error(menuname, "walk", err)
 			os.Exit(1)
 		}
 	}
+
+	// swap in the new menus at once, so reloading never exposes a partial
+	// set of menus.
+	old := Menus
+	Menus = menus
+
+	for _, v := range old {
+		v.stop()
+	}
 }
 
-func createLuaMenu(path string) {
+// createLuaMenu adds the menu defined by the Lua file at path.
+func createLuaMenu(path string, add func(*Menu)) error {
 	m := Menu{}
 	m.IsLua = true
 
 	b, err := os.ReadFile(path)
 	if err != nil {
-		slog.Error(m.Name, "lua read", err)
-		return
+		return err
 	}
 
 	m.LuaString = string(b)
 
-	state := m.NewLuaState()
+	state, err := m.newLuaState()
+	if err != nil {
+		return err
+	}
+	defer state.Close()
 
 	if val := state.GetGlobal("Name"); val != lua.LNil {
-		m.Name = string(val.(lua.LString))
+		m.Name = lua.LVAsString(val)
 	}
 
 	if val := state.GetGlobal("NamePretty"); val != lua.LNil {
-		m.NamePretty = string(val.(lua.LString))
+		m.NamePretty = lua.LVAsString(val)
 	}
 
 	if val := state.GetGlobal("HideFromProviderlist"); val != lua.LNil {
-		m.HideFromProviderlist = bool(val.(lua.LBool))
+		m.HideFromProviderlist = lua.LVAsBool(val)
 	}
 
 	if val := state.GetGlobal("Description"); val != lua.LNil {
-		m.Description = string(val.(lua.LString))
+		m.Description = lua.LVAsString(val)
 	}
 
 	if val := state.GetGlobal("Icon"); val != lua.LNil {
-		m.Icon = string(val.(lua.LString))
+		m.Icon = lua.LVAsString(val)
 	}
 
 	if val := state.GetGlobal("Action"); val != lua.LNil {
-		m.Action = string(val.(lua.LString))
+		m.Action = lua.LVAsString(val)
 	}
 
 	if val := state.GetGlobal("Actions"); val != lua.LNil {
@@ -539,15 +624,15 @@ func createLuaMenu(path string) {
 	}
 
 	if val := state.GetGlobal("SearchName"); val != lua.LNil {
-		m.SearchName = bool(val.(lua.LBool))
+		m.SearchName = lua.LVAsBool(val)
 	}
 
 	if val := state.GetGlobal("Cache"); val != lua.LNil {
-		m.Cache = bool(val.(lua.LBool))
+		m.Cache = lua.LVAsBool(val)
 	}
 
 	if val := state.GetGlobal("Terminal"); val != lua.LNil {
-		m.Terminal = bool(val.(lua.LBool))
+		m.Terminal = lua.LVAsBool(val)
 	}
 
 	if val := state.GetGlobal("Keywords"); val != lua.LNil {
@@ -595,31 +680,31 @@ func createLuaMenu(path string) {
 	}
 
 	if len(m.Hosts) > 0 && !slices.Contains(m.Hosts, host) {
-		return
+		return nil
 	}
 
 	if val := state.GetGlobal("FixedOrder"); val != lua.LNil {
-		m.FixedOrder = bool(val.(lua.LBool))
+		m.FixedOrder = lua.LVAsBool(val)
 	}
 
 	if val := state.GetGlobal("History"); val != lua.LNil {
-		m.History = bool(val.(lua.LBool))
+		m.History = lua.LVAsBool(val)
 	}
 
 	if val := state.GetGlobal("HistoryWhenEmpty"); val != lua.LNil {
-		m.HistoryWhenEmpty = bool(val.(lua.LBool))
+		m.HistoryWhenEmpty = lua.LVAsBool(val)
 	}
 
 	if val := state.GetGlobal("MinScore"); val != lua.LNil {
-		m.MinScore = int32(val.(lua.LNumber))
+		m.MinScore = int32(lua.LVAsNumber(val))
 	}
 
 	if val := state.GetGlobal("Parent"); val != lua.LNil {
-		m.Parent = string(val.(lua.LString))
+		m.Parent = lua.LVAsString(val)
 	}
 
 	if val := state.GetGlobal("SubMenu"); val != lua.LNil {
-		m.SubMenu = string(val.(lua.LString))
+		m.SubMenu = lua.LVAsString(val)
 	}
 
 	if len(m.RefreshOnChange) > 0 {
@@ -627,32 +712,34 @@ func createLuaMenu(path string) {
 	}
 
 	if m.Cache {
-		m.CreateLuaEntries("")
+		RecoverMenu(func() { m.CreateLuaEntries("") })
+	}
+
+	if m.Name == "" || m.NamePretty == "" {
+		return errors.New("missing Name or NamePretty")
 	}
 
 	if len(m.RefreshOnChange) > 0 {
+		m.done = make(chan struct{})
 		go m.watch()
 	}
 
-	if m.Name == "" || m.NamePretty == "" {
-		slog.Error("menus", "path", path, "error", "missing Name or NamePretty")
-		return
-	}
+	add(&m)
 
-	Menus[m.Name] = &m
+	return nil
 }
 
-func createTomlMenu(path string) {
+func createTomlMenu(path string, add func(*Menu)) {
 	m := Menu{}
 
 	b, err := os.ReadFile(path)
 	if err != nil {
-		slog.Error(menuname, "setup", err)
+		menuLogger.Error(menuname, "setup", err)
 	}
 
 	err = toml.Unmarshal(b, &m)
 	if err != nil {
-		slog.Error(menuname, "setup", err)
+		menuLogger.Error(menuname, "setup", err)
 	}
 
 	for k, v := range m.Entries {
@@ -672,5 +759,5 @@ func createTomlMenu(path string) {
 		return
 	}
 
-	Menus[m.Name] = &m
+	add(&m)
 }
@@ -0,0 +1,9 @@
+package common
+
+// Metric is a gauge reported by a provider for the metrics export. It's
+// exported as elephant_<name> with the provider as label.
+type Metric struct {
+	Name  string
+	Help  string
+	Value float64
+}
@@ -0,0 +1,115 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/knadh/koanf/parsers/toml/v2"
+)
+
+var (
+	profile    string
+	profileMut sync.Mutex
+)
+
+var ErrUnknownProfile = errors.New("unknown profile")
+
+// CheckProfile returns ErrUnknownProfile if no config file defines the
+// profile. Configs not defining it use their base config when it's active.
+func CheckProfile(name string) error {
+	if name == "" {
+		return nil
+	}
+
+	for _, dir := range ConfigDirs() {
+		files, _ := filepath.Glob(filepath.Join(dir, "*.toml"))
+
+		for _, file := range files {
+			b, err := os.ReadFile(file)
+			if err != nil {
+				continue
+			}
+
+			m, err := toml.Parser().Unmarshal(b)
+			if err != nil {
+				continue
+			}
+
+			if profiles, ok := m["profiles"].(map[string]any); ok {
+				if _, ok := profiles[name]; ok {
+					return nil
+				}
+			}
+		}
+	}
+
+	return fmt.Errorf("%w: %s", ErrUnknownProfile, name)
+}
+
+// SetProfile sets the profile whose overlays are merged on top of the
+// configs. An empty profile only uses the base configs. Configs have to be
+// reloaded afterwards.
+func SetProfile(name string) {
+	profileMut.Lock()
+	profile = name
+	profileMut.Unlock()
+
+	slog.Info("config", "profile", name)
+}
+
+// Profile returns the active profile.
+func Profile() string {
+	profileMut.Lock()
+	defer profileMut.Unlock()
+
+	return profile
+}
+
+// profileOverlay removes the profiles table from a parsed config and returns
+// the overlay of the active profile, nil if the config doesn't define it.
+// Unknown profiles are rejected by CheckProfile before they become active.
+func profileOverlay(m map[string]any) (map[string]any, error) {
+	raw, ok := m["profiles"]
+	if !ok {
+		return nil, nil
+	}
+
+	delete(m, "profiles")
+
+	profiles, ok := raw.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("profiles: expected a table, got %T", raw)
+	}
+
+	p := Profile()
+	if p == "" {
+		return nil, nil
+	}
+
+	overlay, ok := profiles[p]
+	if !ok {
+		return nil, nil
+	}
+
+	res, ok := overlay.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("profiles.%s: expected a table, got %T", p, overlay)
+	}
+
+	return res, nil
+}
+
+// rawMap is a koanf provider for an already parsed config.
+type rawMap map[string]any
+
+func (r rawMap) ReadBytes() ([]byte, error) {
+	return nil, fmt.Errorf("rawMap does not support ReadBytes()")
+}
+
+func (r rawMap) Read() (map[string]any, error) {
+	return r, nil
+}
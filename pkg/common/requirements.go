@@ -0,0 +1,46 @@
+package common
+
+import (
+	"fmt"
+	"log/slog"
+	"os/exec"
+	"strings"
+)
+
+// Requirement is an external tool a provider depends on. Providers export
+// their requirements as Requirements.
+type Requirement struct {
+	// Name of the tool or its package, f.e. "imagemagick".
+	Name string
+	// Executables of which at least one has to be in $PATH.
+	Executables []string
+	// Optional requirements only enable additional functionality.
+	Optional bool
+}
+
+// Find returns the path of the first executable found in $PATH.
+func (r Requirement) Find() string {
+	for _, v := range r.Executables {
+		if p, err := exec.LookPath(v); err == nil && p != "" {
+			return p
+		}
+	}
+
+	return ""
+}
+
+// RequirementsMet reports whether all non-optional requirements are found.
+// Missing ones are logged.
+func RequirementsMet(provider string, reqs []Requirement) bool {
+	for _, v := range reqs {
+		if v.Optional || v.Find() != "" {
+			continue
+		}
+
+		slog.Info(provider, "available", fmt.Sprintf("%s (%s) not found. disabling", v.Name, strings.Join(v.Executables, " or ")))
+
+		return false
+	}
+
+	return true
+}
@@ -9,13 +9,14 @@ import (
 var runPrefix = ""
 
 func InitRunPrefix() {
-	runPrefix = elephantConfig.LaunchPrefix
+	cfg := GetElephantConfig()
+	runPrefix = cfg.LaunchPrefix
 
 	if runPrefix != "" {
 		return
 	}
 
-	if !elephantConfig.AutoDetectLaunchPrefix {
+	if !cfg.AutoDetectLaunchPrefix {
 		return
 	}
 
@@ -0,0 +1,248 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.7
+// 	protoc        v6.32.1
+// source: error.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ErrorResponse_Code int32
+
+const (
+	ErrorResponse_UNKNOWN           ErrorResponse_Code = 0
+	ErrorResponse_INVALID_REQUEST   ErrorResponse_Code = 1
+	ErrorResponse_UNKNOWN_REQUEST   ErrorResponse_Code = 2
+	ErrorResponse_UNKNOWN_PROVIDER  ErrorResponse_Code = 3
+	ErrorResponse_UNAUTHENTICATED   ErrorResponse_Code = 4
+	ErrorResponse_FORBIDDEN         ErrorResponse_Code = 5
+	ErrorResponse_ACTIVATION_FAILED ErrorResponse_Code = 6
+	ErrorResponse_INTERNAL          ErrorResponse_Code = 7
+	ErrorResponse_INVALID_CONFIG    ErrorResponse_Code = 8
+	ErrorResponse_PROVIDER_CRASHED  ErrorResponse_Code = 9
+	ErrorResponse_PROVIDER_DISABLED ErrorResponse_Code = 10
+)
+
+// Enum value maps for ErrorResponse_Code.
+var (
+	ErrorResponse_Code_name = map[int32]string{
+		0:  "UNKNOWN",
+		1:  "INVALID_REQUEST",
+		2:  "UNKNOWN_REQUEST",
+		3:  "UNKNOWN_PROVIDER",
+		4:  "UNAUTHENTICATED",
+		5:  "FORBIDDEN",
+		6:  "ACTIVATION_FAILED",
+		7:  "INTERNAL",
+		8:  "INVALID_CONFIG",
+		9:  "PROVIDER_CRASHED",
+		10: "PROVIDER_DISABLED",
+	}
+	ErrorResponse_Code_value = map[string]int32{
+		"UNKNOWN":           0,
+		"INVALID_REQUEST":   1,
+		"UNKNOWN_REQUEST":   2,
+		"UNKNOWN_PROVIDER":  3,
+		"UNAUTHENTICATED":   4,
+		"FORBIDDEN":         5,
+		"ACTIVATION_FAILED": 6,
+		"INTERNAL":          7,
+		"INVALID_CONFIG":    8,
+		"PROVIDER_CRASHED":  9,
+		"PROVIDER_DISABLED": 10,
+	}
+)
+
+func (x ErrorResponse_Code) Enum() *ErrorResponse_Code {
+	p := new(ErrorResponse_Code)
+	*p = x
+	return p
+}
+
+func (x ErrorResponse_Code) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ErrorResponse_Code) Descriptor() protoreflect.EnumDescriptor {
+	return file_error_proto_enumTypes[0].Descriptor()
+}
+
+func (ErrorResponse_Code) Type() protoreflect.EnumType {
+	return &file_error_proto_enumTypes[0]
+}
+
+func (x ErrorResponse_Code) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ErrorResponse_Code.Descriptor instead.
+func (ErrorResponse_Code) EnumDescriptor() ([]byte, []int) {
+	return file_error_proto_rawDescGZIP(), []int{0, 0}
+}
+
+type ErrorResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          ErrorResponse_Code     `protobuf:"varint,1,opt,name=code,proto3,enum=pb.ErrorResponse_Code" json:"code,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	Type          int32                  `protobuf:"varint,3,opt,name=type,proto3" json:"type,omitempty"`
+	Rid           uint32                 `protobuf:"varint,4,opt,name=rid,proto3" json:"rid,omitempty"`
+	Provider      string                 `protobuf:"bytes,5,opt,name=provider,proto3" json:"provider,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ErrorResponse) Reset() {
+	*x = ErrorResponse{}
+	mi := &file_error_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ErrorResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ErrorResponse) ProtoMessage() {}
+
+func (x *ErrorResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_error_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ErrorResponse.ProtoReflect.Descriptor instead.
+func (*ErrorResponse) Descriptor() ([]byte, []int) {
+	return file_error_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ErrorResponse) GetCode() ErrorResponse_Code {
+	if x != nil {
+		return x.Code
+	}
+	return ErrorResponse_UNKNOWN
+}
+
+func (x *ErrorResponse) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *ErrorResponse) GetType() int32 {
+	if x != nil {
+		return x.Type
+	}
+	return 0
+}
+
+func (x *ErrorResponse) GetRid() uint32 {
+	if x != nil {
+		return x.Rid
+	}
+	return 0
+}
+
+func (x *ErrorResponse) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
+var File_error_proto protoreflect.FileDescriptor
+
+const file_error_proto_rawDesc = "" +
+	"\n" +
+	"\verror.proto\x12\x02pb\"\xf7\x02\n" +
+	"\rErrorResponse\x12*\n" +
+	"\x04code\x18\x01 \x01(\x0e2\x16.pb.ErrorResponse.CodeR\x04code\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12\x12\n" +
+	"\x04type\x18\x03 \x01(\x05R\x04type\x12\x10\n" +
+	"\x03rid\x18\x04 \x01(\rR\x03rid\x12\x1a\n" +
+	"\bprovider\x18\x05 \x01(\tR\bprovider\"\xdd\x01\n" +
+	"\x04Code\x12\v\n" +
+	"\aUNKNOWN\x10\x00\x12\x13\n" +
+	"\x0fINVALID_REQUEST\x10\x01\x12\x13\n" +
+	"\x0fUNKNOWN_REQUEST\x10\x02\x12\x14\n" +
+	"\x10UNKNOWN_PROVIDER\x10\x03\x12\x13\n" +
+	"\x0fUNAUTHENTICATED\x10\x04\x12\r\n" +
+	"\tFORBIDDEN\x10\x05\x12\x15\n" +
+	"\x11ACTIVATION_FAILED\x10\x06\x12\f\n" +
+	"\bINTERNAL\x10\a\x12\x12\n" +
+	"\x0eINVALID_CONFIG\x10\b\x12\x14\n" +
+	"\x10PROVIDER_CRASHED\x10\t\x12\x15\n" +
+	"\x11PROVIDER_DISABLED\x10\n" +
+	"B\x06Z\x04./pbb\x06proto3"
+
+var (
+	file_error_proto_rawDescOnce sync.Once
+	file_error_proto_rawDescData []byte
+)
+
+func file_error_proto_rawDescGZIP() []byte {
+	file_error_proto_rawDescOnce.Do(func() {
+		file_error_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_error_proto_rawDesc), len(file_error_proto_rawDesc)))
+	})
+	return file_error_proto_rawDescData
+}
+
+var file_error_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_error_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_error_proto_goTypes = []any{
+	(ErrorResponse_Code)(0), // 0: pb.ErrorResponse.Code
+	(*ErrorResponse)(nil),   // 1: pb.ErrorResponse
+}
+var file_error_proto_depIdxs = []int32{
+	0, // 0: pb.ErrorResponse.code:type_name -> pb.ErrorResponse.Code
+	1, // [1:1] is the sub-list for method output_type
+	1, // [1:1] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_error_proto_init() }
+func file_error_proto_init() {
+	if File_error_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_error_proto_rawDesc), len(file_error_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   1,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_error_proto_goTypes,
+		DependencyIndexes: file_error_proto_depIdxs,
+		EnumInfos:         file_error_proto_enumTypes,
+		MessageInfos:      file_error_proto_msgTypes,
+	}.Build()
+	File_error_proto = out.File
+	file_error_proto_goTypes = nil
+	file_error_proto_depIdxs = nil
+}
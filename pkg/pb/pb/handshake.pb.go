@@ -0,0 +1,356 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.7
+// 	protoc        v6.32.1
+// source: handshake.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type HandshakeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Version       string                 `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`
+	Protocol      int32                  `protobuf:"varint,2,opt,name=protocol,proto3" json:"protocol,omitempty"`
+	Rid           uint32                 `protobuf:"varint,3,opt,name=rid,proto3" json:"rid,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HandshakeRequest) Reset() {
+	*x = HandshakeRequest{}
+	mi := &file_handshake_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HandshakeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HandshakeRequest) ProtoMessage() {}
+
+func (x *HandshakeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_handshake_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HandshakeRequest.ProtoReflect.Descriptor instead.
+func (*HandshakeRequest) Descriptor() ([]byte, []int) {
+	return file_handshake_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *HandshakeRequest) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *HandshakeRequest) GetProtocol() int32 {
+	if x != nil {
+		return x.Protocol
+	}
+	return 0
+}
+
+func (x *HandshakeRequest) GetRid() uint32 {
+	if x != nil {
+		return x.Rid
+	}
+	return 0
+}
+
+type HandshakeResponse struct {
+	state         protoimpl.MessageState        `protogen:"open.v1"`
+	Version       string                        `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`
+	Protocol      int32                         `protobuf:"varint,2,opt,name=protocol,proto3" json:"protocol,omitempty"`
+	Requests      []*HandshakeResponse_Request  `protobuf:"bytes,3,rep,name=requests,proto3" json:"requests,omitempty"`
+	Formats       []string                      `protobuf:"bytes,4,rep,name=formats,proto3" json:"formats,omitempty"`
+	Providers     []*HandshakeResponse_Provider `protobuf:"bytes,5,rep,name=providers,proto3" json:"providers,omitempty"`
+	Rid           uint32                        `protobuf:"varint,6,opt,name=rid,proto3" json:"rid,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HandshakeResponse) Reset() {
+	*x = HandshakeResponse{}
+	mi := &file_handshake_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HandshakeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HandshakeResponse) ProtoMessage() {}
+
+func (x *HandshakeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_handshake_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HandshakeResponse.ProtoReflect.Descriptor instead.
+func (*HandshakeResponse) Descriptor() ([]byte, []int) {
+	return file_handshake_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *HandshakeResponse) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *HandshakeResponse) GetProtocol() int32 {
+	if x != nil {
+		return x.Protocol
+	}
+	return 0
+}
+
+func (x *HandshakeResponse) GetRequests() []*HandshakeResponse_Request {
+	if x != nil {
+		return x.Requests
+	}
+	return nil
+}
+
+func (x *HandshakeResponse) GetFormats() []string {
+	if x != nil {
+		return x.Formats
+	}
+	return nil
+}
+
+func (x *HandshakeResponse) GetProviders() []*HandshakeResponse_Provider {
+	if x != nil {
+		return x.Providers
+	}
+	return nil
+}
+
+func (x *HandshakeResponse) GetRid() uint32 {
+	if x != nil {
+		return x.Rid
+	}
+	return 0
+}
+
+type HandshakeResponse_Request struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          int32                  `protobuf:"varint,1,opt,name=type,proto3" json:"type,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HandshakeResponse_Request) Reset() {
+	*x = HandshakeResponse_Request{}
+	mi := &file_handshake_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HandshakeResponse_Request) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HandshakeResponse_Request) ProtoMessage() {}
+
+func (x *HandshakeResponse_Request) ProtoReflect() protoreflect.Message {
+	mi := &file_handshake_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HandshakeResponse_Request.ProtoReflect.Descriptor instead.
+func (*HandshakeResponse_Request) Descriptor() ([]byte, []int) {
+	return file_handshake_proto_rawDescGZIP(), []int{1, 0}
+}
+
+func (x *HandshakeResponse_Request) GetType() int32 {
+	if x != nil {
+		return x.Type
+	}
+	return 0
+}
+
+func (x *HandshakeResponse_Request) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type HandshakeResponse_Provider struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	NamePretty    string                 `protobuf:"bytes,2,opt,name=name_pretty,json=namePretty,proto3" json:"name_pretty,omitempty"`
+	Capabilities  []string               `protobuf:"bytes,3,rep,name=capabilities,proto3" json:"capabilities,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HandshakeResponse_Provider) Reset() {
+	*x = HandshakeResponse_Provider{}
+	mi := &file_handshake_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HandshakeResponse_Provider) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HandshakeResponse_Provider) ProtoMessage() {}
+
+func (x *HandshakeResponse_Provider) ProtoReflect() protoreflect.Message {
+	mi := &file_handshake_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HandshakeResponse_Provider.ProtoReflect.Descriptor instead.
+func (*HandshakeResponse_Provider) Descriptor() ([]byte, []int) {
+	return file_handshake_proto_rawDescGZIP(), []int{1, 1}
+}
+
+func (x *HandshakeResponse_Provider) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *HandshakeResponse_Provider) GetNamePretty() string {
+	if x != nil {
+		return x.NamePretty
+	}
+	return ""
+}
+
+func (x *HandshakeResponse_Provider) GetCapabilities() []string {
+	if x != nil {
+		return x.Capabilities
+	}
+	return nil
+}
+
+var File_handshake_proto protoreflect.FileDescriptor
+
+const file_handshake_proto_rawDesc = "" +
+	"\n" +
+	"\x0fhandshake.proto\x12\x02pb\"Z\n" +
+	"\x10HandshakeRequest\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\tR\aversion\x12\x1a\n" +
+	"\bprotocol\x18\x02 \x01(\x05R\bprotocol\x12\x10\n" +
+	"\x03rid\x18\x03 \x01(\rR\x03rid\"\x86\x03\n" +
+	"\x11HandshakeResponse\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\tR\aversion\x12\x1a\n" +
+	"\bprotocol\x18\x02 \x01(\x05R\bprotocol\x129\n" +
+	"\brequests\x18\x03 \x03(\v2\x1d.pb.HandshakeResponse.RequestR\brequests\x12\x18\n" +
+	"\aformats\x18\x04 \x03(\tR\aformats\x12<\n" +
+	"\tproviders\x18\x05 \x03(\v2\x1e.pb.HandshakeResponse.ProviderR\tproviders\x12\x10\n" +
+	"\x03rid\x18\x06 \x01(\rR\x03rid\x1a1\n" +
+	"\aRequest\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\x05R\x04type\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x1ac\n" +
+	"\bProvider\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1f\n" +
+	"\vname_pretty\x18\x02 \x01(\tR\n" +
+	"namePretty\x12\"\n" +
+	"\fcapabilities\x18\x03 \x03(\tR\fcapabilitiesB\x06Z\x04./pbb\x06proto3"
+
+var (
+	file_handshake_proto_rawDescOnce sync.Once
+	file_handshake_proto_rawDescData []byte
+)
+
+func file_handshake_proto_rawDescGZIP() []byte {
+	file_handshake_proto_rawDescOnce.Do(func() {
+		file_handshake_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_handshake_proto_rawDesc), len(file_handshake_proto_rawDesc)))
+	})
+	return file_handshake_proto_rawDescData
+}
+
+var file_handshake_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_handshake_proto_goTypes = []any{
+	(*HandshakeRequest)(nil),           // 0: pb.HandshakeRequest
+	(*HandshakeResponse)(nil),          // 1: pb.HandshakeResponse
+	(*HandshakeResponse_Request)(nil),  // 2: pb.HandshakeResponse.Request
+	(*HandshakeResponse_Provider)(nil), // 3: pb.HandshakeResponse.Provider
+}
+var file_handshake_proto_depIdxs = []int32{
+	2, // 0: pb.HandshakeResponse.requests:type_name -> pb.HandshakeResponse.Request
+	3, // 1: pb.HandshakeResponse.providers:type_name -> pb.HandshakeResponse.Provider
+	2, // [2:2] is the sub-list for method output_type
+	2, // [2:2] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_handshake_proto_init() }
+func file_handshake_proto_init() {
+	if File_handshake_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_handshake_proto_rawDesc), len(file_handshake_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   4,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_handshake_proto_goTypes,
+		DependencyIndexes: file_handshake_proto_depIdxs,
+		MessageInfos:      file_handshake_proto_msgTypes,
+	}.Build()
+	File_handshake_proto = out.File
+	file_handshake_proto_goTypes = nil
+	file_handshake_proto_depIdxs = nil
+}
@@ -24,6 +24,7 @@ const (
 type MenuRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Menu          string                 `protobuf:"bytes,1,opt,name=menu,proto3" json:"menu,omitempty"`
+	Rid           uint32                 `protobuf:"varint,2,opt,name=rid,proto3" json:"rid,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -65,14 +66,22 @@ func (x *MenuRequest) GetMenu() string {
 	return ""
 }
 
+func (x *MenuRequest) GetRid() uint32 {
+	if x != nil {
+		return x.Rid
+	}
+	return 0
+}
+
 var File_menu_proto protoreflect.FileDescriptor
 
 const file_menu_proto_rawDesc = "" +
 	"\n" +
 	"\n" +
-	"menu.proto\x12\x02pb\"!\n" +
+	"menu.proto\x12\x02pb\"3\n" +
 	"\vMenuRequest\x12\x12\n" +
-	"\x04menu\x18\x01 \x01(\tR\x04menuB\x06Z\x04./pbb\x06proto3"
+	"\x04menu\x18\x01 \x01(\tR\x04menu\x12\x10\n" +
+	"\x03rid\x18\x02 \x01(\rR\x03ridB\x06Z\x04./pbb\x06proto3"
 
 var (
 	file_menu_proto_rawDescOnce sync.Once
@@ -24,6 +24,7 @@ const (
 type ProviderStateRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Provider      string                 `protobuf:"bytes,1,opt,name=provider,proto3" json:"provider,omitempty"`
+	Rid           uint32                 `protobuf:"varint,2,opt,name=rid,proto3" json:"rid,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -65,11 +66,19 @@ func (x *ProviderStateRequest) GetProvider() string {
 	return ""
 }
 
+func (x *ProviderStateRequest) GetRid() uint32 {
+	if x != nil {
+		return x.Rid
+	}
+	return 0
+}
+
 type ProviderStateResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	States        []string               `protobuf:"bytes,1,rep,name=states,proto3" json:"states,omitempty"`
 	Actions       []string               `protobuf:"bytes,2,rep,name=actions,proto3" json:"actions,omitempty"`
 	Provider      string                 `protobuf:"bytes,3,opt,name=provider,proto3" json:"provider,omitempty"`
+	Rid           uint32                 `protobuf:"varint,4,opt,name=rid,proto3" json:"rid,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -125,17 +134,26 @@ func (x *ProviderStateResponse) GetProvider() string {
 	return ""
 }
 
+func (x *ProviderStateResponse) GetRid() uint32 {
+	if x != nil {
+		return x.Rid
+	}
+	return 0
+}
+
 var File_providerstate_proto protoreflect.FileDescriptor
 
 const file_providerstate_proto_rawDesc = "" +
 	"\n" +
-	"\x13providerstate.proto\x12\x02pb\"2\n" +
+	"\x13providerstate.proto\x12\x02pb\"D\n" +
 	"\x14ProviderStateRequest\x12\x1a\n" +
-	"\bprovider\x18\x01 \x01(\tR\bprovider\"e\n" +
+	"\bprovider\x18\x01 \x01(\tR\bprovider\x12\x10\n" +
+	"\x03rid\x18\x02 \x01(\rR\x03rid\"w\n" +
 	"\x15ProviderStateResponse\x12\x16\n" +
 	"\x06states\x18\x01 \x03(\tR\x06states\x12\x18\n" +
 	"\aactions\x18\x02 \x03(\tR\aactions\x12\x1a\n" +
-	"\bprovider\x18\x03 \x01(\tR\bproviderB\x06Z\x04./pbb\x06proto3"
+	"\bprovider\x18\x03 \x01(\tR\bprovider\x12\x10\n" +
+	"\x03rid\x18\x04 \x01(\rR\x03ridB\x06Z\x04./pbb\x06proto3"
 
 var (
 	file_providerstate_proto_rawDescOnce sync.Once
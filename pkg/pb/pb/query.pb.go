@@ -73,6 +73,9 @@ type QueryRequest struct {
 	Query         string                 `protobuf:"bytes,2,opt,name=query,proto3" json:"query,omitempty"`
 	Maxresults    int32                  `protobuf:"varint,3,opt,name=maxresults,proto3" json:"maxresults,omitempty"`
 	Exactsearch   bool                   `protobuf:"varint,4,opt,name=exactsearch,proto3" json:"exactsearch,omitempty"`
+	Rid           uint32                 `protobuf:"varint,5,opt,name=rid,proto3" json:"rid,omitempty"`
+	Stream        bool                   `protobuf:"varint,6,opt,name=stream,proto3" json:"stream,omitempty"`
+	Offset        int32                  `protobuf:"varint,7,opt,name=offset,proto3" json:"offset,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -135,11 +138,34 @@ func (x *QueryRequest) GetExactsearch() bool {
 	return false
 }
 
+func (x *QueryRequest) GetRid() uint32 {
+	if x != nil {
+		return x.Rid
+	}
+	return 0
+}
+
+func (x *QueryRequest) GetStream() bool {
+	if x != nil {
+		return x.Stream
+	}
+	return false
+}
+
+func (x *QueryRequest) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
 type QueryResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Query         string                 `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
 	Item          *QueryResponse_Item    `protobuf:"bytes,2,opt,name=item,proto3" json:"item,omitempty"`
 	Qid           int32                  `protobuf:"varint,3,opt,name=qid,proto3" json:"qid,omitempty"`
+	Rid           uint32                 `protobuf:"varint,4,opt,name=rid,proto3" json:"rid,omitempty"`
+	Total         int32                  `protobuf:"varint,5,opt,name=total,proto3" json:"total,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -195,6 +221,156 @@ func (x *QueryResponse) GetQid() int32 {
 	return 0
 }
 
+func (x *QueryResponse) GetRid() uint32 {
+	if x != nil {
+		return x.Rid
+	}
+	return 0
+}
+
+func (x *QueryResponse) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+type QueryProviderDone struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Provider      string                 `protobuf:"bytes,1,opt,name=provider,proto3" json:"provider,omitempty"`
+	Results       int32                  `protobuf:"varint,2,opt,name=results,proto3" json:"results,omitempty"`
+	Qid           int32                  `protobuf:"varint,3,opt,name=qid,proto3" json:"qid,omitempty"`
+	Rid           uint32                 `protobuf:"varint,4,opt,name=rid,proto3" json:"rid,omitempty"`
+	Timedout      bool                   `protobuf:"varint,5,opt,name=timedout,proto3" json:"timedout,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QueryProviderDone) Reset() {
+	*x = QueryProviderDone{}
+	mi := &file_query_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QueryProviderDone) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueryProviderDone) ProtoMessage() {}
+
+func (x *QueryProviderDone) ProtoReflect() protoreflect.Message {
+	mi := &file_query_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueryProviderDone.ProtoReflect.Descriptor instead.
+func (*QueryProviderDone) Descriptor() ([]byte, []int) {
+	return file_query_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *QueryProviderDone) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
+func (x *QueryProviderDone) GetResults() int32 {
+	if x != nil {
+		return x.Results
+	}
+	return 0
+}
+
+func (x *QueryProviderDone) GetQid() int32 {
+	if x != nil {
+		return x.Qid
+	}
+	return 0
+}
+
+func (x *QueryProviderDone) GetRid() uint32 {
+	if x != nil {
+		return x.Rid
+	}
+	return 0
+}
+
+func (x *QueryProviderDone) GetTimedout() bool {
+	if x != nil {
+		return x.Timedout
+	}
+	return false
+}
+
+type QueryOrder struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Entries       []*QueryOrder_Entry    `protobuf:"bytes,1,rep,name=entries,proto3" json:"entries,omitempty"`
+	Qid           int32                  `protobuf:"varint,2,opt,name=qid,proto3" json:"qid,omitempty"`
+	Rid           uint32                 `protobuf:"varint,3,opt,name=rid,proto3" json:"rid,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QueryOrder) Reset() {
+	*x = QueryOrder{}
+	mi := &file_query_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QueryOrder) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueryOrder) ProtoMessage() {}
+
+func (x *QueryOrder) ProtoReflect() protoreflect.Message {
+	mi := &file_query_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueryOrder.ProtoReflect.Descriptor instead.
+func (*QueryOrder) Descriptor() ([]byte, []int) {
+	return file_query_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *QueryOrder) GetEntries() []*QueryOrder_Entry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+func (x *QueryOrder) GetQid() int32 {
+	if x != nil {
+		return x.Qid
+	}
+	return 0
+}
+
+func (x *QueryOrder) GetRid() uint32 {
+	if x != nil {
+		return x.Rid
+	}
+	return 0
+}
+
 type QueryResponse_Item struct {
 	state         protoimpl.MessageState        `protogen:"open.v1"`
 	Identifier    string                        `protobuf:"bytes,1,opt,name=identifier,proto3" json:"identifier,omitempty"`
@@ -216,7 +392,7 @@ type QueryResponse_Item struct {
 
 func (x *QueryResponse_Item) Reset() {
 	*x = QueryResponse_Item{}
-	mi := &file_query_proto_msgTypes[2]
+	mi := &file_query_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -228,7 +404,7 @@ func (x *QueryResponse_Item) String() string {
 func (*QueryResponse_Item) ProtoMessage() {}
 
 func (x *QueryResponse_Item) ProtoReflect() protoreflect.Message {
-	mi := &file_query_proto_msgTypes[2]
+	mi := &file_query_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -346,7 +522,7 @@ type QueryResponse_Item_FuzzyInfo struct {
 
 func (x *QueryResponse_Item_FuzzyInfo) Reset() {
 	*x = QueryResponse_Item_FuzzyInfo{}
-	mi := &file_query_proto_msgTypes[3]
+	mi := &file_query_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -358,7 +534,7 @@ func (x *QueryResponse_Item_FuzzyInfo) String() string {
 func (*QueryResponse_Item_FuzzyInfo) ProtoMessage() {}
 
 func (x *QueryResponse_Item_FuzzyInfo) ProtoReflect() protoreflect.Message {
-	mi := &file_query_proto_msgTypes[3]
+	mi := &file_query_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -395,22 +571,79 @@ func (x *QueryResponse_Item_FuzzyInfo) GetPositions() []int32 {
 	return nil
 }
 
+type QueryOrder_Entry struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Provider      string                 `protobuf:"bytes,1,opt,name=provider,proto3" json:"provider,omitempty"`
+	Identifier    string                 `protobuf:"bytes,2,opt,name=identifier,proto3" json:"identifier,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QueryOrder_Entry) Reset() {
+	*x = QueryOrder_Entry{}
+	mi := &file_query_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QueryOrder_Entry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueryOrder_Entry) ProtoMessage() {}
+
+func (x *QueryOrder_Entry) ProtoReflect() protoreflect.Message {
+	mi := &file_query_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueryOrder_Entry.ProtoReflect.Descriptor instead.
+func (*QueryOrder_Entry) Descriptor() ([]byte, []int) {
+	return file_query_proto_rawDescGZIP(), []int{3, 0}
+}
+
+func (x *QueryOrder_Entry) GetProvider() string {
+	if x != nil {
+		return x.Provider
+	}
+	return ""
+}
+
+func (x *QueryOrder_Entry) GetIdentifier() string {
+	if x != nil {
+		return x.Identifier
+	}
+	return ""
+}
+
 var File_query_proto protoreflect.FileDescriptor
 
 const file_query_proto_rawDesc = "" +
 	"\n" +
-	"\vquery.proto\x12\x02pb\"\x84\x01\n" +
+	"\vquery.proto\x12\x02pb\"\xc6\x01\n" +
 	"\fQueryRequest\x12\x1c\n" +
 	"\tproviders\x18\x01 \x03(\tR\tproviders\x12\x14\n" +
 	"\x05query\x18\x02 \x01(\tR\x05query\x12\x1e\n" +
 	"\n" +
 	"maxresults\x18\x03 \x01(\x05R\n" +
 	"maxresults\x12 \n" +
-	"\vexactsearch\x18\x04 \x01(\bR\vexactsearch\"\xeb\x04\n" +
+	"\vexactsearch\x18\x04 \x01(\bR\vexactsearch\x12\x10\n" +
+	"\x03rid\x18\x05 \x01(\rR\x03rid\x12\x16\n" +
+	"\x06stream\x18\x06 \x01(\bR\x06stream\x12\x16\n" +
+	"\x06offset\x18\a \x01(\x05R\x06offset\"\x93\x05\n" +
 	"\rQueryResponse\x12\x14\n" +
 	"\x05query\x18\x01 \x01(\tR\x05query\x12*\n" +
 	"\x04item\x18\x02 \x01(\v2\x16.pb.QueryResponse.ItemR\x04item\x12\x10\n" +
-	"\x03qid\x18\x03 \x01(\x05R\x03qid\x1a\xe6\x03\n" +
+	"\x03qid\x18\x03 \x01(\x05R\x03qid\x12\x10\n" +
+	"\x03rid\x18\x04 \x01(\rR\x03rid\x12\x14\n" +
+	"\x05total\x18\x05 \x01(\x05R\x05total\x1a\xe6\x03\n" +
 	"\x04Item\x12\x1e\n" +
 	"\n" +
 	"identifier\x18\x01 \x01(\tR\n" +
@@ -434,7 +667,23 @@ const file_query_proto_rawDesc = "" +
 	"\tpositions\x18\x03 \x03(\x05R\tpositions\"\x1d\n" +
 	"\x04Type\x12\v\n" +
 	"\aREGULAR\x10\x00\x12\b\n" +
-	"\x04FILE\x10\x01B\x06Z\x04./pbb\x06proto3"
+	"\x04FILE\x10\x01\"\x89\x01\n" +
+	"\x11QueryProviderDone\x12\x1a\n" +
+	"\bprovider\x18\x01 \x01(\tR\bprovider\x12\x18\n" +
+	"\aresults\x18\x02 \x01(\x05R\aresults\x12\x10\n" +
+	"\x03qid\x18\x03 \x01(\x05R\x03qid\x12\x10\n" +
+	"\x03rid\x18\x04 \x01(\rR\x03rid\x12\x1a\n" +
+	"\btimedout\x18\x05 \x01(\bR\btimedout\"\xa5\x01\n" +
+	"\n" +
+	"QueryOrder\x12.\n" +
+	"\aentries\x18\x01 \x03(\v2\x14.pb.QueryOrder.EntryR\aentries\x12\x10\n" +
+	"\x03qid\x18\x02 \x01(\x05R\x03qid\x12\x10\n" +
+	"\x03rid\x18\x03 \x01(\rR\x03rid\x1aC\n" +
+	"\x05Entry\x12\x1a\n" +
+	"\bprovider\x18\x01 \x01(\tR\bprovider\x12\x1e\n" +
+	"\n" +
+	"identifier\x18\x02 \x01(\tR\n" +
+	"identifierB\x06Z\x04./pbb\x06proto3"
 
 var (
 	file_query_proto_rawDescOnce sync.Once
@@ -449,23 +698,27 @@ func file_query_proto_rawDescGZIP() []byte {
 }
 
 var file_query_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_query_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_query_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
 var file_query_proto_goTypes = []any{
 	(QueryResponse_Type)(0),              // 0: pb.QueryResponse.Type
 	(*QueryRequest)(nil),                 // 1: pb.QueryRequest
 	(*QueryResponse)(nil),                // 2: pb.QueryResponse
-	(*QueryResponse_Item)(nil),           // 3: pb.QueryResponse.Item
-	(*QueryResponse_Item_FuzzyInfo)(nil), // 4: pb.QueryResponse.Item.FuzzyInfo
+	(*QueryProviderDone)(nil),            // 3: pb.QueryProviderDone
+	(*QueryOrder)(nil),                   // 4: pb.QueryOrder
+	(*QueryResponse_Item)(nil),           // 5: pb.QueryResponse.Item
+	(*QueryResponse_Item_FuzzyInfo)(nil), // 6: pb.QueryResponse.Item.FuzzyInfo
+	(*QueryOrder_Entry)(nil),             // 7: pb.QueryOrder.Entry
 }
 var file_query_proto_depIdxs = []int32{
-	3, // 0: pb.QueryResponse.item:type_name -> pb.QueryResponse.Item
-	4, // 1: pb.QueryResponse.Item.fuzzyinfo:type_name -> pb.QueryResponse.Item.FuzzyInfo
-	0, // 2: pb.QueryResponse.Item.type:type_name -> pb.QueryResponse.Type
-	3, // [3:3] is the sub-list for method output_type
-	3, // [3:3] is the sub-list for method input_type
-	3, // [3:3] is the sub-list for extension type_name
-	3, // [3:3] is the sub-list for extension extendee
-	0, // [0:3] is the sub-list for field type_name
+	5, // 0: pb.QueryResponse.item:type_name -> pb.QueryResponse.Item
+	7, // 1: pb.QueryOrder.entries:type_name -> pb.QueryOrder.Entry
+	6, // 2: pb.QueryResponse.Item.fuzzyinfo:type_name -> pb.QueryResponse.Item.FuzzyInfo
+	0, // 3: pb.QueryResponse.Item.type:type_name -> pb.QueryResponse.Type
+	4, // [4:4] is the sub-list for method output_type
+	4, // [4:4] is the sub-list for method input_type
+	4, // [4:4] is the sub-list for extension type_name
+	4, // [4:4] is the sub-list for extension extendee
+	0, // [0:4] is the sub-list for field type_name
 }
 
 func init() { file_query_proto_init() }
@@ -479,7 +732,7 @@ func file_query_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_query_proto_rawDesc), len(file_query_proto_rawDesc)),
 			NumEnums:      1,
-			NumMessages:   4,
+			NumMessages:   7,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
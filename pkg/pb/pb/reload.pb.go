@@ -0,0 +1,122 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.7
+// 	protoc        v6.32.1
+// source: reload.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ReloadRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Rid           uint32                 `protobuf:"varint,1,opt,name=rid,proto3" json:"rid,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReloadRequest) Reset() {
+	*x = ReloadRequest{}
+	mi := &file_reload_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReloadRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReloadRequest) ProtoMessage() {}
+
+func (x *ReloadRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_reload_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReloadRequest.ProtoReflect.Descriptor instead.
+func (*ReloadRequest) Descriptor() ([]byte, []int) {
+	return file_reload_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ReloadRequest) GetRid() uint32 {
+	if x != nil {
+		return x.Rid
+	}
+	return 0
+}
+
+var File_reload_proto protoreflect.FileDescriptor
+
+const file_reload_proto_rawDesc = "" +
+	"\n" +
+	"\freload.proto\x12\x02pb\"!\n" +
+	"\rReloadRequest\x12\x10\n" +
+	"\x03rid\x18\x01 \x01(\rR\x03ridB\x06Z\x04./pbb\x06proto3"
+
+var (
+	file_reload_proto_rawDescOnce sync.Once
+	file_reload_proto_rawDescData []byte
+)
+
+func file_reload_proto_rawDescGZIP() []byte {
+	file_reload_proto_rawDescOnce.Do(func() {
+		file_reload_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_reload_proto_rawDesc), len(file_reload_proto_rawDesc)))
+	})
+	return file_reload_proto_rawDescData
+}
+
+var file_reload_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_reload_proto_goTypes = []any{
+	(*ReloadRequest)(nil), // 0: pb.ReloadRequest
+}
+var file_reload_proto_depIdxs = []int32{
+	0, // [0:0] is the sub-list for method output_type
+	0, // [0:0] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_reload_proto_init() }
+func file_reload_proto_init() {
+	if File_reload_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_reload_proto_rawDesc), len(file_reload_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   1,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_reload_proto_goTypes,
+		DependencyIndexes: file_reload_proto_depIdxs,
+		MessageInfos:      file_reload_proto_msgTypes,
+	}.Build()
+	File_reload_proto = out.File
+	file_reload_proto_goTypes = nil
+	file_reload_proto_depIdxs = nil
+}
@@ -0,0 +1,432 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.7
+// 	protoc        v6.32.1
+// source: stats.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type StatsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Rid           uint32                 `protobuf:"varint,1,opt,name=rid,proto3" json:"rid,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StatsRequest) Reset() {
+	*x = StatsRequest{}
+	mi := &file_stats_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatsRequest) ProtoMessage() {}
+
+func (x *StatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_stats_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatsRequest.ProtoReflect.Descriptor instead.
+func (*StatsRequest) Descriptor() ([]byte, []int) {
+	return file_stats_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *StatsRequest) GetRid() uint32 {
+	if x != nil {
+		return x.Rid
+	}
+	return 0
+}
+
+type StatsResponse struct {
+	state         protoimpl.MessageState    `protogen:"open.v1"`
+	Providers     []*StatsResponse_Provider `protobuf:"bytes,1,rep,name=providers,proto3" json:"providers,omitempty"`
+	Connections   uint32                    `protobuf:"varint,2,opt,name=connections,proto3" json:"connections,omitempty"`
+	Subscriptions uint32                    `protobuf:"varint,3,opt,name=subscriptions,proto3" json:"subscriptions,omitempty"`
+	HeapAlloc     uint64                    `protobuf:"varint,4,opt,name=heap_alloc,json=heapAlloc,proto3" json:"heap_alloc,omitempty"`
+	HeapSys       uint64                    `protobuf:"varint,5,opt,name=heap_sys,json=heapSys,proto3" json:"heap_sys,omitempty"`
+	Sys           uint64                    `protobuf:"varint,6,opt,name=sys,proto3" json:"sys,omitempty"`
+	Goroutines    uint32                    `protobuf:"varint,7,opt,name=goroutines,proto3" json:"goroutines,omitempty"`
+	Uptime        int64                     `protobuf:"varint,8,opt,name=uptime,proto3" json:"uptime,omitempty"`
+	Rid           uint32                    `protobuf:"varint,9,opt,name=rid,proto3" json:"rid,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StatsResponse) Reset() {
+	*x = StatsResponse{}
+	mi := &file_stats_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatsResponse) ProtoMessage() {}
+
+func (x *StatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_stats_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatsResponse.ProtoReflect.Descriptor instead.
+func (*StatsResponse) Descriptor() ([]byte, []int) {
+	return file_stats_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *StatsResponse) GetProviders() []*StatsResponse_Provider {
+	if x != nil {
+		return x.Providers
+	}
+	return nil
+}
+
+func (x *StatsResponse) GetConnections() uint32 {
+	if x != nil {
+		return x.Connections
+	}
+	return 0
+}
+
+func (x *StatsResponse) GetSubscriptions() uint32 {
+	if x != nil {
+		return x.Subscriptions
+	}
+	return 0
+}
+
+func (x *StatsResponse) GetHeapAlloc() uint64 {
+	if x != nil {
+		return x.HeapAlloc
+	}
+	return 0
+}
+
+func (x *StatsResponse) GetHeapSys() uint64 {
+	if x != nil {
+		return x.HeapSys
+	}
+	return 0
+}
+
+func (x *StatsResponse) GetSys() uint64 {
+	if x != nil {
+		return x.Sys
+	}
+	return 0
+}
+
+func (x *StatsResponse) GetGoroutines() uint32 {
+	if x != nil {
+		return x.Goroutines
+	}
+	return 0
+}
+
+func (x *StatsResponse) GetUptime() int64 {
+	if x != nil {
+		return x.Uptime
+	}
+	return 0
+}
+
+func (x *StatsResponse) GetRid() uint32 {
+	if x != nil {
+		return x.Rid
+	}
+	return 0
+}
+
+type StatsResponse_Bucket struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Le            float64                `protobuf:"fixed64,1,opt,name=le,proto3" json:"le,omitempty"`
+	Count         uint64                 `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StatsResponse_Bucket) Reset() {
+	*x = StatsResponse_Bucket{}
+	mi := &file_stats_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatsResponse_Bucket) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatsResponse_Bucket) ProtoMessage() {}
+
+func (x *StatsResponse_Bucket) ProtoReflect() protoreflect.Message {
+	mi := &file_stats_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatsResponse_Bucket.ProtoReflect.Descriptor instead.
+func (*StatsResponse_Bucket) Descriptor() ([]byte, []int) {
+	return file_stats_proto_rawDescGZIP(), []int{1, 0}
+}
+
+func (x *StatsResponse_Bucket) GetLe() float64 {
+	if x != nil {
+		return x.Le
+	}
+	return 0
+}
+
+func (x *StatsResponse_Bucket) GetCount() uint64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+type StatsResponse_Provider struct {
+	state         protoimpl.MessageState  `protogen:"open.v1"`
+	Name          string                  `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Queries       uint64                  `protobuf:"varint,2,opt,name=queries,proto3" json:"queries,omitempty"`
+	Results       uint64                  `protobuf:"varint,3,opt,name=results,proto3" json:"results,omitempty"`
+	Activations   uint64                  `protobuf:"varint,4,opt,name=activations,proto3" json:"activations,omitempty"`
+	Errors        uint64                  `protobuf:"varint,5,opt,name=errors,proto3" json:"errors,omitempty"`
+	Timeouts      uint64                  `protobuf:"varint,6,opt,name=timeouts,proto3" json:"timeouts,omitempty"`
+	LatencySum    float64                 `protobuf:"fixed64,7,opt,name=latency_sum,json=latencySum,proto3" json:"latency_sum,omitempty"`
+	Latency       []*StatsResponse_Bucket `protobuf:"bytes,8,rep,name=latency,proto3" json:"latency,omitempty"`
+	Crashes       uint64                  `protobuf:"varint,9,opt,name=crashes,proto3" json:"crashes,omitempty"`
+	DisabledUntil int64                   `protobuf:"varint,10,opt,name=disabled_until,json=disabledUntil,proto3" json:"disabled_until,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StatsResponse_Provider) Reset() {
+	*x = StatsResponse_Provider{}
+	mi := &file_stats_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatsResponse_Provider) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatsResponse_Provider) ProtoMessage() {}
+
+func (x *StatsResponse_Provider) ProtoReflect() protoreflect.Message {
+	mi := &file_stats_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatsResponse_Provider.ProtoReflect.Descriptor instead.
+func (*StatsResponse_Provider) Descriptor() ([]byte, []int) {
+	return file_stats_proto_rawDescGZIP(), []int{1, 1}
+}
+
+func (x *StatsResponse_Provider) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *StatsResponse_Provider) GetQueries() uint64 {
+	if x != nil {
+		return x.Queries
+	}
+	return 0
+}
+
+func (x *StatsResponse_Provider) GetResults() uint64 {
+	if x != nil {
+		return x.Results
+	}
+	return 0
+}
+
+func (x *StatsResponse_Provider) GetActivations() uint64 {
+	if x != nil {
+		return x.Activations
+	}
+	return 0
+}
+
+func (x *StatsResponse_Provider) GetErrors() uint64 {
+	if x != nil {
+		return x.Errors
+	}
+	return 0
+}
+
+func (x *StatsResponse_Provider) GetTimeouts() uint64 {
+	if x != nil {
+		return x.Timeouts
+	}
+	return 0
+}
+
+func (x *StatsResponse_Provider) GetLatencySum() float64 {
+	if x != nil {
+		return x.LatencySum
+	}
+	return 0
+}
+
+func (x *StatsResponse_Provider) GetLatency() []*StatsResponse_Bucket {
+	if x != nil {
+		return x.Latency
+	}
+	return nil
+}
+
+func (x *StatsResponse_Provider) GetCrashes() uint64 {
+	if x != nil {
+		return x.Crashes
+	}
+	return 0
+}
+
+func (x *StatsResponse_Provider) GetDisabledUntil() int64 {
+	if x != nil {
+		return x.DisabledUntil
+	}
+	return 0
+}
+
+var File_stats_proto protoreflect.FileDescriptor
+
+const file_stats_proto_rawDesc = "" +
+	"\n" +
+	"\vstats.proto\x12\x02pb\" \n" +
+	"\fStatsRequest\x12\x10\n" +
+	"\x03rid\x18\x01 \x01(\rR\x03rid\"\x98\x05\n" +
+	"\rStatsResponse\x128\n" +
+	"\tproviders\x18\x01 \x03(\v2\x1a.pb.StatsResponse.ProviderR\tproviders\x12 \n" +
+	"\vconnections\x18\x02 \x01(\rR\vconnections\x12$\n" +
+	"\rsubscriptions\x18\x03 \x01(\rR\rsubscriptions\x12\x1d\n" +
+	"\n" +
+	"heap_alloc\x18\x04 \x01(\x04R\theapAlloc\x12\x19\n" +
+	"\bheap_sys\x18\x05 \x01(\x04R\aheapSys\x12\x10\n" +
+	"\x03sys\x18\x06 \x01(\x04R\x03sys\x12\x1e\n" +
+	"\n" +
+	"goroutines\x18\a \x01(\rR\n" +
+	"goroutines\x12\x16\n" +
+	"\x06uptime\x18\b \x01(\x03R\x06uptime\x12\x10\n" +
+	"\x03rid\x18\t \x01(\rR\x03rid\x1a.\n" +
+	"\x06Bucket\x12\x0e\n" +
+	"\x02le\x18\x01 \x01(\x01R\x02le\x12\x14\n" +
+	"\x05count\x18\x02 \x01(\x04R\x05count\x1a\xbe\x02\n" +
+	"\bProvider\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x18\n" +
+	"\aqueries\x18\x02 \x01(\x04R\aqueries\x12\x18\n" +
+	"\aresults\x18\x03 \x01(\x04R\aresults\x12 \n" +
+	"\vactivations\x18\x04 \x01(\x04R\vactivations\x12\x16\n" +
+	"\x06errors\x18\x05 \x01(\x04R\x06errors\x12\x1a\n" +
+	"\btimeouts\x18\x06 \x01(\x04R\btimeouts\x12\x1f\n" +
+	"\vlatency_sum\x18\a \x01(\x01R\n" +
+	"latencySum\x122\n" +
+	"\alatency\x18\b \x03(\v2\x18.pb.StatsResponse.BucketR\alatency\x12\x18\n" +
+	"\acrashes\x18\t \x01(\x04R\acrashes\x12%\n" +
+	"\x0edisabled_until\x18\n" +
+	" \x01(\x03R\rdisabledUntilB\x06Z\x04./pbb\x06proto3"
+
+var (
+	file_stats_proto_rawDescOnce sync.Once
+	file_stats_proto_rawDescData []byte
+)
+
+func file_stats_proto_rawDescGZIP() []byte {
+	file_stats_proto_rawDescOnce.Do(func() {
+		file_stats_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_stats_proto_rawDesc), len(file_stats_proto_rawDesc)))
+	})
+	return file_stats_proto_rawDescData
+}
+
+var file_stats_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_stats_proto_goTypes = []any{
+	(*StatsRequest)(nil),           // 0: pb.StatsRequest
+	(*StatsResponse)(nil),          // 1: pb.StatsResponse
+	(*StatsResponse_Bucket)(nil),   // 2: pb.StatsResponse.Bucket
+	(*StatsResponse_Provider)(nil), // 3: pb.StatsResponse.Provider
+}
+var file_stats_proto_depIdxs = []int32{
+	3, // 0: pb.StatsResponse.providers:type_name -> pb.StatsResponse.Provider
+	2, // 1: pb.StatsResponse.Provider.latency:type_name -> pb.StatsResponse.Bucket
+	2, // [2:2] is the sub-list for method output_type
+	2, // [2:2] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_stats_proto_init() }
+func file_stats_proto_init() {
+	if File_stats_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_stats_proto_rawDesc), len(file_stats_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   4,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_stats_proto_goTypes,
+		DependencyIndexes: file_stats_proto_depIdxs,
+		MessageInfos:      file_stats_proto_msgTypes,
+	}.Build()
+	File_stats_proto = out.File
+	file_stats_proto_goTypes = nil
+	file_stats_proto_depIdxs = nil
+}
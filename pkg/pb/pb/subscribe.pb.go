@@ -26,6 +26,7 @@ type SubscribeRequest struct {
 	Interval      int32                  `protobuf:"varint,1,opt,name=interval,proto3" json:"interval,omitempty"`
 	Provider      string                 `protobuf:"bytes,2,opt,name=provider,proto3" json:"provider,omitempty"`
 	Query         string                 `protobuf:"bytes,3,opt,name=query,proto3" json:"query,omitempty"`
+	Rid           uint32                 `protobuf:"varint,4,opt,name=rid,proto3" json:"rid,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -81,9 +82,17 @@ func (x *SubscribeRequest) GetQuery() string {
 	return ""
 }
 
+func (x *SubscribeRequest) GetRid() uint32 {
+	if x != nil {
+		return x.Rid
+	}
+	return 0
+}
+
 type SubscribeResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Value         string                 `protobuf:"bytes,2,opt,name=value,proto3" json:"value,omitempty"`
+	Rid           uint32                 `protobuf:"varint,3,opt,name=rid,proto3" json:"rid,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -125,17 +134,26 @@ func (x *SubscribeResponse) GetValue() string {
 	return ""
 }
 
+func (x *SubscribeResponse) GetRid() uint32 {
+	if x != nil {
+		return x.Rid
+	}
+	return 0
+}
+
 var File_subscribe_proto protoreflect.FileDescriptor
 
 const file_subscribe_proto_rawDesc = "" +
 	"\n" +
-	"\x0fsubscribe.proto\x12\x02pb\"`\n" +
+	"\x0fsubscribe.proto\x12\x02pb\"r\n" +
 	"\x10SubscribeRequest\x12\x1a\n" +
 	"\binterval\x18\x01 \x01(\x05R\binterval\x12\x1a\n" +
 	"\bprovider\x18\x02 \x01(\tR\bprovider\x12\x14\n" +
-	"\x05query\x18\x03 \x01(\tR\x05query\")\n" +
+	"\x05query\x18\x03 \x01(\tR\x05query\x12\x10\n" +
+	"\x03rid\x18\x04 \x01(\rR\x03rid\";\n" +
 	"\x11SubscribeResponse\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05valueB\x06Z\x04./pbb\x06proto3"
+	"\x05value\x18\x02 \x01(\tR\x05value\x12\x10\n" +
+	"\x03rid\x18\x03 \x01(\rR\x03ridB\x06Z\x04./pbb\x06proto3"
 
 var (
 	file_subscribe_proto_rawDescOnce sync.Once